@@ -0,0 +1,5 @@
+package v1
+
+// Hub marks this type as a conversion hub.
+// LogicalVolume of the other versions are converted from and to this version.
+func (*LogicalVolume) Hub() {}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"google.golang.org/grpc/codes"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -18,21 +19,167 @@ type LogicalVolumeSpec struct {
 	NodeName    string            `json:"nodeName"`
 	Size        resource.Quantity `json:"size"`
 	DeviceClass string            `json:"deviceClass,omitempty"`
+
+	// IOLimits is the IO throttling applied to the pods using this volume.
+	// +optional
+	IOLimits *IOLimits `json:"ioLimits,omitempty"`
+
+	// ExistingLVName is the name of an existing LVM logical volume to adopt.
+	// If this is set, topolvm-node adopts the logical volume instead of creating a new one.
+	// +optional
+	ExistingLVName string `json:"existingLVName,omitempty"`
+}
+
+// IOLimits defines the limits of IO to a logical volume.  Zero means unlimited.
+type IOLimits struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReadBPS int64 `json:"readBPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WriteBPS int64 `json:"writeBPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReadIOPS int64 `json:"readIOPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WriteIOPS int64 `json:"writeIOPS,omitempty"`
+}
+
+// IsZero returns true if no limit is set.
+func (l *IOLimits) IsZero() bool {
+	return l == nil || *l == IOLimits{}
+}
+
+// PersistentVolumePhase is the phase of LogicalVolume derived from its PersistentVolume.
+type PersistentVolumePhase string
+
+const (
+	// PersistentVolumeBound means the PersistentVolume of the LogicalVolume is bound to a claim.
+	PersistentVolumeBound PersistentVolumePhase = "Bound"
+	// PersistentVolumeReleased means the claim of the PersistentVolume has been deleted
+	// and the logical volume is retained.  The PersistentVolume can be bound to a new claim.
+	PersistentVolumeReleased PersistentVolumePhase = "Released"
+	// PersistentVolumeOrphaned means the PersistentVolume has been deleted while the logical volume is retained.
+	PersistentVolumeOrphaned PersistentVolumePhase = "Orphaned"
+)
+
+// Condition types of LogicalVolume.
+const (
+	// LogicalVolumeCreated indicates whether the logical volume has been created or adopted.
+	// It is False if the creation has failed.
+	LogicalVolumeCreated = "Created"
+	// LogicalVolumeResizing indicates whether the logical volume is being resized.
+	// It stays True with the reason of the failure while resizing is retried.
+	LogicalVolumeResizing = "Resizing"
+	// LogicalVolumeReady indicates whether the logical volume is created and has the requested size.
+	LogicalVolumeReady = "Ready"
+	// LogicalVolumeDegraded indicates whether the logical volume is unhealthy on the node.
+	LogicalVolumeDegraded = "Degraded"
+	// LogicalVolumeDeleting indicates whether the logical volume is being removed.
+	LogicalVolumeDeleting = "Deleting"
+)
+
+// Condition reasons of LogicalVolume.
+// The reasons of failures are the names of gRPC codes returned by lvmd, e.g. "ResourceExhausted".
+const (
+	ReasonCreated  = "Created"
+	ReasonAdopted  = "Adopted"
+	ReasonPending  = "Pending"
+	ReasonResizing = "Resizing"
+	ReasonResized  = "Resized"
+	ReasonReady    = "Ready"
+	ReasonHealthy  = "Healthy"
+	ReasonNotFound = "NotFound"
+	ReasonInactive = "Inactive"
+	ReasonPartial  = "Partial"
+	ReasonPoolFull = "PoolFull"
+	ReasonDeleting = "Deleting"
+)
+
+// ReasonCode returns the gRPC code of the failure reason.
+// codes.Internal is returned if the reason is not the name of a gRPC code.
+func ReasonCode(reason string) codes.Code {
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == reason {
+			return c
+		}
+	}
+	return codes.Internal
 }
 
+// VolumePhase is the phase of the logical volume of LogicalVolume on the node.
+type VolumePhase string
+
+const (
+	// VolumePending means the logical volume has not been created.
+	VolumePending VolumePhase = "Pending"
+	// VolumeReady means the logical volume has been created and has the requested size.
+	VolumeReady VolumePhase = "Ready"
+	// VolumeResizing means the logical volume is being resized.
+	VolumeResizing VolumePhase = "Resizing"
+	// VolumeFailed means the creation of the logical volume has failed.
+	VolumeFailed VolumePhase = "Failed"
+	// VolumeDeleting means the logical volume is being removed.
+	VolumeDeleting VolumePhase = "Deleting"
+)
+
 // LogicalVolumeStatus defines the observed state of LogicalVolume
 type LogicalVolumeStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	VolumeID    string             `json:"volumeID,omitempty"`
-	Code        codes.Code         `json:"code,omitempty"`
+	VolumeID string `json:"volumeID,omitempty"`
+	// LVName is the name of the LVM logical volume if it differs from VolumeID.
+	// It is set when an existing logical volume is adopted.
+	// +optional
+	LVName string `json:"lvName,omitempty"`
+	// Code is the gRPC code of the last failure.
+	// Deprecated: use Conditions instead.  This is kept for compatibility.
+	Code codes.Code `json:"code,omitempty"`
+	// Message is the message of the last failure.
+	// Deprecated: use Conditions instead.  This is kept for compatibility.
 	Message     string             `json:"message,omitempty"`
 	CurrentSize *resource.Quantity `json:"currentSize,omitempty"`
+
+	// Phase is the phase of the logical volume on the node.
+	// It is derived from Conditions.
+	// +optional
+	Phase VolumePhase `json:"phase,omitempty"`
+	// PersistentVolumePhase is the phase of the PersistentVolume of this LogicalVolume.
+	// +kubebuilder:validation:Enum=Bound;Released;Orphaned
+	// +optional
+	PersistentVolumePhase PersistentVolumePhase `json:"persistentVolumePhase,omitempty"`
+	// ObservedGeneration is the generation of the spec observed by topolvm-node.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the latest observations of the logical volume.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// DevicePath is the path of the device of the logical volume on the node.
+	// +optional
+	DevicePath string `json:"devicePath,omitempty"`
+	// DevMajor is the major number of the device of the logical volume.
+	// +optional
+	DevMajor uint32 `json:"devMajor,omitempty"`
+	// DevMinor is the minor number of the device of the logical volume.
+	// +optional
+	DevMinor uint32 `json:"devMinor,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="NODE",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="DEVICECLASS",type=string,JSONPath=`.spec.deviceClass`
+//+kubebuilder:printcolumn:name="SIZE",type=string,JSONPath=`.status.currentSize`
+//+kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="PV",type=string,JSONPath=`.status.persistentVolumePhase`,priority=1
+//+kubebuilder:printcolumn:name="VOLUMEID",type=string,JSONPath=`.status.volumeID`,priority=1
+//+kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // LogicalVolume is the Schema for the logicalvolumes API
 type LogicalVolume struct {
@@ -54,6 +201,57 @@ func (lv *LogicalVolume) IsCompatibleWith(lv2 *LogicalVolume) bool {
 	return true
 }
 
+// SetCondition sets the condition of the logical volume observed for the current generation.
+func (lv *LogicalVolume) SetCondition(condType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&lv.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		ObservedGeneration: lv.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// LVName returns the name of the LVM logical volume of lv.
+// The name is the UID of lv unless lv adopts an existing logical volume.
+func (lv *LogicalVolume) LVName() string {
+	if lv.Status.LVName != "" {
+		return lv.Status.LVName
+	}
+	if lv.Status.VolumeID != "" {
+		return lv.Status.VolumeID
+	}
+	return string(lv.UID)
+}
+
+// UpdatePhase sets Ready condition, Phase and ObservedGeneration from the other conditions.
+func (lv *LogicalVolume) UpdatePhase() {
+	st := &lv.Status
+	st.ObservedGeneration = lv.Generation
+	created := meta.FindStatusCondition(st.Conditions, LogicalVolumeCreated)
+	resizing := meta.FindStatusCondition(st.Conditions, LogicalVolumeResizing)
+
+	status, reason, message := metav1.ConditionFalse, "", ""
+	switch {
+	case lv.DeletionTimestamp != nil:
+		st.Phase = VolumeDeleting
+		reason = ReasonDeleting
+	case created == nil:
+		st.Phase = VolumePending
+		reason = ReasonPending
+	case created.Status != metav1.ConditionTrue:
+		st.Phase = VolumeFailed
+		reason, message = created.Reason, created.Message
+	case resizing != nil && resizing.Status == metav1.ConditionTrue:
+		st.Phase = VolumeResizing
+		reason, message = ReasonResizing, resizing.Message
+	default:
+		st.Phase = VolumeReady
+		status, reason = metav1.ConditionTrue, ReasonReady
+	}
+	lv.SetCondition(LogicalVolumeReady, status, reason, message)
+}
+
 //+kubebuilder:object:root=true
 
 // LogicalVolumeList contains a list of LogicalVolume
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdatePhase(t *testing.T) {
+	now := metav1.Now()
+	cases := []struct {
+		name       string
+		conditions []metav1.Condition
+		deleting   bool
+		phase      VolumePhase
+		ready      metav1.ConditionStatus
+		reason     string
+	}{
+		{
+			name:   "pending",
+			phase:  VolumePending,
+			ready:  metav1.ConditionFalse,
+			reason: ReasonPending,
+		},
+		{
+			name: "failed",
+			conditions: []metav1.Condition{
+				{Type: LogicalVolumeCreated, Status: metav1.ConditionFalse, Reason: "ResourceExhausted", Message: "no enough space"},
+			},
+			phase:  VolumeFailed,
+			ready:  metav1.ConditionFalse,
+			reason: "ResourceExhausted",
+		},
+		{
+			name: "resizing",
+			conditions: []metav1.Condition{
+				{Type: LogicalVolumeCreated, Status: metav1.ConditionTrue, Reason: ReasonCreated},
+				{Type: LogicalVolumeResizing, Status: metav1.ConditionTrue, Reason: ReasonResizing},
+			},
+			phase:  VolumeResizing,
+			ready:  metav1.ConditionFalse,
+			reason: ReasonResizing,
+		},
+		{
+			name: "ready",
+			conditions: []metav1.Condition{
+				{Type: LogicalVolumeCreated, Status: metav1.ConditionTrue, Reason: ReasonCreated},
+				{Type: LogicalVolumeResizing, Status: metav1.ConditionFalse, Reason: ReasonResized},
+			},
+			phase:  VolumeReady,
+			ready:  metav1.ConditionTrue,
+			reason: ReasonReady,
+		},
+		{
+			name: "deleting",
+			conditions: []metav1.Condition{
+				{Type: LogicalVolumeCreated, Status: metav1.ConditionTrue, Reason: ReasonCreated},
+			},
+			deleting: true,
+			phase:    VolumeDeleting,
+			ready:    metav1.ConditionFalse,
+			reason:   ReasonDeleting,
+		},
+	}
+
+	for _, c := range cases {
+		lv := &LogicalVolume{}
+		lv.Generation = 2
+		lv.Status.Conditions = c.conditions
+		if c.deleting {
+			lv.DeletionTimestamp = &now
+		}
+		lv.UpdatePhase()
+
+		if lv.Status.Phase != c.phase {
+			t.Errorf("%s: unexpected phase: expected=%s, actual=%s", c.name, c.phase, lv.Status.Phase)
+		}
+		if lv.Status.ObservedGeneration != 2 {
+			t.Errorf("%s: unexpected observed generation: %d", c.name, lv.Status.ObservedGeneration)
+		}
+		ready := meta.FindStatusCondition(lv.Status.Conditions, LogicalVolumeReady)
+		if ready == nil {
+			t.Fatalf("%s: Ready condition is not set", c.name)
+		}
+		if ready.Status != c.ready || ready.Reason != c.reason || ready.ObservedGeneration != 2 {
+			t.Errorf("%s: unexpected Ready condition: %#v", c.name, ready)
+		}
+	}
+}
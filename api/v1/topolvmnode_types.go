@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeGroupHealth is the health of a volume group.
+type VolumeGroupHealth string
+
+const (
+	// VolumeGroupHealthy means all physical volumes of the volume group are available.
+	VolumeGroupHealthy VolumeGroupHealth = "Healthy"
+	// VolumeGroupPartial means one or more physical volumes of the volume group are missing.
+	VolumeGroupPartial VolumeGroupHealth = "Partial"
+)
+
+// HeartbeatTimeout is the duration after which TopolvmNode whose heartbeat has not been
+// updated is considered to have no capacity.  topolvm-node updates the heartbeat every minute.
+const HeartbeatTimeout = 3 * time.Minute
+
+// ThinPoolStatus is the usage of a thin pool.
+type ThinPoolStatus struct {
+	// Name is the name of the thin pool.
+	Name string `json:"name"`
+	// SizeBytes is the size of the thin pool in bytes.
+	SizeBytes int64 `json:"sizeBytes"`
+	// UsedBytes is the data usage of the thin pool in bytes.
+	UsedBytes int64 `json:"usedBytes"`
+}
+
+// DeviceClassStatus is the capacity of a device-class on the node.
+type DeviceClassStatus struct {
+	// Name is the name of the device-class.
+	Name string `json:"name"`
+	// Default is true if the device-class is the default one.
+	// +optional
+	Default bool `json:"default,omitempty"`
+	// SizeBytes is the size of the volume group in bytes.
+	SizeBytes int64 `json:"sizeBytes"`
+	// FreeBytes is the free space of the volume group in bytes.
+	// This is the same value as "capacity.topolvm.cybozu.com/<device-class>" annotation of Node.
+	FreeBytes int64 `json:"freeBytes"`
+	// SpareBytes is the space of the volume group that lvmd does not allocate in bytes.
+	// +optional
+	SpareBytes int64 `json:"spareBytes,omitempty"`
+	// WipingBytes is the size of logical volumes being wiped before removal in bytes.
+	// +optional
+	WipingBytes int64 `json:"wipingBytes,omitempty"`
+	// Health is the health of the volume group.
+	Health VolumeGroupHealth `json:"health"`
+	// ThinPools is the usage of thin pools in the volume group.
+	// +optional
+	ThinPools []ThinPoolStatus `json:"thinPools,omitempty"`
+}
+
+// TopolvmNodeStatus defines the observed state of TopolvmNode
+type TopolvmNodeStatus struct {
+	// DeviceClasses is the capacity of device-classes on the node.
+	// +optional
+	DeviceClasses []DeviceClassStatus `json:"deviceClasses,omitempty"`
+	// LVMdVersion is the version of lvmd running on the node.
+	// +optional
+	LVMdVersion string `json:"lvmdVersion,omitempty"`
+	// LastHeartbeatTime is the last time the status was reported by topolvm-node.
+	// +optional
+	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime,omitempty"`
+}
+
+// AvailableBytes returns the free space for new logical volumes in bytes.
+// It is zero unless the volume group is healthy.
+func (s *DeviceClassStatus) AvailableBytes() int64 {
+	if s.Health != VolumeGroupHealthy {
+		return 0
+	}
+	return s.FreeBytes
+}
+
+// Stale returns true if the status has not been reported by topolvm-node for HeartbeatTimeout.
+func (s *TopolvmNodeStatus) Stale(now time.Time) bool {
+	return now.Sub(s.LastHeartbeatTime.Time) > HeartbeatTimeout
+}
+
+// DeviceClass returns the status of the device-class.
+// The empty name means the default device-class.
+func (s *TopolvmNodeStatus) DeviceClass(name string) *DeviceClassStatus {
+	for i := range s.DeviceClasses {
+		dc := &s.DeviceClasses[i]
+		if (name == "" && dc.Default) || dc.Name == name {
+			return dc
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="LVMD",type=string,JSONPath=`.status.lvmdVersion`
+//+kubebuilder:printcolumn:name="HEARTBEAT",type=date,JSONPath=`.status.lastHeartbeatTime`
+//+kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TopolvmNode is the Schema for the topolvmnodes API.
+// It has the same name as the Node and is updated by topolvm-node on the Node.
+type TopolvmNode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status TopolvmNodeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TopolvmNodeList contains a list of TopolvmNode
+type TopolvmNodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TopolvmNode `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TopolvmNode{}, &TopolvmNodeList{})
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAvailableBytes(t *testing.T) {
+	cases := []struct {
+		health   VolumeGroupHealth
+		expected int64
+	}{
+		{VolumeGroupHealthy, 10},
+		{VolumeGroupPartial, 0},
+		{"", 0},
+	}
+	for _, c := range cases {
+		dc := &DeviceClassStatus{Name: "ssd", FreeBytes: 10, Health: c.health}
+		if actual := dc.AvailableBytes(); actual != c.expected {
+			t.Errorf("%q: expected %d, actual %d", c.health, c.expected, actual)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		heartbeat time.Time
+		expected  bool
+	}{
+		{now, false},
+		{now.Add(-HeartbeatTimeout), false},
+		{now.Add(-HeartbeatTimeout - time.Second), true},
+		{time.Time{}, true},
+	}
+	for _, c := range cases {
+		st := &TopolvmNodeStatus{LastHeartbeatTime: metav1.NewTime(c.heartbeat)}
+		if actual := st.Stale(now); actual != c.expected {
+			t.Errorf("%s: expected %v, actual %v", c.heartbeat, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeMigrationSpec defines the desired state of VolumeMigration
+type VolumeMigrationSpec struct {
+	// LogicalVolumeName is the name of the LogicalVolume to migrate.
+	// This is the same as the name of the PersistentVolume.
+	LogicalVolumeName string `json:"logicalVolumeName"`
+
+	// TargetNode is the name of the node where the volume is migrated to.
+	TargetNode string `json:"targetNode"`
+
+	// Cancel cancels the migration if it is true.
+	// The migration cannot be canceled once it reaches the Switching phase.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+// VolumeMigrationPhase is the phase of VolumeMigration.
+type VolumeMigrationPhase string
+
+const (
+	// VolumeMigrationPending means the migration has not been started.
+	VolumeMigrationPending VolumeMigrationPhase = ""
+	// VolumeMigrationCopying means the whole volume is being copied from a snapshot.
+	VolumeMigrationCopying VolumeMigrationPhase = "Copying"
+	// VolumeMigrationWaitingForDetach means the migration waits for the volume to be unstaged on the source node.
+	VolumeMigrationWaitingForDetach VolumeMigrationPhase = "WaitingForDetach"
+	// VolumeMigrationSyncing means the blocks changed during Copying are being copied.
+	VolumeMigrationSyncing VolumeMigrationPhase = "Syncing"
+	// VolumeMigrationVerifying means the checksums of the source and target volumes are being compared.
+	VolumeMigrationVerifying VolumeMigrationPhase = "Verifying"
+	// VolumeMigrationSwitching means the PersistentVolume and LogicalVolume are being switched to the target node.
+	VolumeMigrationSwitching VolumeMigrationPhase = "Switching"
+	// VolumeMigrationSucceeded means the volume has been migrated.
+	VolumeMigrationSucceeded VolumeMigrationPhase = "Succeeded"
+	// VolumeMigrationFailed means the migration has failed.
+	VolumeMigrationFailed VolumeMigrationPhase = "Failed"
+	// VolumeMigrationCancelled means the migration has been canceled.
+	VolumeMigrationCancelled VolumeMigrationPhase = "Cancelled"
+)
+
+// IsFinished returns true if the phase is terminal.
+func (p VolumeMigrationPhase) IsFinished() bool {
+	return p == VolumeMigrationSucceeded || p == VolumeMigrationFailed || p == VolumeMigrationCancelled
+}
+
+// VolumeMigrationStatus defines the observed state of VolumeMigration
+type VolumeMigrationStatus struct {
+	// +optional
+	Phase VolumeMigrationPhase `json:"phase,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// SourceNode is the name of the node where the volume is migrated from.
+	// +optional
+	SourceNode string `json:"sourceNode,omitempty"`
+	// SourceAddress is the address of LVStreamService of lvmd on the source node.
+	// +optional
+	SourceAddress string `json:"sourceAddress,omitempty"`
+	// VolumeID is the volume ID of the logical volume.  It is also the name of the logical volume on the target node.
+	// +optional
+	VolumeID string `json:"volumeID,omitempty"`
+	// SourceLVName is the name of the logical volume on the source node.
+	// It differs from VolumeID if the logical volume has been adopted.
+	// +optional
+	SourceLVName string `json:"sourceLVName,omitempty"`
+	// DeviceClass is the device-class of the logical volume on both nodes.
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+	// BaseSnapshot is the snapshot copied in Copying phase.
+	// +optional
+	BaseSnapshot string `json:"baseSnapshot,omitempty"`
+	// FinalSnapshot is the snapshot copied in Syncing phase.
+	// +optional
+	FinalSnapshot string `json:"finalSnapshot,omitempty"`
+
+	// TotalBytes is the size of the volume.
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+	// CopiedBytes is the progress of the current copy in bytes.
+	// +optional
+	CopiedBytes int64 `json:"copiedBytes,omitempty"`
+
+	// SourceChecksum is the SHA-256 checksum of the final snapshot.
+	// +optional
+	SourceChecksum string `json:"sourceChecksum,omitempty"`
+	// TargetChecksum is the SHA-256 checksum of the volume on the target node.
+	// +optional
+	TargetChecksum string `json:"targetChecksum,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="VOLUME",type=string,JSONPath=`.spec.logicalVolumeName`
+//+kubebuilder:printcolumn:name="SOURCE",type=string,JSONPath=`.status.sourceNode`
+//+kubebuilder:printcolumn:name="TARGET",type=string,JSONPath=`.spec.targetNode`
+//+kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VolumeMigration is the Schema for the volumemigrations API
+type VolumeMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeMigrationSpec   `json:"spec,omitempty"`
+	Status VolumeMigrationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VolumeMigrationList contains a list of VolumeMigration
+type VolumeMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VolumeMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VolumeMigration{}, &VolumeMigrationList{})
+}
@@ -0,0 +1,192 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// conversionData is the fields that cannot be represented in the other version.
+// It is kept in the annotation of the converted object to make the conversion lossless.
+type conversionData struct {
+	// Name is spec.name of v1 if it differs from metadata.name.
+	Name string `json:"name,omitempty"`
+	// AccessMode is spec.accessMode of v2.
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+}
+
+// ConvertTo converts this LogicalVolume to the hub version (v1).
+func (src *LogicalVolume) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*topolvmv1.LogicalVolume)
+	if src.Spec.Encryption != nil {
+		return errors.New("encryption of LogicalVolume is not supported yet")
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	var data conversionData
+	if err := popConversionData(&dst.ObjectMeta, &data); err != nil {
+		return err
+	}
+	if err := pushConversionData(&dst.ObjectMeta, conversionData{AccessMode: src.Spec.AccessMode}); err != nil {
+		return err
+	}
+
+	dst.Spec = topolvmv1.LogicalVolumeSpec{
+		Name:        src.Name,
+		NodeName:    src.Spec.NodeName,
+		Size:        *resource.NewQuantity(src.Spec.SizeBytes, resource.BinarySI),
+		DeviceClass: src.Spec.DeviceClass,
+	}
+	if data.Name != "" {
+		dst.Spec.Name = data.Name
+	}
+	if src.Spec.Source != nil {
+		dst.Spec.ExistingLVName = src.Spec.Source.ExistingLVName
+	}
+	if src.Spec.IO != nil && src.Spec.IO.Limits != nil {
+		l := src.Spec.IO.Limits
+		dst.Spec.IOLimits = &topolvmv1.IOLimits{
+			ReadBPS:   l.ReadBPS,
+			WriteBPS:  l.WriteBPS,
+			ReadIOPS:  l.ReadIOPS,
+			WriteIOPS: l.WriteIOPS,
+		}
+	}
+
+	st := &src.Status
+	dst.Status = topolvmv1.LogicalVolumeStatus{
+		VolumeID:              st.VolumeID,
+		LVName:                st.LVName,
+		Phase:                 topolvmv1.VolumePhase(st.Phase),
+		PersistentVolumePhase: topolvmv1.PersistentVolumePhase(st.PersistentVolumePhase),
+		ObservedGeneration:    st.ObservedGeneration,
+	}
+	if st.SizeBytes != 0 {
+		dst.Status.CurrentSize = resource.NewQuantity(st.SizeBytes, resource.BinarySI)
+	}
+	for _, c := range st.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *c.DeepCopy())
+	}
+	if st.Device != nil {
+		dst.Status.DevicePath = st.Device.Path
+		dst.Status.DevMajor = st.Device.Major
+		dst.Status.DevMinor = st.Device.Minor
+	}
+	dst.Status.Code, dst.Status.Message = failure(st.Conditions)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version.
+func (dst *LogicalVolume) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*topolvmv1.LogicalVolume)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	var data conversionData
+	if err := popConversionData(&dst.ObjectMeta, &data); err != nil {
+		return err
+	}
+	var name string
+	if src.Spec.Name != src.Name {
+		name = src.Spec.Name
+	}
+	if err := pushConversionData(&dst.ObjectMeta, conversionData{Name: name}); err != nil {
+		return err
+	}
+
+	dst.Spec = LogicalVolumeSpec{
+		NodeName:    src.Spec.NodeName,
+		DeviceClass: src.Spec.DeviceClass,
+		SizeBytes:   src.Spec.Size.Value(),
+		AccessMode:  data.AccessMode,
+	}
+	if src.Spec.ExistingLVName != "" {
+		dst.Spec.Source = &VolumeSource{ExistingLVName: src.Spec.ExistingLVName}
+	}
+	if l := src.Spec.IOLimits; l != nil {
+		dst.Spec.IO = &IOSettings{
+			Limits: &IOLimits{
+				ReadBPS:   l.ReadBPS,
+				WriteBPS:  l.WriteBPS,
+				ReadIOPS:  l.ReadIOPS,
+				WriteIOPS: l.WriteIOPS,
+			},
+		}
+	}
+
+	st := &src.Status
+	dst.Status = LogicalVolumeStatus{
+		VolumeID:              st.VolumeID,
+		LVName:                st.LVName,
+		Phase:                 VolumePhase(st.Phase),
+		PersistentVolumePhase: PersistentVolumePhase(st.PersistentVolumePhase),
+		ObservedGeneration:    st.ObservedGeneration,
+	}
+	if st.CurrentSize != nil {
+		dst.Status.SizeBytes = st.CurrentSize.Value()
+	}
+	for _, c := range st.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *c.DeepCopy())
+	}
+	if st.DevicePath != "" {
+		dst.Status.Device = &DeviceStatus{
+			Path:  st.DevicePath,
+			Major: st.DevMajor,
+			Minor: st.DevMinor,
+		}
+	}
+	return nil
+}
+
+// failure returns the deprecated code and message of v1 from the conditions.
+func failure(conditions []metav1.Condition) (codes.Code, string) {
+	created := meta.FindStatusCondition(conditions, topolvmv1.LogicalVolumeCreated)
+	if created != nil && created.Status == metav1.ConditionFalse {
+		return topolvmv1.ReasonCode(created.Reason), created.Message
+	}
+	resizing := meta.FindStatusCondition(conditions, topolvmv1.LogicalVolumeResizing)
+	if resizing != nil && resizing.Status == metav1.ConditionTrue && resizing.Reason != topolvmv1.ReasonResizing {
+		return topolvmv1.ReasonCode(resizing.Reason), resizing.Message
+	}
+	return codes.OK, ""
+}
+
+// popConversionData removes the conversion data from the annotations and decodes it into data.
+func popConversionData(obj *metav1.ObjectMeta, data *conversionData) error {
+	v, ok := obj.Annotations[topolvm.ConversionDataKey]
+	if !ok {
+		return nil
+	}
+	delete(obj.Annotations, topolvm.ConversionDataKey)
+	if len(obj.Annotations) == 0 {
+		obj.Annotations = nil
+	}
+	if err := json.Unmarshal([]byte(v), data); err != nil {
+		return fmt.Errorf("invalid annotation %s: %w", topolvm.ConversionDataKey, err)
+	}
+	return nil
+}
+
+// pushConversionData encodes data into the annotations unless data is empty.
+func pushConversionData(obj *metav1.ObjectMeta, data conversionData) error {
+	if data == (conversionData{}) {
+		return nil
+	}
+	v, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+	obj.Annotations[topolvm.ConversionDataKey] = string(v)
+	return nil
+}
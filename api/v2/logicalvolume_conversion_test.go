@@ -0,0 +1,146 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testV1LogicalVolume() *topolvmv1.LogicalVolume {
+	size := resource.MustParse("2Gi")
+	return &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pvc-1",
+			Generation:  3,
+			Annotations: map[string]string{topolvm.ResizeRequestedAtKey: "now"},
+		},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			Name:           "pvc-1",
+			NodeName:       "node1",
+			Size:           resource.MustParse("3Gi"),
+			DeviceClass:    "ssd",
+			IOLimits:       &topolvmv1.IOLimits{ReadBPS: 100, WriteIOPS: 10},
+			ExistingLVName: "data",
+		},
+		Status: topolvmv1.LogicalVolumeStatus{
+			VolumeID:              "data",
+			Code:                  codes.ResourceExhausted,
+			Message:               "no enough space",
+			CurrentSize:           &size,
+			Phase:                 topolvmv1.VolumeResizing,
+			PersistentVolumePhase: topolvmv1.PersistentVolumeBound,
+			ObservedGeneration:    3,
+			Conditions: []metav1.Condition{
+				{Type: topolvmv1.LogicalVolumeCreated, Status: metav1.ConditionTrue, Reason: topolvmv1.ReasonAdopted},
+				{Type: topolvmv1.LogicalVolumeResizing, Status: metav1.ConditionTrue, Reason: "ResourceExhausted", Message: "no enough space"},
+			},
+			DevicePath: "/dev/myvg/data",
+			DevMajor:   253,
+			DevMinor:   1,
+		},
+	}
+}
+
+func TestConvertFromV1(t *testing.T) {
+	src := testV1LogicalVolume()
+	lv := &LogicalVolume{}
+	if err := lv.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+
+	if lv.Spec.SizeBytes != 3<<30 || lv.Status.SizeBytes != 2<<30 {
+		t.Errorf("unexpected size: spec=%d, status=%d", lv.Spec.SizeBytes, lv.Status.SizeBytes)
+	}
+	if lv.Spec.Source == nil || lv.Spec.Source.ExistingLVName != "data" {
+		t.Errorf("unexpected source: %#v", lv.Spec.Source)
+	}
+	if lv.Spec.IO == nil || lv.Spec.IO.Limits == nil || lv.Spec.IO.Limits.ReadBPS != 100 || lv.Spec.IO.Limits.WriteIOPS != 10 {
+		t.Errorf("unexpected io: %#v", lv.Spec.IO)
+	}
+	if lv.Status.Phase != VolumeResizing || lv.Status.PersistentVolumePhase != PersistentVolumeBound {
+		t.Errorf("unexpected phase: %s, %s", lv.Status.Phase, lv.Status.PersistentVolumePhase)
+	}
+	if lv.Status.Device == nil || lv.Status.Device.Path != "/dev/myvg/data" || lv.Status.Device.Major != 253 || lv.Status.Device.Minor != 1 {
+		t.Errorf("unexpected device: %#v", lv.Status.Device)
+	}
+	if _, ok := lv.Annotations[topolvm.ConversionDataKey]; ok {
+		t.Errorf("conversion data should not be set: %v", lv.Annotations)
+	}
+
+	dst := &topolvmv1.LogicalVolume{}
+	if err := lv.ConvertTo(dst); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(src, dst) {
+		t.Errorf("round trip conversion changed LogicalVolume:\nexpected=%#v\nactual=%#v", src, dst)
+	}
+}
+
+func TestConvertFromV1WithDifferentName(t *testing.T) {
+	src := testV1LogicalVolume()
+	src.Spec.Name = "other"
+	lv := &LogicalVolume{}
+	if err := lv.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lv.Annotations[topolvm.ConversionDataKey]; !ok {
+		t.Errorf("conversion data should be set: %v", lv.Annotations)
+	}
+
+	dst := &topolvmv1.LogicalVolume{}
+	if err := lv.ConvertTo(dst); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(src, dst) {
+		t.Errorf("round trip conversion changed LogicalVolume:\nexpected=%#v\nactual=%#v", src, dst)
+	}
+}
+
+func TestConvertToV1(t *testing.T) {
+	src := &LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-2"},
+		Spec: LogicalVolumeSpec{
+			NodeName:   "node1",
+			SizeBytes:  1 << 30,
+			AccessMode: corev1.ReadWriteOncePod,
+		},
+		Status: LogicalVolumeStatus{
+			Phase: VolumeFailed,
+			Conditions: []metav1.Condition{
+				{Type: topolvmv1.LogicalVolumeCreated, Status: metav1.ConditionFalse, Reason: "ResourceExhausted", Message: "no enough space"},
+			},
+		},
+	}
+	v1lv := &topolvmv1.LogicalVolume{}
+	if err := src.ConvertTo(v1lv); err != nil {
+		t.Fatal(err)
+	}
+	if v1lv.Spec.Name != "pvc-2" || v1lv.Spec.Size.Value() != 1<<30 {
+		t.Errorf("unexpected spec: %#v", v1lv.Spec)
+	}
+	if v1lv.Status.Code != codes.ResourceExhausted || v1lv.Status.Message != "no enough space" {
+		t.Errorf("unexpected code and message: %s, %s", v1lv.Status.Code, v1lv.Status.Message)
+	}
+	if v1lv.Status.CurrentSize != nil {
+		t.Errorf("current size should be nil: %v", v1lv.Status.CurrentSize)
+	}
+
+	dst := &LogicalVolume{}
+	if err := dst.ConvertFrom(v1lv); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(src, dst) {
+		t.Errorf("round trip conversion changed LogicalVolume:\nexpected=%#v\nactual=%#v", src, dst)
+	}
+
+	src.Spec.Encryption = &Encryption{SecretRef: corev1.SecretReference{Name: "key"}}
+	if err := src.ConvertTo(&topolvmv1.LogicalVolume{}); err == nil {
+		t.Error("encryption should be rejected")
+	}
+}
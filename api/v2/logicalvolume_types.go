@@ -0,0 +1,179 @@
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogicalVolumeSpec defines the desired state of LogicalVolume
+type LogicalVolumeSpec struct {
+	// NodeName is the name of the node where the logical volume is created.
+	NodeName string `json:"nodeName"`
+	// DeviceClass is the name of the device-class of the logical volume.
+	// Empty means the default device-class.
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+	// SizeBytes is the requested size of the logical volume in bytes.
+	// +kubebuilder:validation:Minimum=1
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// Source is the source of the logical volume.
+	// If this is not set, a new empty logical volume is created.
+	// +optional
+	Source *VolumeSource `json:"source,omitempty"`
+	// AccessMode is the access mode of the volume requested by the PersistentVolumeClaim.
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadWriteOncePod
+	// +optional
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	// Encryption is the encryption of the logical volume.
+	// This is not supported yet and LogicalVolume with this field is rejected.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// IO is the IO settings applied to the pods using the volume.
+	// +optional
+	IO *IOSettings `json:"io,omitempty"`
+}
+
+// VolumeSource is the source of a logical volume.
+type VolumeSource struct {
+	// ExistingLVName is the name of an existing LVM logical volume to adopt.
+	// +optional
+	ExistingLVName string `json:"existingLVName,omitempty"`
+}
+
+// Encryption defines the encryption of a logical volume.
+type Encryption struct {
+	// SecretRef is the reference to the Secret that has the passphrase.
+	SecretRef corev1.SecretReference `json:"secretRef"`
+}
+
+// IOSettings defines the IO settings of a logical volume.
+type IOSettings struct {
+	// Limits is the IO throttling applied to the pods using the volume.
+	// +optional
+	Limits *IOLimits `json:"limits,omitempty"`
+}
+
+// IOLimits defines the limits of IO to a logical volume.  Zero means unlimited.
+type IOLimits struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReadBPS int64 `json:"readBPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WriteBPS int64 `json:"writeBPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReadIOPS int64 `json:"readIOPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WriteIOPS int64 `json:"writeIOPS,omitempty"`
+}
+
+// VolumePhase is the phase of the logical volume on the node.
+type VolumePhase string
+
+const (
+	// VolumePending means the logical volume has not been created.
+	VolumePending VolumePhase = "Pending"
+	// VolumeReady means the logical volume has been created and has the requested size.
+	VolumeReady VolumePhase = "Ready"
+	// VolumeResizing means the logical volume is being resized.
+	VolumeResizing VolumePhase = "Resizing"
+	// VolumeFailed means the creation of the logical volume has failed.
+	VolumeFailed VolumePhase = "Failed"
+	// VolumeDeleting means the logical volume is being removed.
+	VolumeDeleting VolumePhase = "Deleting"
+)
+
+// PersistentVolumePhase is the phase of LogicalVolume derived from its PersistentVolume.
+type PersistentVolumePhase string
+
+const (
+	// PersistentVolumeBound means the PersistentVolume is bound to a claim.
+	PersistentVolumeBound PersistentVolumePhase = "Bound"
+	// PersistentVolumeReleased means the claim of the PersistentVolume has been deleted
+	// and the PersistentVolume is retained.
+	PersistentVolumeReleased PersistentVolumePhase = "Released"
+	// PersistentVolumeOrphaned means the PersistentVolume has been deleted while the logical volume is retained.
+	PersistentVolumeOrphaned PersistentVolumePhase = "Orphaned"
+)
+
+// DeviceStatus is the device of a logical volume on the node.
+type DeviceStatus struct {
+	// Path is the path of the device.
+	Path string `json:"path"`
+	// Major is the major number of the device.
+	Major uint32 `json:"major"`
+	// Minor is the minor number of the device.
+	Minor uint32 `json:"minor"`
+}
+
+// LogicalVolumeStatus defines the observed state of LogicalVolume
+type LogicalVolumeStatus struct {
+	// VolumeID is the volume ID of CSI.  It is also the name of the logical volume unless LVName is set.
+	// +optional
+	VolumeID string `json:"volumeID,omitempty"`
+	// LVName is the name of the LVM logical volume if it differs from VolumeID.
+	// It is set when an existing logical volume is adopted.
+	// +optional
+	LVName string `json:"lvName,omitempty"`
+	// SizeBytes is the current size of the logical volume in bytes.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Phase is the phase of the logical volume on the node.
+	// +optional
+	Phase VolumePhase `json:"phase,omitempty"`
+	// PersistentVolumePhase is the phase of the PersistentVolume of this LogicalVolume.
+	// +kubebuilder:validation:Enum=Bound;Released;Orphaned
+	// +optional
+	PersistentVolumePhase PersistentVolumePhase `json:"persistentVolumePhase,omitempty"`
+	// ObservedGeneration is the generation of the spec observed by topolvm-node.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the latest observations of the logical volume.
+	// The reason of the conditions set on failure is the name of the gRPC code.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Device is the device of the logical volume on the node.
+	// +optional
+	Device *DeviceStatus `json:"device,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:unservedversion
+//+kubebuilder:printcolumn:name="NODE",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="DEVICECLASS",type=string,JSONPath=`.spec.deviceClass`
+//+kubebuilder:printcolumn:name="SIZE",type=integer,JSONPath=`.status.sizeBytes`
+//+kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="PV",type=string,JSONPath=`.status.persistentVolumePhase`,priority=1
+//+kubebuilder:printcolumn:name="VOLUMEID",type=string,JSONPath=`.status.volumeID`,priority=1
+//+kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// LogicalVolume is the Schema for the logicalvolumes API
+//
+// This version is not served until topolvm-controller configures the conversion webhook.
+type LogicalVolume struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LogicalVolumeSpec   `json:"spec,omitempty"`
+	Status LogicalVolumeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LogicalVolumeList contains a list of LogicalVolume
+type LogicalVolumeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LogicalVolume `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LogicalVolume{}, &LogicalVolumeList{})
+}
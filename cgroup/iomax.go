@@ -0,0 +1,117 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultRoot is the default mount point of the cgroup v2 unified hierarchy.
+const DefaultRoot = "/sys/fs/cgroup"
+
+const ioMaxFile = "io.max"
+
+// IOMax represents the limits of a device in io.max.  Zero means unlimited.
+type IOMax struct {
+	ReadBPS   uint64
+	WriteBPS  uint64
+	ReadIOPS  uint64
+	WriteIOPS uint64
+}
+
+func formatLimit(v uint64) string {
+	if v == 0 {
+		return "max"
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+func (m IOMax) format(major, minor uint32) string {
+	return fmt.Sprintf("%d:%d rbps=%s wbps=%s riops=%s wiops=%s", major, minor,
+		formatLimit(m.ReadBPS), formatLimit(m.WriteBPS), formatLimit(m.ReadIOPS), formatLimit(m.WriteIOPS))
+}
+
+// parseIOMax returns the limits of the device from the content of io.max.
+func parseIOMax(data string, major, minor uint32) (IOMax, error) {
+	var m IOMax
+	dev := fmt.Sprintf("%d:%d", major, minor)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != dev {
+			continue
+		}
+		for _, f := range fields[1:] {
+			kv := strings.SplitN(f, "=", 2)
+			if len(kv) != 2 {
+				return m, fmt.Errorf("invalid io.max entry: %s", line)
+			}
+			var v uint64
+			if kv[1] != "max" {
+				var err error
+				v, err = strconv.ParseUint(kv[1], 10, 64)
+				if err != nil {
+					return m, fmt.Errorf("invalid io.max entry: %s", line)
+				}
+			}
+			switch kv[0] {
+			case "rbps":
+				m.ReadBPS = v
+			case "wbps":
+				m.WriteBPS = v
+			case "riops":
+				m.ReadIOPS = v
+			case "wiops":
+				m.WriteIOPS = v
+			}
+		}
+	}
+	return m, nil
+}
+
+// FindPodCgroup returns the path of the pod-level cgroup of the pod.
+// Both cgroupfs and systemd cgroup drivers of kubelet are supported.
+func FindPodCgroup(root, podUID string) (string, error) {
+	systemdUID := strings.ReplaceAll(podUID, "-", "_")
+	candidates := []string{
+		filepath.Join(root, "kubepods", "pod"+podUID),
+		filepath.Join(root, "kubepods", "burstable", "pod"+podUID),
+		filepath.Join(root, "kubepods", "besteffort", "pod"+podUID),
+		filepath.Join(root, "kubepods.slice", "kubepods-pod"+systemdUID+".slice"),
+		filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice", "kubepods-burstable-pod"+systemdUID+".slice"),
+		filepath.Join(root, "kubepods.slice", "kubepods-besteffort.slice", "kubepods-besteffort-pod"+systemdUID+".slice"),
+	}
+	for _, c := range candidates {
+		fi, err := os.Stat(c)
+		if err == nil && fi.IsDir() {
+			return c, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("cgroup of pod %s is not found under %s: %w", podUID, root, os.ErrNotExist)
+}
+
+// SetIOMax sets the limits of the device to io.max of the cgroup.
+// It returns true if io.max is updated, or false if the limits are already set.
+func SetIOMax(cgroupPath string, major, minor uint32, limits IOMax) (bool, error) {
+	p := filepath.Join(cgroupPath, ioMaxFile)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+	current, err := parseIOMax(string(data), major, minor)
+	if err != nil {
+		return false, err
+	}
+	if current == limits {
+		return false, nil
+	}
+
+	if err := os.WriteFile(p, []byte(limits.format(major, minor)), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", p, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIOMax(t *testing.T) {
+	data := `8:0 rbps=1048576 wbps=max riops=max wiops=100
+253:3 rbps=max wbps=2097152 riops=500 wiops=max
+`
+	m, err := parseIOMax(data, 253, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := IOMax{WriteBPS: 2097152, ReadIOPS: 500}
+	if m != expected {
+		t.Errorf("expected %+v, actual %+v", expected, m)
+	}
+
+	m, err = parseIOMax(data, 253, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (IOMax{}) {
+		t.Errorf("device without entry should be unlimited: %+v", m)
+	}
+
+	_, err = parseIOMax("253:3 rbps=abc\n", 253, 3)
+	if err == nil {
+		t.Error("invalid entry should be an error")
+	}
+}
+
+func TestFindPodCgroup(t *testing.T) {
+	root := t.TempDir()
+	uid := "0f6e9b1c-5a4d-4c2b-9f7e-3d2c1b0a9e8f"
+
+	_, err := FindPodCgroup(root, uid)
+	if err == nil {
+		t.Error("cgroup should not be found")
+	}
+
+	p := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice",
+		"kubepods-burstable-pod0f6e9b1c_5a4d_4c2b_9f7e_3d2c1b0a9e8f.slice")
+	if err := os.MkdirAll(p, 0755); err != nil {
+		t.Fatal(err)
+	}
+	found, err := FindPodCgroup(root, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != p {
+		t.Errorf("expected %s, actual %s", p, found)
+	}
+}
+
+func TestSetIOMax(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, ioMaxFile)
+	if err := os.WriteFile(p, []byte("253:3 rbps=1024 wbps=max riops=max wiops=max\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := SetIOMax(dir, 253, 3, IOMax{ReadBPS: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("io.max should not be written if the limits are already set")
+	}
+
+	changed, err = SetIOMax(dir, 253, 3, IOMax{WriteIOPS: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("io.max should be written")
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "253:3 rbps=max wbps=max riops=max wiops=100" {
+		t.Errorf("unexpected io.max: %s", data)
+	}
+}
@@ -5,6 +5,13 @@ import corev1 "k8s.io/api/core/v1"
 // CapacityKeyPrefix is the key prefix of Node annotation that represents VG free space.
 const CapacityKeyPrefix = "capacity.topolvm.cybozu.com/"
 
+// TotalCapacityKeyPrefix is the key prefix of Node annotation that represents VG size.
+const TotalCapacityKeyPrefix = "total-capacity.topolvm.cybozu.com/"
+
+// CapacityResourcePrefix is the name prefix of the extended resources of Node and Pod
+// that represent VG free space and the requested capacity of each device-class in bytes.
+const CapacityResourcePrefix = "capacity.topolvm.cybozu.com/"
+
 // CapacityResource is the resource name of topolvm capacity.
 const CapacityResource = corev1.ResourceName("topolvm.cybozu.com/capacity")
 
@@ -17,12 +24,62 @@ const TopologyNodeKey = "topology.topolvm.cybozu.com/node"
 // DeviceClassKey is the key used in CSI volume create requests to specify a device-class.
 const DeviceClassKey = "topolvm.cybozu.com/device-class"
 
+// FSTrimIntervalKey is the key of StorageClass parameter that specifies the interval of
+// periodic fstrim for the filesystems of the volumes.
+const FSTrimIntervalKey = "topolvm.cybozu.com/fstrim-interval"
+
+// ReadBPSKey is the key of StorageClass parameter that limits the read bytes per second of the volumes.
+const ReadBPSKey = "topolvm.cybozu.com/read-bps"
+
+// WriteBPSKey is the key of StorageClass parameter that limits the write bytes per second of the volumes.
+const WriteBPSKey = "topolvm.cybozu.com/write-bps"
+
+// ReadIOPSKey is the key of StorageClass parameter that limits the read operations per second of the volumes.
+const ReadIOPSKey = "topolvm.cybozu.com/read-iops"
+
+// WriteIOPSKey is the key of StorageClass parameter that limits the write operations per second of the volumes.
+const WriteIOPSKey = "topolvm.cybozu.com/write-iops"
+
+// MkfsOptionsKey is the key of StorageClass parameter and volume context that specifies
+// the options of mkfs used at the first format of the volumes.
+const MkfsOptionsKey = "topolvm.cybozu.com/mkfs-options"
+
+// BtrfsCompressionKey is the key of StorageClass parameter and volume context that specifies
+// the compression algorithm of btrfs volumes, e.g. "zstd:3".
+const BtrfsCompressionKey = "topolvm.cybozu.com/btrfs-compression"
+
 // ResizeRequestedAtKey is the key of LogicalVolume that represents the timestamp of the resize request.
 const ResizeRequestedAtKey = "topolvm.cybozu.com/resize-requested-at"
 
+// ConversionDataKey is the key of LogicalVolume annotation that keeps the fields of
+// topolvm.cybozu.com/v2 that cannot be represented in topolvm.cybozu.com/v1.
+const ConversionDataKey = "topolvm.cybozu.com/conversion-data"
+
+// StorageClassKey is the key of LogicalVolume annotation that specifies the StorageClass
+// of the PersistentVolume created for an adopted logical volume.
+const StorageClassKey = "topolvm.cybozu.com/storage-class"
+
 // LogicalVolumeFinalizer is the name of LogicalVolume finalizer
 const LogicalVolumeFinalizer = "topolvm.cybozu.com/logicalvolume"
 
+// MigratingKey is the key of LogicalVolume annotation that represents the name of
+// VolumeMigration switching the volume.  The volume cannot be staged while this is set.
+const MigratingKey = "topolvm.cybozu.com/migrating"
+
+// OriginalPVKey is the key of VolumeMigration annotation that keeps the PersistentVolume
+// while it is recreated on the target node.
+const OriginalPVKey = "topolvm.cybozu.com/original-pv"
+
+// LVMdStreamAddressKey is the key of Node annotation that registers the address of
+// LVStreamService of lvmd on the node.  Volumes are pulled only from this address.
+const LVMdStreamAddressKey = "topolvm.cybozu.com/lvmd-stream-address"
+
+// MigrationSourceFinalizer is the name of VolumeMigration finalizer for the source node
+const MigrationSourceFinalizer = "topolvm.cybozu.com/migration-source"
+
+// MigrationTargetFinalizer is the name of VolumeMigration finalizer for the target node
+const MigrationTargetFinalizer = "topolvm.cybozu.com/migration-target"
+
 // NodeFinalizer is the name of Node finalizer of TopoLVM
 const NodeFinalizer = "topolvm.cybozu.com/node"
 
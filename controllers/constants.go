@@ -11,4 +11,11 @@ const (
 	// be dynamically provisioned. Its value is the name of the selected node.
 	// https://github.com/kubernetes/kubernetes/blob/9bae1bc56804db4905abebcd408e0f02e199ab93/pkg/controller/volume/persistentvolume/util/util.go#L53
 	AnnSelectedNode = "volume.kubernetes.io/selected-node"
+
+	// AnnProvisionedBy annotation is added to a PV provisioned by the CSI driver.
+	// external-provisioner calls DeleteVolume only for PVs with this annotation.
+	AnnProvisionedBy = "pv.kubernetes.io/provisioned-by"
+
+	// fsTypeParameter is the StorageClass parameter that specifies the filesystem of volumes.
+	fsTypeParameter = "csi.storage.k8s.io/fstype"
 )
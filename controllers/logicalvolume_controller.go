@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/topolvm/topolvm"
@@ -10,8 +12,11 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -83,6 +88,14 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{Requeue: true}, nil
 		}
 
+		if lv.Status.VolumeID == "" && lv.Spec.ExistingLVName != "" {
+			err := r.adoptLV(ctx, log, lv)
+			if err != nil {
+				log.Error(err, "failed to adopt LV", "name", lv.Name, "existingLVName", lv.Spec.ExistingLVName)
+			}
+			return ctrl.Result{}, err
+		}
+
 		if lv.Status.VolumeID == "" {
 			err := r.createLV(ctx, log, lv)
 			if err != nil {
@@ -91,9 +104,14 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{}, err
 		}
 
-		err := r.expandLV(ctx, log, lv)
-		if err != nil {
+		if err := r.expandLV(ctx, log, lv); err != nil {
 			log.Error(err, "failed to expand LV", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+
+		err := r.updateVolumeStatus(ctx, log, lv)
+		if err != nil {
+			log.Error(err, "failed to update status of LV", "name", lv.Name)
 		}
 		return ctrl.Result{}, err
 	}
@@ -105,8 +123,22 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	log.Info("start finalizing LogicalVolume", "name", lv.Name)
+	if !meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.LogicalVolumeDeleting) {
+		lv.SetCondition(topolvmv1.LogicalVolumeDeleting, metav1.ConditionTrue, topolvmv1.ReasonDeleting, "")
+		lv.UpdatePhase()
+		if err := r.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+	}
 	err := r.removeLVIfExists(ctx, log, lv)
 	if err != nil {
+		code, message := extractFromError(err)
+		lv.SetCondition(topolvmv1.LogicalVolumeDeleting, metav1.ConditionTrue, code.String(), message)
+		if err2 := r.Status().Update(ctx, lv); err2 != nil {
+			// err2 is logged but not returned because err is more important
+			log.Error(err2, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		}
 		return ctrl.Result{}, err
 	}
 
@@ -137,19 +169,20 @@ func (r *LogicalVolumeReconciler) removeLVIfExists(ctx context.Context, log logr
 		return err
 	}
 
+	name := lv.LVName()
 	for _, v := range respList.Volumes {
-		if v.Name != string(lv.UID) {
+		if v.Name != name {
 			continue
 		}
-		_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: string(lv.UID), DeviceClass: lv.Spec.DeviceClass})
+		_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: name, DeviceClass: lv.Spec.DeviceClass})
 		if err != nil {
-			log.Error(err, "failed to remove LV", "name", lv.Name, "uid", lv.UID)
+			log.Error(err, "failed to remove LV", "name", lv.Name, "uid", lv.UID, "lvName", name)
 			return err
 		}
-		log.Info("removed LV", "name", lv.Name, "uid", lv.UID)
+		log.Info("removed LV", "name", lv.Name, "uid", lv.UID, "lvName", name)
 		return nil
 	}
-	log.Info("LV already removed", "name", lv.Name, "uid", lv.UID)
+	log.Info("LV already removed", "name", lv.Name, "uid", lv.UID, "lvName", name)
 	return nil
 }
 
@@ -170,9 +203,9 @@ func (r *LogicalVolumeReconciler) volumeExists(ctx context.Context, log logr.Log
 }
 
 func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
-	// When lv.Status.Code is not codes.OK (== 0), CreateLV has already failed.
+	// When Created condition is False, CreateLV has already failed.
 	// LogicalVolume CRD will be deleted soon by the controller.
-	if lv.Status.Code != codes.OK {
+	if meta.IsStatusConditionFalse(lv.Status.Conditions, topolvmv1.LogicalVolumeCreated) {
 		return nil
 	}
 
@@ -182,15 +215,13 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 		// In case the controller crashed just after LVM LV creation, LV may already exist.
 		found, err := r.volumeExists(ctx, log, lv)
 		if err != nil {
-			lv.Status.Code = codes.Internal
-			lv.Status.Message = "failed to check volume existence"
+			setFailure(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionFalse, codes.Internal, "failed to check volume existence")
 			return err
 		}
 		if found {
 			log.Info("set volumeID to existing LogicalVolume", "name", lv.Name, "uid", lv.UID, "status.volumeID", lv.Status.VolumeID)
 			lv.Status.VolumeID = string(lv.UID)
-			lv.Status.Code = codes.OK
-			lv.Status.Message = ""
+			setSuccess(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionTrue, topolvmv1.ReasonCreated)
 			return nil
 		}
 
@@ -198,17 +229,17 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 		if err != nil {
 			code, message := extractFromError(err)
 			log.Error(err, message)
-			lv.Status.Code = code
-			lv.Status.Message = message
+			setFailure(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionFalse, code, message)
 			return err
 		}
 
 		lv.Status.VolumeID = resp.Volume.Name
 		lv.Status.CurrentSize = resource.NewQuantity(reqBytes, resource.BinarySI)
-		lv.Status.Code = codes.OK
-		lv.Status.Message = ""
+		setDevice(lv, resp.Volume)
+		setSuccess(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionTrue, topolvmv1.ReasonCreated)
 		return nil
 	}()
+	lv.UpdatePhase()
 
 	if err != nil {
 		if err2 := r.Status().Update(ctx, lv); err2 != nil {
@@ -227,6 +258,68 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 	return nil
 }
 
+// adoptLV adopts an existing LVM logical volume specified by lv.Spec.ExistingLVName.
+// The logical volume is never created nor renamed.
+func (r *LogicalVolumeReconciler) adoptLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+	// When Created condition is False, AdoptLV has already failed.
+	if meta.IsStatusConditionFalse(lv.Status.Conditions, topolvmv1.LogicalVolumeCreated) {
+		return nil
+	}
+
+	name := lv.Spec.ExistingLVName
+	err := func() error {
+		lvList := new(topolvmv1.LogicalVolumeList)
+		if err := r.List(ctx, lvList); err != nil {
+			setFailure(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionFalse, codes.Internal, "failed to list LogicalVolume")
+			return err
+		}
+		for _, other := range lvList.Items {
+			if other.UID == lv.UID || other.Spec.NodeName != lv.Spec.NodeName || other.Spec.DeviceClass != lv.Spec.DeviceClass {
+				continue
+			}
+			// Concurrent adoptions are rejected by lvmd with the owner tag.
+			if other.Status.VolumeID != "" && other.LVName() == name {
+				message := fmt.Sprintf("LV %s is already used by LogicalVolume %s", name, other.Name)
+				setFailure(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionFalse, codes.AlreadyExists, message)
+				return errors.New(message)
+			}
+		}
+
+		resp, err := r.lvService.AdoptLV(ctx, &proto.AdoptLVRequest{Name: name, DeviceClass: lv.Spec.DeviceClass, Owner: lv.Name})
+		if err != nil {
+			code, message := extractFromError(err)
+			log.Error(err, message)
+			setFailure(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionFalse, code, message)
+			return err
+		}
+
+		// The name of the existing LV is unique only on the node, so the UID is used as the volume ID.
+		lv.Status.VolumeID = string(lv.UID)
+		lv.Status.LVName = resp.Volume.Name
+		lv.Status.CurrentSize = resource.NewQuantity(int64(resp.Volume.SizeBytes), resource.BinarySI)
+		setDevice(lv, resp.Volume)
+		setSuccess(lv, topolvmv1.LogicalVolumeCreated, metav1.ConditionTrue, topolvmv1.ReasonAdopted)
+		return nil
+	}()
+	lv.UpdatePhase()
+
+	if err != nil {
+		if err2 := r.Status().Update(ctx, lv); err2 != nil {
+			// err2 is logged but not returned because err is more important
+			log.Error(err2, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		}
+		return err
+	}
+
+	if err := r.Status().Update(ctx, lv); err != nil {
+		log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		return err
+	}
+
+	log.Info("adopted existing LV", "name", lv.Name, "uid", lv.UID, "status.volumeID", lv.Status.VolumeID, "status.lvName", lv.Status.LVName)
+	return nil
+}
+
 func (r *LogicalVolumeReconciler) expandLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
 	// lv.Status.CurrentSize is added in v0.4.0 and filled by topolvm-controller when resizing is triggered.
 	// The reconciliation loop of LogicalVolume may call expandLV before resizing is triggered.
@@ -242,21 +335,33 @@ func (r *LogicalVolumeReconciler) expandLV(ctx context.Context, log logr.Logger,
 	origBytes := (*lv.Status.CurrentSize).Value()
 	reqBytes := lv.Spec.Size.Value()
 
+	// Resizing condition is set before calling lvmd to show the progress.
+	// If it is already set for this generation, the previous attempt has failed and is retried.
+	resizing := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.LogicalVolumeResizing)
+	if resizing == nil || resizing.Status != metav1.ConditionTrue || resizing.ObservedGeneration != lv.Generation {
+		lv.SetCondition(topolvmv1.LogicalVolumeResizing, metav1.ConditionTrue, topolvmv1.ReasonResizing,
+			fmt.Sprintf("resizing from %d to %d bytes", origBytes, reqBytes))
+		lv.UpdatePhase()
+		if err := r.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return err
+		}
+	}
+
 	err := func() error {
-		_, err := r.lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: string(lv.UID), SizeGb: uint64(reqBytes >> 30), DeviceClass: lv.Spec.DeviceClass})
+		_, err := r.lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: lv.LVName(), SizeGb: uint64(reqBytes >> 30), DeviceClass: lv.Spec.DeviceClass})
 		if err != nil {
 			code, message := extractFromError(err)
 			log.Error(err, message)
-			lv.Status.Code = code
-			lv.Status.Message = message
+			setFailure(lv, topolvmv1.LogicalVolumeResizing, metav1.ConditionTrue, code, message)
 			return err
 		}
 
 		lv.Status.CurrentSize = resource.NewQuantity(reqBytes, resource.BinarySI)
-		lv.Status.Code = codes.OK
-		lv.Status.Message = ""
+		setSuccess(lv, topolvmv1.LogicalVolumeResizing, metav1.ConditionFalse, topolvmv1.ReasonResized)
 		return nil
 	}()
+	lv.UpdatePhase()
 
 	if err != nil {
 		if err2 := r.Status().Update(ctx, lv); err2 != nil {
@@ -325,6 +430,86 @@ func removeString(slice []string, s string) (result []string) {
 	return
 }
 
+// updateVolumeStatus updates the device and Degraded condition of the created LV.
+// The status is not updated if nothing is changed.
+func (r *LogicalVolumeReconciler) updateVolumeStatus(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+	respList, err := r.vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: lv.Spec.DeviceClass})
+	if err != nil {
+		log.Error(err, "failed to get list of LV")
+		return err
+	}
+
+	var vol *proto.LogicalVolume
+	name := lv.LVName()
+	for _, v := range respList.Volumes {
+		if v.Name == name {
+			vol = v
+			break
+		}
+	}
+
+	lv2 := lv.DeepCopy()
+	if vol != nil {
+		setDevice(lv2, vol)
+	}
+	// LogicalVolumes created by older versions have the volume ID without Created condition.
+	if meta.FindStatusCondition(lv2.Status.Conditions, topolvmv1.LogicalVolumeCreated) == nil {
+		reason := topolvmv1.ReasonCreated
+		if lv2.Spec.ExistingLVName != "" {
+			reason = topolvmv1.ReasonAdopted
+		}
+		lv2.SetCondition(topolvmv1.LogicalVolumeCreated, metav1.ConditionTrue, reason, "")
+	}
+	status, reason, message := degradedCondition(vol)
+	lv2.SetCondition(topolvmv1.LogicalVolumeDegraded, status, reason, message)
+	lv2.UpdatePhase()
+	if equality.Semantic.DeepEqual(lv.Status, lv2.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, lv2); err != nil {
+		log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		return err
+	}
+	return nil
+}
+
+// degradedCondition returns Degraded condition of the LV reported by lvmd.
+func degradedCondition(vol *proto.LogicalVolume) (metav1.ConditionStatus, string, string) {
+	switch {
+	case vol == nil:
+		return metav1.ConditionTrue, topolvmv1.ReasonNotFound, "logical volume is not found"
+	case vol.Inactive:
+		return metav1.ConditionTrue, topolvmv1.ReasonInactive, "logical volume is not active"
+	case vol.Partial:
+		return metav1.ConditionTrue, topolvmv1.ReasonPartial, "one or more physical volumes of the logical volume are missing"
+	case vol.PoolDataPercent >= 100:
+		return metav1.ConditionTrue, topolvmv1.ReasonPoolFull, "thin pool is out of data space"
+	}
+	return metav1.ConditionFalse, topolvmv1.ReasonHealthy, ""
+}
+
+// setDevice sets the device of the LV reported by lvmd.
+func setDevice(lv *topolvmv1.LogicalVolume, vol *proto.LogicalVolume) {
+	lv.Status.DevicePath = vol.Path
+	lv.Status.DevMajor = vol.DevMajor
+	lv.Status.DevMinor = vol.DevMinor
+}
+
+// setSuccess sets the condition and clears the deprecated code and message.
+func setSuccess(lv *topolvmv1.LogicalVolume, condType string, status metav1.ConditionStatus, reason string) {
+	lv.Status.Code = codes.OK
+	lv.Status.Message = ""
+	lv.SetCondition(condType, status, reason, "")
+}
+
+// setFailure sets the condition with the name of code as the reason.
+// The deprecated code and message are also set for compatibility.
+func setFailure(lv *topolvmv1.LogicalVolume, condType string, status metav1.ConditionStatus, code codes.Code, message string) {
+	lv.Status.Code = code
+	lv.Status.Message = message
+	lv.SetCondition(condType, status, code.String(), message)
+}
+
 func extractFromError(err error) (codes.Code, string) {
 	s, ok := status.FromError(err)
 	if !ok {
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeAdoptLVService struct {
+	proto.LVServiceClient
+}
+
+func (s *fakeAdoptLVService) AdoptLV(_ context.Context, req *proto.AdoptLVRequest, _ ...grpc.CallOption) (*proto.AdoptLVResponse, error) {
+	return &proto.AdoptLVResponse{Volume: &proto.LogicalVolume{Name: req.GetName(), SizeBytes: 1 << 30}}, nil
+}
+
+func TestAdoptLV(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	adopting := func(name, uid, nodeName string) *topolvmv1.LogicalVolume {
+		return &topolvmv1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(uid)},
+			Spec: topolvmv1.LogicalVolumeSpec{
+				Name:           name,
+				NodeName:       nodeName,
+				ExistingLVName: "data",
+			},
+		}
+	}
+	lv1 := adopting("lv1", "7d1c4f6e-0c0e-4a5b-9a39-2f3c1f0d9a01", "node1")
+	lv2 := adopting("lv2", "7d1c4f6e-0c0e-4a5b-9a39-2f3c1f0d9a02", "node2")
+	lv3 := adopting("lv3", "7d1c4f6e-0c0e-4a5b-9a39-2f3c1f0d9a03", "node1")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lv1, lv2, lv3).Build()
+
+	ctx := context.Background()
+	log := ctrl.Log.WithName("test")
+	adopt := func(nodeName, name string) (*topolvmv1.LogicalVolume, error) {
+		r := &LogicalVolumeReconciler{Client: c, nodeName: nodeName, lvService: &fakeAdoptLVService{}}
+		lv := new(topolvmv1.LogicalVolume)
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, lv); err != nil {
+			t.Fatal(err)
+		}
+		err := r.adoptLV(ctx, log, lv)
+		if err2 := c.Get(ctx, client.ObjectKey{Name: name}, lv); err2 != nil {
+			t.Fatal(err2)
+		}
+		return lv, err
+	}
+
+	// LVs with the same name on different nodes get different volume IDs.
+	for _, lv := range []*topolvmv1.LogicalVolume{lv1, lv2} {
+		adopted, err := adopt(lv.Spec.NodeName, lv.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if adopted.Status.VolumeID != string(lv.UID) {
+			t.Errorf("volume ID of %s should be the UID: %s", lv.Name, adopted.Status.VolumeID)
+		}
+		if adopted.LVName() != "data" {
+			t.Errorf("LV name of %s should be data: %s", lv.Name, adopted.LVName())
+		}
+		if !meta.IsStatusConditionTrue(adopted.Status.Conditions, topolvmv1.LogicalVolumeCreated) {
+			t.Errorf("%s should be created: %v", lv.Name, adopted.Status.Conditions)
+		}
+	}
+
+	// The LV adopted on the same node cannot be adopted again.
+	adopted, err := adopt("node1", "lv3")
+	if err == nil {
+		t.Fatal("lv3 should not adopt the LV of lv1")
+	}
+	if adopted.Status.VolumeID != "" || !meta.IsStatusConditionFalse(adopted.Status.Conditions, topolvmv1.LogicalVolumeCreated) {
+		t.Errorf("lv3 should fail: %#v", adopted.Status)
+	}
+}
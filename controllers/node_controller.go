@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -132,6 +133,16 @@ func (r *NodeReconciler) doFinalize(ctx context.Context, log logr.Logger, node *
 	}
 
 	for _, lv := range lvList.Items {
+		retained, err := r.isRetained(ctx, &lv)
+		if err != nil {
+			log.Error(err, "failed to get PersistentVolume", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+		if retained {
+			log.Info("retained LogicalVolume", "name", lv.Name)
+			continue
+		}
+
 		err = r.cleanupLogicalVolume(ctx, log, &lv)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -141,6 +152,21 @@ func (r *NodeReconciler) doFinalize(ctx context.Context, log logr.Logger, node *
 	return ctrl.Result{}, nil
 }
 
+// isRetained returns true if the LogicalVolume should survive the deletion of the node
+// because its PersistentVolume has Retain reclaim policy.
+func (r *NodeReconciler) isRetained(ctx context.Context, lv *topolvmv1.LogicalVolume) (bool, error) {
+	pv := &corev1.PersistentVolume{}
+	err := r.Get(ctx, types.NamespacedName{Name: lv.Name}, pv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		pv = nil
+	default:
+		return false, err
+	}
+	return isRetained(lv, pv), nil
+}
+
 func (r *NodeReconciler) cleanupLogicalVolume(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
 	finExists := false
 	for _, fin := range lv.Finalizers {
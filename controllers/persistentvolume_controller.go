@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// PersistentVolumeReconciler reconciles a PersistentVolume object
+type PersistentVolumeReconciler struct {
+	client.Client
+}
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=logicalvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=logicalvolumes/status,verbs=get;update;patch
+
+// Reconcile updates the phase of LogicalVolume from its PersistentVolume.
+// LogicalVolume and PersistentVolume share the same name.
+// It also creates the PersistentVolume of LogicalVolume that has adopted an existing logical volume.
+func (r *PersistentVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	lv := &topolvmv1.LogicalVolume{}
+	err := r.Get(ctx, types.NamespacedName{Name: req.Name}, lv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	if lv.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	pv := &corev1.PersistentVolume{}
+	err = r.Get(ctx, req.NamespacedName, pv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		pv = nil
+	default:
+		return ctrl.Result{}, err
+	}
+
+	// status.persistentVolumePhase is empty until the PersistentVolume is found, so the PersistentVolume
+	// deleted after that is not recreated.
+	if pv == nil && lv.Status.PersistentVolumePhase == "" && isAdopted(lv) {
+		if err := r.createPV(ctx, log, lv); err != nil {
+			log.Error(err, "failed to create PersistentVolume", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	phase := persistentVolumePhase(lv, pv)
+	if phase == lv.Status.PersistentVolumePhase {
+		return ctrl.Result{}, nil
+	}
+
+	lv2 := lv.DeepCopy()
+	lv2.Status.PersistentVolumePhase = phase
+	patch := client.MergeFrom(lv)
+	if err := r.Status().Patch(ctx, lv2, patch); err != nil {
+		log.Error(err, "failed to update phase", "name", lv.Name)
+		return ctrl.Result{}, err
+	}
+	log.Info("updated phase", "name", lv.Name, "phase", phase)
+	return ctrl.Result{}, nil
+}
+
+// isAdopted returns true if lv has adopted an existing logical volume on the node.
+func isAdopted(lv *topolvmv1.LogicalVolume) bool {
+	return lv.Spec.ExistingLVName != "" && lv.Status.CurrentSize != nil &&
+		meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.LogicalVolumeCreated)
+}
+
+// createPV creates the PersistentVolume for the adopted logical volume so that a PVC can be
+// bound to it.  The StorageClass in the annotation, if any, gives the settings of the PersistentVolume.
+func (r *PersistentVolumeReconciler) createPV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        lv.Name,
+			Annotations: map[string]string{AnnProvisionedBy: topolvm.PluginName},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: *lv.Status.CurrentSize},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       topolvm.PluginName,
+					VolumeHandle: lv.Status.VolumeID,
+				},
+			},
+			NodeAffinity: nodeAffinity(lv.Spec.NodeName),
+		},
+	}
+	if name := lv.Annotations[topolvm.StorageClassKey]; name != "" {
+		sc := new(storagev1.StorageClass)
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, sc); err != nil {
+			return err
+		}
+		pv.Spec.StorageClassName = sc.Name
+		if sc.ReclaimPolicy != nil {
+			pv.Spec.PersistentVolumeReclaimPolicy = *sc.ReclaimPolicy
+		}
+		pv.Spec.MountOptions = sc.MountOptions
+		pv.Spec.CSI.FSType = sc.Parameters[fsTypeParameter]
+	}
+
+	err := r.Create(ctx, pv)
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("created PersistentVolume for adopted LV", "name", lv.Name, "status.volumeID", lv.Status.VolumeID)
+	return nil
+}
+
+// persistentVolumePhase returns the phase of lv for its PersistentVolume pv.
+// pv is nil if the PersistentVolume does not exist.
+func persistentVolumePhase(lv *topolvmv1.LogicalVolume, pv *corev1.PersistentVolume) topolvmv1.PersistentVolumePhase {
+	if pv == nil {
+		// LogicalVolume is created before its PersistentVolume by CreateVolume,
+		// so it is orphaned only if the PersistentVolume has been seen.
+		if lv.Status.PersistentVolumePhase == "" {
+			return ""
+		}
+		return topolvmv1.PersistentVolumeOrphaned
+	}
+
+	switch pv.Status.Phase {
+	case corev1.VolumeBound:
+		return topolvmv1.PersistentVolumeBound
+	case corev1.VolumeReleased, corev1.VolumeAvailable:
+		if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+			return topolvmv1.PersistentVolumeReleased
+		}
+	}
+	// The PersistentVolume is not bound, and is going to be deleted along with
+	// the logical volume unless it is retained.
+	return ""
+}
+
+// isRetained returns true if the logical volume must be kept after its claim or node is deleted.
+func isRetained(lv *topolvmv1.LogicalVolume, pv *corev1.PersistentVolume) bool {
+	if pv == nil {
+		return lv.Status.PersistentVolumePhase == topolvmv1.PersistentVolumeOrphaned
+	}
+	return pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain
+}
+
+func isTopoLVMVolume(o client.Object) bool {
+	pv, ok := o.(*corev1.PersistentVolume)
+	if !ok {
+		return false
+	}
+	return pv.Spec.CSI != nil && pv.Spec.CSI.Driver == topolvm.PluginName
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PersistentVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	pred := predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isTopoLVMVolume(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isTopoLVMVolume(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isTopoLVMVolume(e.ObjectNew) },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+
+	// LogicalVolumes are also watched to find those whose PersistentVolume
+	// had been deleted while topolvm-controller was not running.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("persistentvolume").
+		For(&corev1.PersistentVolume{}, builder.WithPredicates(pred)).
+		Watches(&source.Kind{Type: &topolvmv1.LogicalVolume{}}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testPV(phase corev1.PersistentVolumePhase, policy corev1.PersistentVolumeReclaimPolicy) *corev1.PersistentVolume {
+	pv := &corev1.PersistentVolume{}
+	pv.Spec.PersistentVolumeReclaimPolicy = policy
+	pv.Status.Phase = phase
+	return pv
+}
+
+func TestPersistentVolumePhase(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  topolvmv1.PersistentVolumePhase
+		pv       *corev1.PersistentVolume
+		expected topolvmv1.PersistentVolumePhase
+	}{
+		{"not created yet", "", nil, ""},
+		{"deleted bound", topolvmv1.PersistentVolumeBound, nil, topolvmv1.PersistentVolumeOrphaned},
+		{"deleted released", topolvmv1.PersistentVolumeReleased, nil, topolvmv1.PersistentVolumeOrphaned},
+		{"orphaned", topolvmv1.PersistentVolumeOrphaned, nil, topolvmv1.PersistentVolumeOrphaned},
+		{"pending", "", testPV(corev1.VolumePending, corev1.PersistentVolumeReclaimDelete), ""},
+		{"bound", "", testPV(corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete), topolvmv1.PersistentVolumeBound},
+		{"bound retain", "", testPV(corev1.VolumeBound, corev1.PersistentVolumeReclaimRetain), topolvmv1.PersistentVolumeBound},
+		{"released retain", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain), topolvmv1.PersistentVolumeReleased},
+		{"available retain", topolvmv1.PersistentVolumeReleased, testPV(corev1.VolumeAvailable, corev1.PersistentVolumeReclaimRetain), topolvmv1.PersistentVolumeReleased},
+		{"released delete", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeReleased, corev1.PersistentVolumeReclaimDelete), ""},
+		{"available delete", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeAvailable, corev1.PersistentVolumeReclaimDelete), ""},
+		{"failed delete", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeFailed, corev1.PersistentVolumeReclaimDelete), ""},
+	}
+	for _, c := range cases {
+		lv := &topolvmv1.LogicalVolume{}
+		lv.Status.PersistentVolumePhase = c.current
+		if actual := persistentVolumePhase(lv, c.pv); actual != c.expected {
+			t.Errorf("%s: expected %q, actual %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestIsRetained(t *testing.T) {
+	cases := []struct {
+		name     string
+		phase    topolvmv1.PersistentVolumePhase
+		pv       *corev1.PersistentVolume
+		expected bool
+	}{
+		{"not created yet", "", nil, false},
+		{"orphaned", topolvmv1.PersistentVolumeOrphaned, nil, true},
+		{"bound delete", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete), false},
+		{"released delete", "", testPV(corev1.VolumeReleased, corev1.PersistentVolumeReclaimDelete), false},
+		{"bound retain", topolvmv1.PersistentVolumeBound, testPV(corev1.VolumeBound, corev1.PersistentVolumeReclaimRetain), true},
+		{"released retain", topolvmv1.PersistentVolumeReleased, testPV(corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain), true},
+	}
+	for _, c := range cases {
+		lv := &topolvmv1.LogicalVolume{}
+		lv.Status.PersistentVolumePhase = c.phase
+		if actual := isRetained(lv, c.pv); actual != c.expected {
+			t.Errorf("%s: expected %v, actual %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestCreatePV(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	size := resource.MustParse("1536Mi")
+	lv := &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "imported",
+			UID:         "7d1c4f6e-0c0e-4a5b-9a39-2f3c1f0d9a01",
+			Annotations: map[string]string{topolvm.StorageClassKey: "topolvm-provisioner"},
+		},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			Name:           "imported",
+			NodeName:       "node1",
+			ExistingLVName: "data",
+		},
+	}
+	policy := corev1.PersistentVolumeReclaimDelete
+	sc := &storagev1.StorageClass{
+		ObjectMeta:    metav1.ObjectMeta{Name: "topolvm-provisioner"},
+		Provisioner:   topolvm.PluginName,
+		Parameters:    map[string]string{fsTypeParameter: "ext4"},
+		ReclaimPolicy: &policy,
+		MountOptions:  []string{"debug"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lv, sc).Build()
+	r := &PersistentVolumeReconciler{Client: c}
+
+	ctx := context.Background()
+	key := client.ObjectKey{Name: "imported"}
+	reconcile := func() {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "imported"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The PersistentVolume is not created until the logical volume is adopted.
+	reconcile()
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, key, pv); err == nil {
+		t.Fatal("PersistentVolume should not be created before adoption")
+	}
+
+	if err := c.Get(ctx, key, lv); err != nil {
+		t.Fatal(err)
+	}
+	lv.Status.VolumeID = string(lv.UID)
+	lv.Status.LVName = "data"
+	lv.Status.CurrentSize = &size
+	lv.SetCondition(topolvmv1.LogicalVolumeCreated, metav1.ConditionTrue, topolvmv1.ReasonAdopted, "")
+	if err := c.Status().Update(ctx, lv); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	// The PersistentVolume already exists.
+	reconcile()
+
+	if err := c.Get(ctx, key, pv); err != nil {
+		t.Fatal(err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != topolvm.PluginName || pv.Spec.CSI.VolumeHandle != string(lv.UID) || pv.Spec.CSI.FSType != "ext4" {
+		t.Errorf("unexpected CSI source: %#v", pv.Spec.CSI)
+	}
+	if capacity := pv.Spec.Capacity[corev1.ResourceStorage]; capacity.Value() != 1536<<20 {
+		t.Errorf("unexpected capacity: %s", capacity.String())
+	}
+	if pv.Spec.StorageClassName != "topolvm-provisioner" || pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+		t.Errorf("unexpected storage class: %s, %s", pv.Spec.StorageClassName, pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+	if !isPVOnNode(pv, "node1") || isPVOnNode(pv, "node2") {
+		t.Errorf("unexpected node affinity: %#v", pv.Spec.NodeAffinity)
+	}
+	if pv.Annotations[AnnProvisionedBy] != topolvm.PluginName {
+		t.Errorf("unexpected annotations: %v", pv.Annotations)
+	}
+
+	// The PersistentVolume deleted after it is bound is not recreated.
+	pv.Status.Phase = corev1.VolumeBound
+	if err := c.Status().Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	if err := c.Delete(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	if err := c.Get(ctx, key, pv); err == nil {
+		t.Error("PersistentVolume should not be recreated")
+	}
+	if err := c.Get(ctx, key, lv); err != nil {
+		t.Fatal(err)
+	}
+	if lv.Status.PersistentVolumePhase != topolvmv1.PersistentVolumeOrphaned {
+		t.Errorf("unexpected phase: %s", lv.Status.PersistentVolumePhase)
+	}
+}
@@ -0,0 +1,632 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/driver"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// migrationRetryInterval is the interval to retry a failed copy or verification.
+const migrationRetryInterval = 30 * time.Second
+
+// VolumeMigrationReconciler reconciles a VolumeMigration object.
+//
+// topolvm-node on the source node takes snapshots of the volume and removes
+// the volume after the migration.  topolvm-node on the target node pulls the
+// snapshots from lvmd on the source node and verifies them.
+// The volume is switched by VolumeMigrationSwitchReconciler in topolvm-controller.
+type VolumeMigrationReconciler struct {
+	client.Client
+	nodeName      string
+	streamAddress string
+	lvService     proto.LVServiceClient
+	streamService proto.LVStreamServiceClient
+	dialSource    func(ctx context.Context, address string) (*grpc.ClientConn, error)
+
+	mu     sync.Mutex
+	jobs   map[types.UID]*migrationJob
+	events chan event.GenericEvent
+}
+
+// migrationJob is a copy or verification running in background.
+type migrationJob struct {
+	phase  topolvmv1.VolumeMigrationPhase
+	cancel context.CancelFunc
+}
+
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=volumemigrations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=volumemigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
+
+// NewVolumeMigrationReconciler returns VolumeMigrationReconciler.
+// streamAddress is the address of LVStreamService of lvmd on this node for other nodes.
+// If it is empty, volumes cannot be migrated from this node.
+// streamCreds is the credentials to connect to LVStreamService of lvmd on other nodes.
+// If it is nil, volumes cannot be migrated to this node.
+func NewVolumeMigrationReconciler(client client.Client, nodeName, streamAddress string, conn *grpc.ClientConn, streamCreds credentials.TransportCredentials) *VolumeMigrationReconciler {
+	return &VolumeMigrationReconciler{
+		Client:        client,
+		nodeName:      nodeName,
+		streamAddress: streamAddress,
+		lvService:     proto.NewLVServiceClient(conn),
+		streamService: proto.NewLVStreamServiceClient(conn),
+		dialSource: func(ctx context.Context, address string) (*grpc.ClientConn, error) {
+			if streamCreds == nil {
+				return nil, errors.New("TLS of lvmd stream is not configured")
+			}
+			return grpc.DialContext(ctx, address, grpc.WithTransportCredentials(streamCreds))
+		},
+		jobs:   make(map[types.UID]*migrationJob),
+		events: make(chan event.GenericEvent),
+	}
+}
+
+// Reconcile advances the migration of a volume from or to this node.
+func (r *VolumeMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	vm := new(topolvmv1.VolumeMigration)
+	if err := r.Get(ctx, req.NamespacedName, vm); err != nil {
+		if !apierrs.IsNotFound(err) {
+			log.Error(err, "unable to fetch VolumeMigration")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	lv := new(topolvmv1.LogicalVolume)
+	err := r.Get(ctx, types.NamespacedName{Name: vm.Spec.LogicalVolumeName}, lv)
+	switch {
+	case err == nil:
+	case apierrs.IsNotFound(err):
+		lv = nil
+	default:
+		log.Error(err, "unable to fetch LogicalVolume", "name", vm.Spec.LogicalVolumeName)
+		return ctrl.Result{}, err
+	}
+
+	if vm.Spec.TargetNode == r.nodeName {
+		return r.reconcileTarget(ctx, log, vm, lv)
+	}
+
+	sourceNode := vm.Status.SourceNode
+	if sourceNode == "" && lv != nil {
+		sourceNode = lv.Spec.NodeName
+	}
+	if sourceNode == r.nodeName {
+		return r.reconcileSource(ctx, log, vm, lv)
+	}
+	return ctrl.Result{}, nil
+}
+
+func isCancelRequested(vm *topolvmv1.VolumeMigration) bool {
+	if vm.Spec.Cancel || vm.DeletionTimestamp != nil {
+		return !vm.Status.Phase.IsFinished() && vm.Status.Phase != topolvmv1.VolumeMigrationSwitching
+	}
+	return false
+}
+
+func (r *VolumeMigrationReconciler) reconcileSource(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	if isCancelRequested(vm) {
+		return ctrl.Result{}, r.finish(ctx, vm, topolvmv1.VolumeMigrationCancelled, "canceled")
+	}
+	if vm.Status.Phase.IsFinished() {
+		return ctrl.Result{}, r.cleanupSource(ctx, log, vm, lv)
+	}
+
+	if !containsString(vm.Finalizers, topolvm.MigrationSourceFinalizer) {
+		vm2 := vm.DeepCopy()
+		vm2.Finalizers = append(vm2.Finalizers, topolvm.MigrationSourceFinalizer)
+		patch := client.MergeFrom(vm)
+		if err := r.Patch(ctx, vm2, patch); err != nil {
+			log.Error(err, "failed to add finalizer", "name", vm.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	switch vm.Status.Phase {
+	case topolvmv1.VolumeMigrationPending:
+		return ctrl.Result{}, r.startMigration(ctx, log, vm, lv)
+	case topolvmv1.VolumeMigrationWaitingForDetach:
+		return r.takeFinalSnapshot(ctx, log, vm, lv)
+	}
+	return ctrl.Result{}, nil
+}
+
+func migrationSnapshotName(vm *topolvmv1.VolumeMigration, suffix string) string {
+	return fmt.Sprintf("migration-%s-%s", vm.UID, suffix)
+}
+
+// startMigration takes the base snapshot of the volume and starts Copying phase.
+func (r *VolumeMigrationReconciler) startMigration(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) error {
+	if lv.Status.VolumeID == "" {
+		return r.fail(ctx, vm, "LogicalVolume %s has no volume yet", lv.Name)
+	}
+	if r.streamAddress == "" {
+		return r.fail(ctx, vm, "lvmd stream address is not configured on node %s", r.nodeName)
+	}
+
+	vmList := new(topolvmv1.VolumeMigrationList)
+	if err := r.List(ctx, vmList); err != nil {
+		log.Error(err, "failed to list VolumeMigration")
+		return err
+	}
+	for _, other := range vmList.Items {
+		if other.UID == vm.UID || other.Spec.LogicalVolumeName != vm.Spec.LogicalVolumeName {
+			continue
+		}
+		if other.Status.Phase != topolvmv1.VolumeMigrationPending && !other.Status.Phase.IsFinished() {
+			return r.fail(ctx, vm, "LogicalVolume %s is being migrated by %s", lv.Name, other.Name)
+		}
+	}
+
+	if err := r.registerStreamAddress(ctx); err != nil {
+		log.Error(err, "failed to register lvmd stream address", "node", r.nodeName)
+		return err
+	}
+
+	snapshot := migrationSnapshotName(vm, "base")
+	_, err := r.lvService.SnapshotLV(ctx, &proto.SnapshotLVRequest{
+		Name:         lv.LVName(),
+		DeviceClass:  lv.Spec.DeviceClass,
+		SnapshotName: snapshot,
+	})
+	if err != nil {
+		log.Error(err, "failed to take snapshot", "name", vm.Name, "snapshot", snapshot)
+		return err
+	}
+
+	size := lv.Spec.Size
+	if lv.Status.CurrentSize != nil {
+		size = *lv.Status.CurrentSize
+	}
+	err = r.updateStatus(ctx, vm.Name, topolvmv1.VolumeMigrationPending, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationCopying
+		st.Message = ""
+		st.SourceNode = r.nodeName
+		st.SourceAddress = r.streamAddress
+		st.VolumeID = lv.Status.VolumeID
+		st.SourceLVName = lv.LVName()
+		st.DeviceClass = lv.Spec.DeviceClass
+		st.BaseSnapshot = snapshot
+		st.TotalBytes = size.Value()
+	})
+	if err != nil {
+		log.Error(err, "failed to update status", "name", vm.Name)
+		return err
+	}
+	log.Info("started migration", "name", vm.Name, "volumeID", lv.Status.VolumeID, "target", vm.Spec.TargetNode)
+	return nil
+}
+
+// registerStreamAddress registers the address of LVStreamService of lvmd on this node
+// in the annotation of the Node.  The target node pulls volumes only from the registered address.
+func (r *VolumeMigrationReconciler) registerStreamAddress(ctx context.Context) error {
+	node := new(corev1.Node)
+	if err := r.Get(ctx, types.NamespacedName{Name: r.nodeName}, node); err != nil {
+		return err
+	}
+	if node.Annotations[topolvm.LVMdStreamAddressKey] == r.streamAddress {
+		return nil
+	}
+	node2 := node.DeepCopy()
+	if node2.Annotations == nil {
+		node2.Annotations = make(map[string]string)
+	}
+	node2.Annotations[topolvm.LVMdStreamAddressKey] = r.streamAddress
+	return r.Patch(ctx, node2, client.MergeFrom(node))
+}
+
+// sourceAddress returns the address of LVStreamService registered by the source node.
+// status.sourceAddress is not used because it can be set to any address.
+func (r *VolumeMigrationReconciler) sourceAddress(ctx context.Context, vm *topolvmv1.VolumeMigration) (string, error) {
+	node := new(corev1.Node)
+	if err := r.Get(ctx, types.NamespacedName{Name: vm.Status.SourceNode}, node); err != nil {
+		return "", err
+	}
+	addr := node.Annotations[topolvm.LVMdStreamAddressKey]
+	if addr == "" {
+		return "", fmt.Errorf("node %s has no lvmd stream address", vm.Status.SourceNode)
+	}
+	return addr, nil
+}
+
+// takeFinalSnapshot waits for the volume to be unstaged and takes the final snapshot.
+func (r *VolumeMigrationReconciler) takeFinalSnapshot(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	if lv == nil {
+		return ctrl.Result{}, r.fail(ctx, vm, "LogicalVolume %s is not found", vm.Spec.LogicalVolumeName)
+	}
+
+	// Prevent the volume from being staged again before checking it.
+	if lv.Annotations[topolvm.MigratingKey] != vm.Name {
+		lv2 := lv.DeepCopy()
+		if lv2.Annotations == nil {
+			lv2.Annotations = make(map[string]string)
+		}
+		lv2.Annotations[topolvm.MigratingKey] = vm.Name
+		patch := client.MergeFrom(lv)
+		if err := r.Patch(ctx, lv2, patch); err != nil {
+			log.Error(err, "failed to add annotation", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The device file exists while the volume is staged.
+	_, err := os.Stat(filepath.Join(driver.DeviceDirectory, vm.Status.VolumeID))
+	if err == nil {
+		msg := "waiting for the volume to be unstaged"
+		if vm.Status.Message != msg {
+			err := r.updateStatus(ctx, vm.Name, vm.Status.Phase, func(st *topolvmv1.VolumeMigrationStatus) {
+				st.Message = msg
+			})
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if !os.IsNotExist(err) {
+		return ctrl.Result{}, err
+	}
+
+	snapshot := migrationSnapshotName(vm, "final")
+	_, err = r.lvService.SnapshotLV(ctx, &proto.SnapshotLVRequest{
+		Name:         sourceLVName(vm),
+		DeviceClass:  vm.Status.DeviceClass,
+		SnapshotName: snapshot,
+	})
+	if err != nil {
+		log.Error(err, "failed to take snapshot", "name", vm.Name, "snapshot", snapshot)
+		return ctrl.Result{}, err
+	}
+
+	err = r.updateStatus(ctx, vm.Name, topolvmv1.VolumeMigrationWaitingForDetach, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationSyncing
+		st.Message = ""
+		st.FinalSnapshot = snapshot
+		st.CopiedBytes = 0
+	})
+	if err != nil {
+		log.Error(err, "failed to update status", "name", vm.Name)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// sourceLVName returns the name of the logical volume on the source node.
+// Migrations started by older versions do not record it, and then it is the volume ID.
+func sourceLVName(vm *topolvmv1.VolumeMigration) string {
+	if vm.Status.SourceLVName != "" {
+		return vm.Status.SourceLVName
+	}
+	return vm.Status.VolumeID
+}
+
+// cleanupSource removes the snapshots, and the volume if it has been migrated.
+func (r *VolumeMigrationReconciler) cleanupSource(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) error {
+	if !containsString(vm.Finalizers, topolvm.MigrationSourceFinalizer) {
+		return nil
+	}
+
+	names := []string{vm.Status.BaseSnapshot, vm.Status.FinalSnapshot}
+	if vm.Status.Phase == topolvmv1.VolumeMigrationSucceeded && (lv == nil || lv.Spec.NodeName != r.nodeName) {
+		names = append(names, sourceLVName(vm))
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: name, DeviceClass: vm.Status.DeviceClass})
+		if err != nil {
+			log.Error(err, "failed to remove LV", "name", vm.Name, "lvName", name)
+			return err
+		}
+	}
+
+	if lv != nil && lv.Annotations[topolvm.MigratingKey] == vm.Name {
+		lv2 := lv.DeepCopy()
+		delete(lv2.Annotations, topolvm.MigratingKey)
+		patch := client.MergeFrom(lv)
+		if err := r.Patch(ctx, lv2, patch); err != nil {
+			log.Error(err, "failed to remove annotation", "name", lv.Name)
+			return err
+		}
+	}
+
+	vm2 := vm.DeepCopy()
+	vm2.Finalizers = removeString(vm2.Finalizers, topolvm.MigrationSourceFinalizer)
+	patch := client.MergeFrom(vm)
+	if err := r.Patch(ctx, vm2, patch); err != nil {
+		log.Error(err, "failed to remove finalizer", "name", vm.Name)
+		return err
+	}
+	log.Info("cleaned up the source of migration", "name", vm.Name, "phase", vm.Status.Phase)
+	return nil
+}
+
+func (r *VolumeMigrationReconciler) reconcileTarget(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	if isCancelRequested(vm) {
+		r.stopJob(vm.UID)
+		return ctrl.Result{}, r.finish(ctx, vm, topolvmv1.VolumeMigrationCancelled, "canceled")
+	}
+	if vm.Status.Phase.IsFinished() {
+		r.stopJob(vm.UID)
+		return ctrl.Result{}, r.cleanupTarget(ctx, log, vm, lv)
+	}
+
+	if lv == nil {
+		return ctrl.Result{}, r.fail(ctx, vm, "LogicalVolume %s is not found", vm.Spec.LogicalVolumeName)
+	}
+	if vm.Status.Phase == topolvmv1.VolumeMigrationPending {
+		if lv.Spec.NodeName == r.nodeName {
+			return ctrl.Result{}, r.fail(ctx, vm, "LogicalVolume %s is already on node %s", lv.Name, r.nodeName)
+		}
+		// wait for the source node to start the migration
+		return ctrl.Result{}, nil
+	}
+
+	if !containsString(vm.Finalizers, topolvm.MigrationTargetFinalizer) {
+		vm2 := vm.DeepCopy()
+		vm2.Finalizers = append(vm2.Finalizers, topolvm.MigrationTargetFinalizer)
+		patch := client.MergeFrom(vm)
+		if err := r.Patch(ctx, vm2, patch); err != nil {
+			log.Error(err, "failed to add finalizer", "name", vm.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	switch vm.Status.Phase {
+	case topolvmv1.VolumeMigrationCopying, topolvmv1.VolumeMigrationSyncing, topolvmv1.VolumeMigrationVerifying:
+		r.startJob(vm)
+	}
+	// Switching is processed by topolvm-controller.
+	return ctrl.Result{}, nil
+}
+
+// startJob starts the copy or verification for the current phase in background.
+func (r *VolumeMigrationReconciler) startJob(vm *topolvmv1.VolumeMigration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[vm.UID]; ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.jobs[vm.UID] = &migrationJob{phase: vm.Status.Phase, cancel: cancel}
+	go r.runJob(ctx, vm.DeepCopy())
+}
+
+func (r *VolumeMigrationReconciler) stopJob(uid types.UID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[uid]; ok {
+		job.cancel()
+		delete(r.jobs, uid)
+	}
+}
+
+func (r *VolumeMigrationReconciler) runJob(ctx context.Context, vm *topolvmv1.VolumeMigration) {
+	log := ctrl.Log.WithName("volumemigration").WithValues("name", vm.Name, "phase", vm.Status.Phase)
+	phase := vm.Status.Phase
+
+	var next topolvmv1.VolumeMigrationPhase
+	var err error
+	switch phase {
+	case topolvmv1.VolumeMigrationCopying:
+		next = topolvmv1.VolumeMigrationWaitingForDetach
+		err = r.pull(ctx, log, vm, vm.Status.BaseSnapshot, "")
+	case topolvmv1.VolumeMigrationSyncing:
+		next = topolvmv1.VolumeMigrationVerifying
+		err = r.pull(ctx, log, vm, vm.Status.FinalSnapshot, vm.Status.BaseSnapshot)
+	case topolvmv1.VolumeMigrationVerifying:
+		next = topolvmv1.VolumeMigrationSwitching
+		err = r.verify(ctx, log, vm)
+	}
+	if ctx.Err() != nil {
+		log.Info("stopped migration job")
+		return
+	}
+
+	if err != nil {
+		log.Error(err, "migration job failed")
+		msg := err.Error()
+		err := r.updateStatus(ctx, vm.Name, phase, func(st *topolvmv1.VolumeMigrationStatus) {
+			st.Message = msg
+		})
+		if err != nil {
+			log.Error(err, "failed to update status")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(migrationRetryInterval):
+		}
+		r.stopJob(vm.UID)
+		r.events <- event.GenericEvent{Object: vm}
+		return
+	}
+
+	r.stopJob(vm.UID)
+	err = r.updateStatus(context.Background(), vm.Name, phase, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = next
+		st.Message = ""
+	})
+	if err != nil {
+		log.Error(err, "failed to update status")
+		r.events <- event.GenericEvent{Object: vm}
+		return
+	}
+	log.Info("migration job completed")
+}
+
+// pull copies the source volume to the volume on this node.
+func (r *VolumeMigrationReconciler) pull(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, source, base string) error {
+	addr, err := r.sourceAddress(ctx, vm)
+	if err != nil {
+		return err
+	}
+	stream, err := r.lvService.PullLV(ctx, &proto.PullLVRequest{
+		Name:              vm.Status.VolumeID,
+		DeviceClass:       vm.Status.DeviceClass,
+		SizeGb:            uint64((vm.Status.TotalBytes + (1 << 30) - 1) >> 30),
+		SourceAddress:     addr,
+		SourceName:        source,
+		SourceDeviceClass: vm.Status.DeviceClass,
+		SourceBaseName:    base,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = r.updateStatus(ctx, vm.Name, vm.Status.Phase, func(st *topolvmv1.VolumeMigrationStatus) {
+			st.CopiedBytes = int64(resp.GetCopiedBytes())
+		})
+		if err != nil {
+			// the progress is not important enough to abort the copy
+			log.Error(err, "failed to update progress")
+		}
+	}
+}
+
+// verify compares the checksums of the final snapshot and the volume on this node.
+// If they differ, the migration fails.
+func (r *VolumeMigrationReconciler) verify(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration) error {
+	addr, err := r.sourceAddress(ctx, vm)
+	if err != nil {
+		return err
+	}
+	conn, err := r.dialSource(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	src, err := proto.NewLVStreamServiceClient(conn).ChecksumLV(ctx, &proto.ChecksumLVRequest{
+		Name:        vm.Status.FinalSnapshot,
+		DeviceClass: vm.Status.DeviceClass,
+	})
+	if err != nil {
+		return err
+	}
+	dst, err := r.streamService.ChecksumLV(ctx, &proto.ChecksumLVRequest{
+		Name:        vm.Status.VolumeID,
+		DeviceClass: vm.Status.DeviceClass,
+		Length:      src.GetTotalBytes(),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("verified checksums", "source", src.GetSha256(), "target", dst.GetSha256())
+	err = r.updateStatus(ctx, vm.Name, topolvmv1.VolumeMigrationVerifying, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.SourceChecksum = src.GetSha256()
+		st.TargetChecksum = dst.GetSha256()
+		if st.SourceChecksum != st.TargetChecksum {
+			st.Phase = topolvmv1.VolumeMigrationFailed
+			st.Message = "checksum mismatch"
+		}
+	})
+	return err
+}
+
+// switchVolume recreates the PersistentVolume with the node affinity for this node
+// and moves the LogicalVolume to this node.
+//
+// As the node affinity of PersistentVolume is immutable, the PersistentVolume is
+// deleted and created again with the same name.  The claim stays bound to it because
+// the claim reference is kept.
+// cleanupTarget removes the incomplete volume on this node unless the migration has succeeded.
+func (r *VolumeMigrationReconciler) cleanupTarget(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) error {
+	if !containsString(vm.Finalizers, topolvm.MigrationTargetFinalizer) {
+		return nil
+	}
+
+	if vm.Status.Phase != topolvmv1.VolumeMigrationSucceeded && vm.Status.VolumeID != "" && (lv == nil || lv.Spec.NodeName != r.nodeName) {
+		_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: vm.Status.VolumeID, DeviceClass: vm.Status.DeviceClass})
+		if err != nil {
+			log.Error(err, "failed to remove LV", "name", vm.Name, "lvName", vm.Status.VolumeID)
+			return err
+		}
+	}
+
+	vm2 := vm.DeepCopy()
+	vm2.Finalizers = removeString(vm2.Finalizers, topolvm.MigrationTargetFinalizer)
+	patch := client.MergeFrom(vm)
+	if err := r.Patch(ctx, vm2, patch); err != nil {
+		log.Error(err, "failed to remove finalizer", "name", vm.Name)
+		return err
+	}
+	log.Info("cleaned up the target of migration", "name", vm.Name, "phase", vm.Status.Phase)
+	return nil
+}
+
+// updateStatus updates the status of VolumeMigration with mutate if its phase is still phase.
+func (r *VolumeMigrationReconciler) updateStatus(ctx context.Context, name string, phase topolvmv1.VolumeMigrationPhase, mutate func(*topolvmv1.VolumeMigrationStatus)) error {
+	return updateMigrationStatus(ctx, r.Client, name, phase, mutate)
+}
+
+func (r *VolumeMigrationReconciler) finish(ctx context.Context, vm *topolvmv1.VolumeMigration, phase topolvmv1.VolumeMigrationPhase, message string) error {
+	return r.updateStatus(ctx, vm.Name, vm.Status.Phase, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = phase
+		st.Message = message
+	})
+}
+
+func (r *VolumeMigrationReconciler) fail(ctx context.Context, vm *topolvmv1.VolumeMigration, format string, args ...interface{}) error {
+	return r.finish(ctx, vm, topolvmv1.VolumeMigrationFailed, fmt.Sprintf(format, args...))
+}
+
+// updateMigrationStatus mutates the status of VolumeMigration unless its phase has been changed from phase.
+func updateMigrationStatus(ctx context.Context, c client.Client, name string, phase topolvmv1.VolumeMigrationPhase, mutate func(*topolvmv1.VolumeMigrationStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vm := new(topolvmv1.VolumeMigration)
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, vm); err != nil {
+			return err
+		}
+		if vm.Status.Phase != phase {
+			return nil
+		}
+		mutate(&vm.Status)
+		return c.Status().Update(ctx, vm)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VolumeMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&topolvmv1.VolumeMigration{}).
+		Watches(&source.Channel{Source: r.events}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
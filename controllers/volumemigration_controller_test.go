@@ -0,0 +1,496 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testMigrationUID     = "0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10"
+	testBaseSnapshot     = "migration-" + testMigrationUID + "-base"
+	testFinalSnapshot    = "migration-" + testMigrationUID + "-final"
+	testSourceAddress    = "10.0.0.1:9470"
+	testUntrustedAddress = "192.0.2.1:9470"
+)
+
+type fakeLVService struct {
+	proto.LVServiceClient
+	origins   []string
+	snapshots []string
+	removed   []string
+	pulled    []*proto.PullLVRequest
+}
+
+func (s *fakeLVService) SnapshotLV(_ context.Context, req *proto.SnapshotLVRequest, _ ...grpc.CallOption) (*proto.SnapshotLVResponse, error) {
+	s.origins = append(s.origins, req.GetName())
+	s.snapshots = append(s.snapshots, req.GetSnapshotName())
+	return &proto.SnapshotLVResponse{Snapshot: &proto.LogicalVolume{Name: req.GetSnapshotName()}}, nil
+}
+
+func (s *fakeLVService) RemoveLV(_ context.Context, req *proto.RemoveLVRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	s.removed = append(s.removed, req.GetName())
+	return &proto.Empty{}, nil
+}
+
+func (s *fakeLVService) PullLV(_ context.Context, req *proto.PullLVRequest, _ ...grpc.CallOption) (proto.LVService_PullLVClient, error) {
+	s.pulled = append(s.pulled, req)
+	return &fakePullLVClient{}, nil
+}
+
+type fakePullLVClient struct {
+	grpc.ClientStream
+	done bool
+}
+
+func (c *fakePullLVClient) Recv() (*proto.PullLVResponse, error) {
+	if c.done {
+		return nil, io.EOF
+	}
+	c.done = true
+	return &proto.PullLVResponse{CopiedBytes: 1 << 30, TotalBytes: 1 << 30}, nil
+}
+
+type fakeLVStreamService struct {
+	proto.LVStreamServiceClient
+	sum string
+}
+
+func (s *fakeLVStreamService) ChecksumLV(_ context.Context, req *proto.ChecksumLVRequest, _ ...grpc.CallOption) (*proto.ChecksumLVResponse, error) {
+	return &proto.ChecksumLVResponse{Sha256: s.sum, TotalBytes: req.GetLength()}, nil
+}
+
+type fakeLVStreamServer struct {
+	proto.UnimplementedLVStreamServiceServer
+	sum string
+}
+
+func (s *fakeLVStreamServer) ChecksumLV(_ context.Context, req *proto.ChecksumLVRequest) (*proto.ChecksumLVResponse, error) {
+	return &proto.ChecksumLVResponse{Sha256: s.sum, TotalBytes: 1 << 30}, nil
+}
+
+type migrationTest struct {
+	t         *testing.T
+	client    client.Client
+	r         *VolumeMigrationReconciler
+	lvService *fakeLVService
+	dialed    []string
+}
+
+func newMigrationTest(t *testing.T, nodeName, sourceSum, targetSum string, objs ...client.Object) *migrationTest {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	objs = append(objs,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        "node1",
+			Annotations: map[string]string{topolvm.LVMdStreamAddressKey: testSourceAddress},
+		}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}},
+	)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto.RegisterLVStreamServiceServer(server, &fakeLVStreamServer{sum: sourceSum})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	mt := &migrationTest{t: t, client: c, lvService: &fakeLVService{}}
+	mt.r = NewVolumeMigrationReconciler(c, nodeName, "", nil, nil)
+	if nodeName == "node1" {
+		mt.r.streamAddress = testSourceAddress
+	}
+	mt.r.lvService = mt.lvService
+	mt.r.streamService = &fakeLVStreamService{sum: targetSum}
+	mt.r.dialSource = func(ctx context.Context, address string) (*grpc.ClientConn, error) {
+		mt.dialed = append(mt.dialed, address)
+		return grpc.DialContext(ctx, "bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	}
+	return mt
+}
+
+func (mt *migrationTest) reconcile() ctrl.Result {
+	mt.t.Helper()
+	res, err := mt.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "vm"}})
+	if err != nil {
+		mt.t.Fatal(err)
+	}
+	return res
+}
+
+func (mt *migrationTest) get(name string, obj client.Object) {
+	mt.t.Helper()
+	if err := mt.client.Get(context.Background(), types.NamespacedName{Name: name}, obj); err != nil {
+		mt.t.Fatal(err)
+	}
+}
+
+func (mt *migrationTest) migration() *topolvmv1.VolumeMigration {
+	mt.t.Helper()
+	vm := new(topolvmv1.VolumeMigration)
+	mt.get("vm", vm)
+	return vm
+}
+
+// setStatus updates the status of the VolumeMigration as the other node does.
+func (mt *migrationTest) setStatus(mutate func(*topolvmv1.VolumeMigrationStatus)) {
+	mt.t.Helper()
+	vm := mt.migration()
+	mutate(&vm.Status)
+	if err := mt.client.Status().Update(context.Background(), vm); err != nil {
+		mt.t.Fatal(err)
+	}
+}
+
+func (mt *migrationTest) expectPhase(phase topolvmv1.VolumeMigrationPhase) *topolvmv1.VolumeMigration {
+	mt.t.Helper()
+	vm := mt.migration()
+	if vm.Status.Phase != phase {
+		mt.t.Fatalf("unexpected phase: expected=%q, actual=%q, message=%q", phase, vm.Status.Phase, vm.Status.Message)
+	}
+	return vm
+}
+
+func testMigrationObjects() (*topolvmv1.VolumeMigration, *topolvmv1.LogicalVolume) {
+	vm := &topolvmv1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "vm", UID: testMigrationUID},
+		Spec: topolvmv1.VolumeMigrationSpec{
+			LogicalVolumeName: "pvc-1",
+			TargetNode:        "node2",
+		},
+	}
+	size := resource.MustParse("1Gi")
+	lv := &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			Name:        "pvc-1",
+			NodeName:    "node1",
+			Size:        size,
+			DeviceClass: "ssd",
+		},
+		Status: topolvmv1.LogicalVolumeStatus{
+			VolumeID:    "vol1",
+			CurrentSize: &size,
+		},
+	}
+	return vm, lv
+}
+
+// targetMigration returns VolumeMigration started by the source node.
+func targetMigration(phase topolvmv1.VolumeMigrationPhase) *topolvmv1.VolumeMigration {
+	vm, _ := testMigrationObjects()
+	vm.Finalizers = []string{topolvm.MigrationSourceFinalizer, topolvm.MigrationTargetFinalizer}
+	vm.Status = topolvmv1.VolumeMigrationStatus{
+		Phase:         phase,
+		SourceNode:    "node1",
+		SourceAddress: testUntrustedAddress,
+		VolumeID:      "vol1",
+		DeviceClass:   "ssd",
+		BaseSnapshot:  testBaseSnapshot,
+		FinalSnapshot: testFinalSnapshot,
+		TotalBytes:    1 << 30,
+	}
+	return vm
+}
+
+func TestVolumeMigrationSource(t *testing.T) {
+	vm, lv := testMigrationObjects()
+	mt := newMigrationTest(t, "node1", "", "", vm, lv)
+
+	if res := mt.reconcile(); !res.Requeue {
+		t.Error("should requeue after adding the finalizer")
+	}
+	mt.reconcile()
+	vm = mt.expectPhase(topolvmv1.VolumeMigrationCopying)
+	if vm.Status.SourceNode != "node1" || vm.Status.VolumeID != "vol1" || vm.Status.BaseSnapshot != testBaseSnapshot || vm.Status.TotalBytes != 1<<30 {
+		t.Errorf("unexpected status: %#v", vm.Status)
+	}
+	node := new(corev1.Node)
+	mt.get("node1", node)
+	if node.Annotations[topolvm.LVMdStreamAddressKey] != testSourceAddress {
+		t.Errorf("stream address is not registered: %v", node.Annotations)
+	}
+
+	// The target node has copied the base snapshot.
+	mt.setStatus(func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationWaitingForDetach
+	})
+	mt.reconcile()
+	vm = mt.expectPhase(topolvmv1.VolumeMigrationSyncing)
+	if vm.Status.FinalSnapshot != testFinalSnapshot {
+		t.Errorf("unexpected final snapshot: %s", vm.Status.FinalSnapshot)
+	}
+	mt.get("pvc-1", lv)
+	if lv.Annotations[topolvm.MigratingKey] != "vm" {
+		t.Errorf("LogicalVolume is not marked: %v", lv.Annotations)
+	}
+	if !reflect.DeepEqual(mt.lvService.snapshots, []string{testBaseSnapshot, testFinalSnapshot}) {
+		t.Errorf("unexpected snapshots: %v", mt.lvService.snapshots)
+	}
+
+	// The target node has switched the volume.
+	lv2 := lv.DeepCopy()
+	lv2.Spec.NodeName = "node2"
+	delete(lv2.Annotations, topolvm.MigratingKey)
+	if err := mt.client.Update(context.Background(), lv2); err != nil {
+		t.Fatal(err)
+	}
+	mt.setStatus(func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationSucceeded
+	})
+	mt.reconcile()
+	if !reflect.DeepEqual(mt.lvService.removed, []string{testBaseSnapshot, testFinalSnapshot, "vol1"}) {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+	vm = mt.migration()
+	if containsString(vm.Finalizers, topolvm.MigrationSourceFinalizer) {
+		t.Errorf("source finalizer is not removed: %v", vm.Finalizers)
+	}
+}
+
+func TestVolumeMigrationAdoptedSource(t *testing.T) {
+	vm, lv := testMigrationObjects()
+	lv.Status.LVName = "data"
+	mt := newMigrationTest(t, "node1", "", "", vm, lv)
+
+	mt.reconcile()
+	mt.reconcile()
+	vm = mt.expectPhase(topolvmv1.VolumeMigrationCopying)
+	if vm.Status.VolumeID != "vol1" || vm.Status.SourceLVName != "data" {
+		t.Errorf("unexpected status: %#v", vm.Status)
+	}
+
+	mt.setStatus(func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationWaitingForDetach
+	})
+	mt.reconcile()
+	mt.expectPhase(topolvmv1.VolumeMigrationSyncing)
+	if !reflect.DeepEqual(mt.lvService.origins, []string{"data", "data"}) {
+		t.Errorf("unexpected origins of snapshots: %v", mt.lvService.origins)
+	}
+
+	mt.get("pvc-1", lv)
+	lv.Spec.NodeName = "node2"
+	if err := mt.client.Update(context.Background(), lv); err != nil {
+		t.Fatal(err)
+	}
+	mt.setStatus(func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationSucceeded
+	})
+	mt.reconcile()
+	if !reflect.DeepEqual(mt.lvService.removed, []string{testBaseSnapshot, testFinalSnapshot, "data"}) {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+}
+
+func TestVolumeMigrationSourceCancel(t *testing.T) {
+	_, lv := testMigrationObjects()
+	vm := targetMigration(topolvmv1.VolumeMigrationCopying)
+	vm.Spec.Cancel = true
+	vm.Status.FinalSnapshot = ""
+	mt := newMigrationTest(t, "node1", "", "", vm, lv)
+
+	mt.reconcile()
+	mt.expectPhase(topolvmv1.VolumeMigrationCancelled)
+	mt.reconcile()
+	// the volume is not removed because it has not been migrated
+	if !reflect.DeepEqual(mt.lvService.removed, []string{testBaseSnapshot}) {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+}
+
+func TestVolumeMigrationTarget(t *testing.T) {
+	_, lv := testMigrationObjects()
+	lv.Status.LVName = "data"
+	vm := targetMigration(topolvmv1.VolumeMigrationCopying)
+	vm.Finalizers = []string{topolvm.MigrationSourceFinalizer}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "pvc-1",
+			Finalizers: []string{"kubernetes.io/pv-protection"},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: topolvm.PluginName, VolumeHandle: "vol1"},
+			},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "default", Name: "pvc", UID: "claim-uid"},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			NodeAffinity:                  nodeAffinity("node1"),
+		},
+	}
+	mt := newMigrationTest(t, "node2", "sum", "sum", vm, lv, pv)
+
+	if res := mt.reconcile(); !res.Requeue {
+		t.Error("should requeue after adding the finalizer")
+	}
+	if vm = mt.migration(); !containsString(vm.Finalizers, topolvm.MigrationTargetFinalizer) {
+		t.Fatalf("target finalizer is not added: %v", vm.Finalizers)
+	}
+
+	// Copying pulls the base snapshot from the registered address.
+	mt.r.runJob(context.Background(), vm)
+	mt.expectPhase(topolvmv1.VolumeMigrationWaitingForDetach)
+	if len(mt.lvService.pulled) != 1 {
+		t.Fatalf("unexpected pulls: %v", mt.lvService.pulled)
+	}
+	req := mt.lvService.pulled[0]
+	if req.GetSourceAddress() != testSourceAddress || req.GetSourceName() != testBaseSnapshot || req.GetSourceBaseName() != "" || req.GetName() != "vol1" || req.GetSizeGb() != 1 {
+		t.Errorf("unexpected pull: %v", req)
+	}
+
+	// Syncing pulls the changes of the final snapshot.
+	mt.setStatus(func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationSyncing
+	})
+	mt.r.runJob(context.Background(), mt.migration())
+	mt.expectPhase(topolvmv1.VolumeMigrationVerifying)
+	req = mt.lvService.pulled[1]
+	if req.GetSourceAddress() != testSourceAddress || req.GetSourceName() != testFinalSnapshot || req.GetSourceBaseName() != testBaseSnapshot {
+		t.Errorf("unexpected pull: %v", req)
+	}
+
+	// Verifying compares the checksums.
+	mt.r.runJob(context.Background(), mt.migration())
+	vm = mt.expectPhase(topolvmv1.VolumeMigrationSwitching)
+	if vm.Status.SourceChecksum != "sum" || vm.Status.TargetChecksum != "sum" {
+		t.Errorf("unexpected checksums: %#v", vm.Status)
+	}
+	if !reflect.DeepEqual(mt.dialed, []string{testSourceAddress}) {
+		t.Errorf("unexpected dialed addresses: %v", mt.dialed)
+	}
+
+	// topolvm-node leaves Switching to topolvm-controller.
+	mt.reconcile()
+	mt.expectPhase(topolvmv1.VolumeMigrationSwitching)
+
+	// Switching recreates the PersistentVolume.
+	sr := &VolumeMigrationSwitchReconciler{Client: mt.client}
+	for i := 0; i < 3 && mt.migration().Status.Phase == topolvmv1.VolumeMigrationSwitching; i++ {
+		if _, err := sr.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "vm"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mt.expectPhase(topolvmv1.VolumeMigrationSucceeded)
+	newPV := new(corev1.PersistentVolume)
+	mt.get("pvc-1", newPV)
+	if !isPVOnNode(newPV, "node2") {
+		t.Errorf("PersistentVolume is not on the target node: %#v", newPV.Spec.NodeAffinity)
+	}
+	if !reflect.DeepEqual(newPV.Spec.ClaimRef, pv.Spec.ClaimRef) {
+		t.Errorf("claim reference is not kept: %#v", newPV.Spec.ClaimRef)
+	}
+	if newPV.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+		t.Errorf("reclaim policy is not restored: %s", newPV.Spec.PersistentVolumeReclaimPolicy)
+	}
+	original := new(corev1.PersistentVolume)
+	if err := json.Unmarshal([]byte(mt.migration().Annotations[topolvm.OriginalPVKey]), original); err != nil {
+		t.Fatal(err)
+	}
+	if original.Spec.CSI == nil || original.Spec.CSI.VolumeHandle != "vol1" {
+		t.Errorf("unexpected original PersistentVolume: %#v", original.Spec)
+	}
+	mt.get("pvc-1", lv)
+	if lv.Spec.NodeName != "node2" {
+		t.Errorf("LogicalVolume is not switched: %s", lv.Spec.NodeName)
+	}
+	if lv.LVName() != "vol1" {
+		t.Errorf("the LV on the target node should be named after the volume ID: %s", lv.LVName())
+	}
+
+	// The migrated volume is kept.
+	mt.reconcile()
+	if len(mt.lvService.removed) != 0 {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+	if vm = mt.migration(); containsString(vm.Finalizers, topolvm.MigrationTargetFinalizer) {
+		t.Errorf("target finalizer is not removed: %v", vm.Finalizers)
+	}
+}
+
+func TestVolumeMigrationTargetChecksumMismatch(t *testing.T) {
+	_, lv := testMigrationObjects()
+	vm := targetMigration(topolvmv1.VolumeMigrationVerifying)
+	mt := newMigrationTest(t, "node2", "sum1", "sum2", vm, lv)
+
+	mt.r.runJob(context.Background(), mt.migration())
+	vm = mt.expectPhase(topolvmv1.VolumeMigrationFailed)
+	if vm.Status.Message != "checksum mismatch" {
+		t.Errorf("unexpected message: %s", vm.Status.Message)
+	}
+
+	mt.reconcile()
+	if !reflect.DeepEqual(mt.lvService.removed, []string{"vol1"}) {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+	mt.get("pvc-1", lv)
+	if lv.Spec.NodeName != "node1" {
+		t.Errorf("LogicalVolume should not be switched: %s", lv.Spec.NodeName)
+	}
+}
+
+func TestVolumeMigrationTargetCancel(t *testing.T) {
+	_, lv := testMigrationObjects()
+	vm := targetMigration(topolvmv1.VolumeMigrationCopying)
+	vm.Spec.Cancel = true
+	mt := newMigrationTest(t, "node2", "", "", vm, lv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mt.r.jobs[vm.UID] = &migrationJob{phase: topolvmv1.VolumeMigrationCopying, cancel: cancel}
+
+	mt.reconcile()
+	mt.expectPhase(topolvmv1.VolumeMigrationCancelled)
+	if ctx.Err() == nil {
+		t.Error("the running copy is not stopped")
+	}
+	if len(mt.r.jobs) != 0 {
+		t.Errorf("unexpected jobs: %v", mt.r.jobs)
+	}
+
+	mt.reconcile()
+	if !reflect.DeepEqual(mt.lvService.removed, []string{"vol1"}) {
+		t.Errorf("unexpected removed LVs: %v", mt.lvService.removed)
+	}
+}
+
+func TestVolumeMigrationTargetWithoutRegisteredAddress(t *testing.T) {
+	_, lv := testMigrationObjects()
+	vm := targetMigration(topolvmv1.VolumeMigrationCopying)
+	vm.Status.SourceNode = "node2"
+	mt := newMigrationTest(t, "node2", "", "", vm, lv)
+
+	err := mt.r.pull(context.Background(), ctrl.Log, mt.migration(), testBaseSnapshot, "")
+	if err == nil {
+		t.Error("should not pull from a node without the registered address")
+	}
+	if len(mt.lvService.pulled) != 0 {
+		t.Errorf("unexpected pulls: %v", mt.lvService.pulled)
+	}
+}
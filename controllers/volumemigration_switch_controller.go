@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VolumeMigrationSwitchReconciler switches the volume of VolumeMigration in Switching phase.
+// It runs in topolvm-controller so that topolvm-node does not need to write PersistentVolumes.
+type VolumeMigrationSwitchReconciler struct {
+	client.Client
+}
+
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=volumemigrations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=volumemigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=logicalvolumes,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=logicalvolumes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;create;patch;delete
+
+// Reconcile switches the volume after topolvm-node on the target node has verified it.
+func (r *VolumeMigrationSwitchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	vm := new(topolvmv1.VolumeMigration)
+	if err := r.Get(ctx, req.NamespacedName, vm); err != nil {
+		if !apierrs.IsNotFound(err) {
+			log.Error(err, "unable to fetch VolumeMigration")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if vm.Status.Phase != topolvmv1.VolumeMigrationSwitching {
+		return ctrl.Result{}, nil
+	}
+
+	lv := new(topolvmv1.LogicalVolume)
+	err := r.Get(ctx, types.NamespacedName{Name: vm.Spec.LogicalVolumeName}, lv)
+	switch {
+	case err == nil:
+	case apierrs.IsNotFound(err):
+		return ctrl.Result{}, r.fail(ctx, vm, fmt.Sprintf("LogicalVolume %s is not found", vm.Spec.LogicalVolumeName))
+	default:
+		log.Error(err, "unable to fetch LogicalVolume", "name", vm.Spec.LogicalVolumeName)
+		return ctrl.Result{}, err
+	}
+	return r.switchVolume(ctx, log, vm, lv)
+}
+
+func (r *VolumeMigrationSwitchReconciler) fail(ctx context.Context, vm *topolvmv1.VolumeMigration, message string) error {
+	return updateMigrationStatus(ctx, r.Client, vm.Name, vm.Status.Phase, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationFailed
+		st.Message = message
+	})
+}
+
+// switchVolume recreates the PersistentVolume with the node affinity for the target node,
+// and moves the LogicalVolume to the target node.
+func (r *VolumeMigrationSwitchReconciler) switchVolume(ctx context.Context, log logr.Logger, vm *topolvmv1.VolumeMigration, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	nodeName := vm.Spec.TargetNode
+	pv := new(corev1.PersistentVolume)
+	err := r.Get(ctx, types.NamespacedName{Name: lv.Name}, pv)
+	switch {
+	case err == nil:
+	case apierrs.IsNotFound(err):
+		pv = nil
+	default:
+		log.Error(err, "unable to fetch PersistentVolume", "name", lv.Name)
+		return ctrl.Result{}, err
+	}
+
+	if pv != nil && !isPVOnNode(pv, nodeName) {
+		if pv.DeletionTimestamp != nil {
+			return ctrl.Result{RequeueAfter: time.Second}, nil
+		}
+		if _, ok := vm.Annotations[topolvm.OriginalPVKey]; !ok {
+			data, err := json.Marshal(&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        pv.Name,
+					Labels:      pv.Labels,
+					Annotations: pv.Annotations,
+					Finalizers:  pv.Finalizers,
+				},
+				Spec: pv.Spec,
+			})
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			vm2 := vm.DeepCopy()
+			if vm2.Annotations == nil {
+				vm2.Annotations = make(map[string]string)
+			}
+			vm2.Annotations[topolvm.OriginalPVKey] = string(data)
+			patch := client.MergeFrom(vm)
+			if err := r.Patch(ctx, vm2, patch); err != nil {
+				log.Error(err, "failed to save PersistentVolume", "name", vm.Name)
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Retain the volume and remove finalizers not to delete the volume with the PersistentVolume.
+		pv2 := pv.DeepCopy()
+		pv2.Finalizers = nil
+		pv2.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		patch := client.MergeFrom(pv)
+		if err := r.Patch(ctx, pv2, patch); err != nil {
+			log.Error(err, "failed to patch PersistentVolume", "name", pv.Name)
+			return ctrl.Result{}, err
+		}
+		if err := r.Delete(ctx, pv2, client.Preconditions{UID: &pv.UID}); err != nil && !apierrs.IsNotFound(err) {
+			log.Error(err, "failed to delete PersistentVolume", "name", pv.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("deleted PersistentVolume to recreate", "name", pv.Name)
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	if pv == nil {
+		if data, ok := vm.Annotations[topolvm.OriginalPVKey]; ok {
+			newPV := new(corev1.PersistentVolume)
+			if err := json.Unmarshal([]byte(data), newPV); err != nil {
+				return ctrl.Result{}, r.fail(ctx, vm, fmt.Sprintf("broken annotation %s: %v", topolvm.OriginalPVKey, err))
+			}
+			newPV.Spec.NodeAffinity = nodeAffinity(nodeName)
+			if err := r.Create(ctx, newPV); err != nil {
+				log.Error(err, "failed to create PersistentVolume", "name", newPV.Name)
+				return ctrl.Result{}, err
+			}
+			log.Info("created PersistentVolume on the target node", "name", newPV.Name)
+		}
+	}
+
+	// The logical volume on this node is named after the volume ID even if the source was adopted.
+	if lv.Status.LVName != "" {
+		lv2 := lv.DeepCopy()
+		lv2.Status.LVName = ""
+		if err := r.Status().Update(ctx, lv2); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+		lv = lv2
+	}
+
+	if lv.Spec.NodeName != nodeName || lv.Annotations[topolvm.MigratingKey] != "" {
+		lv2 := lv.DeepCopy()
+		lv2.Spec.NodeName = nodeName
+		delete(lv2.Annotations, topolvm.MigratingKey)
+		patch := client.MergeFrom(lv)
+		if err := r.Patch(ctx, lv2, patch); err != nil {
+			log.Error(err, "failed to switch LogicalVolume", "name", lv.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	err = updateMigrationStatus(ctx, r.Client, vm.Name, topolvmv1.VolumeMigrationSwitching, func(st *topolvmv1.VolumeMigrationStatus) {
+		st.Phase = topolvmv1.VolumeMigrationSucceeded
+		st.Message = ""
+	})
+	if err != nil {
+		log.Error(err, "failed to update status", "name", vm.Name)
+		return ctrl.Result{}, err
+	}
+	log.Info("migrated volume", "name", vm.Name, "logicalVolume", lv.Name, "node", nodeName)
+	return ctrl.Result{}, nil
+}
+
+func nodeAffinity(nodeName string) *corev1.VolumeNodeAffinity {
+	return &corev1.VolumeNodeAffinity{
+		Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      topolvm.TopologyNodeKey,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{nodeName},
+				}},
+			}},
+		},
+	}
+}
+
+// isPVOnNode returns true if the node affinity of pv selects only nodeName.
+func isPVOnNode(pv *corev1.PersistentVolume, nodeName string) bool {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return false
+	}
+	found := false
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, req := range term.MatchExpressions {
+			if req.Key != topolvm.TopologyNodeKey || req.Operator != corev1.NodeSelectorOpIn {
+				continue
+			}
+			if len(req.Values) != 1 || req.Values[0] != nodeName {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VolumeMigrationSwitchReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&topolvmv1.VolumeMigration{}).
+		Complete(r)
+}
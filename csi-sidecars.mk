@@ -1,6 +1,6 @@
 # CSI sidecar versions
-EXTERNAL_PROVISIONER_VERSION = 2.2.2
-EXTERNAL_RESIZER_VERSION = 1.2.0
+EXTERNAL_PROVISIONER_VERSION = 3.0.0
+EXTERNAL_RESIZER_VERSION = 1.3.0
 NODE_DRIVER_REGISTRAR_VERSION = 2.2.0
 LIVENESSPROBE_VERSION = 2.3.0
 CSI_SIDECARS = \
@@ -0,0 +1,27 @@
+package driver
+
+import (
+	"github.com/topolvm/topolvm/csi"
+)
+
+// supportedAccessModes is the set of access modes supported by TopoLVM.
+// All of them are single node access modes because the logical volumes are node local.
+var supportedAccessModes = map[csi.VolumeCapability_AccessMode_Mode]bool{
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER:        true,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY:   true,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER: true,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_MULTI_WRITER:  true,
+}
+
+func isSupportedAccessMode(mode csi.VolumeCapability_AccessMode_Mode) bool {
+	return supportedAccessModes[mode]
+}
+
+func accessModeName(mode csi.VolumeCapability_AccessMode_Mode) string {
+	return csi.VolumeCapability_AccessMode_Mode_name[int32(mode)]
+}
+
+// isReadOnlyAccessMode returns true if the volume must not be written with the access mode.
+func isReadOnlyAccessMode(mode csi.VolumeCapability_AccessMode_Mode) bool {
+	return mode == csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY
+}
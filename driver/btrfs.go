@@ -0,0 +1,45 @@
+package driver
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/topolvm/topolvm"
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	btrfsType = "btrfs"
+	btrfsCmd  = "/bin/btrfs"
+)
+
+// btrfsCompressionPattern matches the algorithms and levels accepted by "compress" mount option of btrfs.
+var btrfsCompressionPattern = regexp.MustCompile(`^(zlib(:[1-9])?|lzo|zstd(:([1-9]|1[0-5]))?|no)$`)
+
+func validateBtrfsCompression(compression string) error {
+	if !btrfsCompressionPattern.MatchString(compression) {
+		return fmt.Errorf("invalid btrfs compression: %s", compression)
+	}
+	return nil
+}
+
+// btrfsMountOptions returns the mount options for the btrfs volume.
+func btrfsMountOptions(volumeContext map[string]string) ([]string, error) {
+	compression := volumeContext[topolvm.BtrfsCompressionKey]
+	if compression == "" {
+		return nil, nil
+	}
+	if err := validateBtrfsCompression(compression); err != nil {
+		return nil, err
+	}
+	return []string{"compress=" + compression}, nil
+}
+
+// resizeBtrfs grows the btrfs filesystem mounted on path to the size of the device.
+func resizeBtrfs(exec utilexec.Interface, path string) error {
+	out, err := exec.Command(btrfsCmd, "filesystem", "resize", "max", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("btrfs filesystem resize failed: path=%s, output=%s, error=%v", path, string(out), err)
+	}
+	return nil
+}
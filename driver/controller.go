@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	"github.com/topolvm/topolvm/csi"
 	"github.com/topolvm/topolvm/driver/k8s"
 	"google.golang.org/grpc/codes"
@@ -60,15 +63,18 @@ func (s controllerService) CreateVolume(ctx context.Context, req *csi.CreateVolu
 				"access_type", "mount",
 				"fs_type", mount.GetFsType(),
 				"flags", mount.GetMountFlags())
+			if err := validateFilesystemParameters(fsTypeOrDefault(mount.GetFsType()), req.GetParameters()); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
 		} else {
 			return nil, status.Error(codes.InvalidArgument, "unknown or empty access_type")
 		}
 
 		if mode := capability.GetAccessMode(); mode != nil {
-			modeName := csi.VolumeCapability_AccessMode_Mode_name[int32(mode.GetMode())]
+			modeName := accessModeName(mode.GetMode())
 			ctrlLogger.Info("CreateVolume specifies volume capability", "access_mode", modeName)
-			// we only support SINGLE_NODE_WRITER
-			if mode.GetMode() != csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER {
+			// we only support single node access modes
+			if !isSupportedAccessMode(mode.GetMode()) {
 				return nil, status.Errorf(codes.InvalidArgument, "unsupported access mode: %s", modeName)
 			}
 		}
@@ -79,6 +85,11 @@ func (s controllerService) CreateVolume(ctx context.Context, req *csi.CreateVolu
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	ioLimits, err := ioLimitsFromParameters(req.GetParameters())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// process topology
 	var node string
 	requirements := req.GetAccessibilityRequirements()
@@ -126,7 +137,7 @@ func (s controllerService) CreateVolume(ctx context.Context, req *csi.CreateVolu
 
 	name = strings.ToLower(name)
 
-	volumeID, err := s.lvService.CreateVolume(ctx, node, deviceClass, name, requestGb)
+	volumeID, err := s.lvService.CreateVolume(ctx, node, deviceClass, name, requestGb, ioLimits)
 	if err != nil {
 		_, ok := status.FromError(err)
 		if !ok {
@@ -139,6 +150,7 @@ func (s controllerService) CreateVolume(ctx context.Context, req *csi.CreateVolu
 		Volume: &csi.Volume{
 			CapacityBytes: requestGb << 30,
 			VolumeId:      volumeID,
+			VolumeContext: volumeContextFromParameters(req.GetParameters()),
 			AccessibleTopology: []*csi.Topology{
 				{
 					Segments: map[string]string{topolvm.TopologyNodeKey: node},
@@ -148,6 +160,44 @@ func (s controllerService) CreateVolume(ctx context.Context, req *csi.CreateVolu
 	}, nil
 }
 
+// volumeContextKeys are the keys of StorageClass parameters passed to the node service through the volume context.
+var volumeContextKeys = []string{
+	topolvm.MkfsOptionsKey,
+	topolvm.BtrfsCompressionKey,
+}
+
+func validateFilesystemParameters(fsType string, params map[string]string) error {
+	if v := params[topolvm.MkfsOptionsKey]; v != "" {
+		if _, err := parseMkfsOptions(fsType, v); err != nil {
+			return err
+		}
+	}
+	if v := params[topolvm.BtrfsCompressionKey]; v != "" {
+		if fsType != btrfsType {
+			return fmt.Errorf("%s is only for btrfs: fstype=%s", topolvm.BtrfsCompressionKey, fsType)
+		}
+		if err := validateBtrfsCompression(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func volumeContextFromParameters(params map[string]string) map[string]string {
+	var volumeContext map[string]string
+	for _, key := range volumeContextKeys {
+		v := params[key]
+		if v == "" {
+			continue
+		}
+		if volumeContext == nil {
+			volumeContext = make(map[string]string)
+		}
+		volumeContext[key] = v
+	}
+	return volumeContext
+}
+
 func convertRequestCapacity(requestBytes, limitBytes int64) (int64, error) {
 	if requestBytes < 0 {
 		return 0, errors.New("required capacity must not be negative")
@@ -212,8 +262,8 @@ func (s controllerService) ValidateVolumeCapabilities(ctx context.Context, req *
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Since TopoLVM does not provide means to pre-provision volumes,
-	// any existing volume is valid.
+	// Pre-provisioned volumes are adopted through LogicalVolume with spec.existingLVName,
+	// so any existing volume is valid.
 	return &csi.ValidateVolumeCapabilitiesResponse{
 		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
 			VolumeContext:      req.GetVolumeContext(),
@@ -271,11 +321,128 @@ func (s controllerService) GetCapacity(ctx context.Context, req *csi.GetCapacity
 	}, nil
 }
 
+// csiVolume converts LogicalVolume to csi.Volume.
+func csiVolume(lv *topolvmv1.LogicalVolume) *csi.Volume {
+	size := lv.Spec.Size
+	if lv.Status.CurrentSize != nil {
+		size = *lv.Status.CurrentSize
+	}
+	return &csi.Volume{
+		CapacityBytes: size.Value(),
+		VolumeId:      lv.Status.VolumeID,
+		AccessibleTopology: []*csi.Topology{
+			{
+				Segments: map[string]string{topolvm.TopologyNodeKey: lv.Spec.NodeName},
+			},
+		},
+	}
+}
+
+func (s controllerService) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	ctrlLogger.Info("ListVolumes called",
+		"max_entries", req.GetMaxEntries(),
+		"starting_token", req.GetStartingToken())
+
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_entries must not be negative")
+	}
+
+	lvs, err := s.lvService.ListVolumes(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// LogicalVolume without volume ID is not provisioned yet.
+	provisioned := make([]*topolvmv1.LogicalVolume, 0, len(lvs))
+	for i := range lvs {
+		if lvs[i].Status.VolumeID != "" {
+			provisioned = append(provisioned, &lvs[i])
+		}
+	}
+	sort.Slice(provisioned, func(i, j int) bool {
+		return provisioned[i].Status.VolumeID < provisioned[j].Status.VolumeID
+	})
+
+	start, end, nextToken, err := pageRange(len(provisioned), int(req.GetMaxEntries()), req.GetStartingToken())
+	if err != nil {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+
+	entries := make([]*csi.ListVolumesResponse_Entry, 0, end-start)
+	for _, lv := range provisioned[start:end] {
+		cond, err := s.volumeCondition(ctx, lv)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: csiVolume(lv),
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: []string{lv.Spec.NodeName},
+				VolumeCondition:  cond,
+			},
+		})
+	}
+	return &csi.ListVolumesResponse{Entries: entries, NextToken: nextToken}, nil
+}
+
+// pageRange returns the range of the page starting from startingToken in total entries.
+// The token is the index of the first entry of the page.
+// nextToken is empty if the page is the last one.
+func pageRange(total, maxEntries int, startingToken string) (start, end int, nextToken string, err error) {
+	if startingToken != "" {
+		start, err = strconv.Atoi(startingToken)
+		if err != nil || start < 0 || start > total {
+			return 0, 0, "", fmt.Errorf("invalid starting_token: %s", startingToken)
+		}
+	}
+
+	end = total
+	if maxEntries > 0 && start+maxEntries < total {
+		end = start + maxEntries
+		nextToken = strconv.Itoa(end)
+	}
+	return start, end, nextToken, nil
+}
+
+func (s controllerService) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	volumeID := req.GetVolumeId()
+	ctrlLogger.Info("ControllerGetVolume called", "volume_id", volumeID)
+
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "volume_id is not provided")
+	}
+
+	lv, err := s.lvService.GetVolume(ctx, volumeID)
+	if err != nil {
+		if err == k8s.ErrVolumeNotFound {
+			return nil, status.Errorf(codes.NotFound, "LogicalVolume for volume id %s is not found", volumeID)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	cond, err := s.volumeCondition(ctx, lv)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerGetVolumeResponse{
+		Volume: csiVolume(lv),
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: []string{lv.Spec.NodeName},
+			VolumeCondition:  cond,
+		},
+	}, nil
+}
+
 func (s controllerService) ControllerGetCapabilities(context.Context, *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
 	capabilities := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_GET_CAPACITY,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+		csi.ControllerServiceCapability_RPC_SINGLE_NODE_MULTI_WRITER,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 	}
 
 	csiCaps := make([]*csi.ControllerServiceCapability, len(capabilities))
@@ -1,7 +1,10 @@
 package driver
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/topolvm/topolvm"
 )
 
 func TestController(t *testing.T) {
@@ -52,3 +55,92 @@ func TestController(t *testing.T) {
 		t.Errorf("should be 2: %d", v)
 	}
 }
+
+func TestValidateFilesystemParameters(t *testing.T) {
+	cases := []struct {
+		fsType string
+		params map[string]string
+		valid  bool
+	}{
+		{"ext4", map[string]string{}, true},
+		{"ext4", map[string]string{topolvm.MkfsOptionsKey: "-m 0"}, true},
+		{"xfs", map[string]string{topolvm.MkfsOptionsKey: "-m 0"}, true},
+		{"xfs", map[string]string{topolvm.MkfsOptionsKey: "-E nodiscard"}, false},
+		{"btrfs", map[string]string{topolvm.BtrfsCompressionKey: "zstd"}, true},
+		{"btrfs", map[string]string{topolvm.BtrfsCompressionKey: "zstd:3"}, true},
+		{"btrfs", map[string]string{topolvm.BtrfsCompressionKey: "lzo"}, true},
+		{"btrfs", map[string]string{topolvm.BtrfsCompressionKey: "zstd:16"}, false},
+		{"btrfs", map[string]string{topolvm.BtrfsCompressionKey: "gzip"}, false},
+		{"xfs", map[string]string{topolvm.BtrfsCompressionKey: "zstd"}, false},
+	}
+
+	for _, c := range cases {
+		err := validateFilesystemParameters(c.fsType, c.params)
+		if c.valid && err != nil {
+			t.Errorf("%s %v: unexpected error: %v", c.fsType, c.params, err)
+		} else if !c.valid && err == nil {
+			t.Errorf("%s %v: should be invalid", c.fsType, c.params)
+		}
+	}
+}
+
+func TestVolumeContextFromParameters(t *testing.T) {
+	if vc := volumeContextFromParameters(map[string]string{topolvm.DeviceClassKey: "ssd"}); vc != nil {
+		t.Errorf("should be nil: %v", vc)
+	}
+
+	vc := volumeContextFromParameters(map[string]string{
+		topolvm.DeviceClassKey:      "ssd",
+		topolvm.MkfsOptionsKey:      "-L data",
+		topolvm.BtrfsCompressionKey: "zstd",
+	})
+	expected := map[string]string{
+		topolvm.MkfsOptionsKey:      "-L data",
+		topolvm.BtrfsCompressionKey: "zstd",
+	}
+	if !reflect.DeepEqual(vc, expected) {
+		t.Errorf("expected %v, actual %v", expected, vc)
+	}
+}
+
+func TestPageRange(t *testing.T) {
+	cases := []struct {
+		total      int
+		maxEntries int
+		token      string
+		start      int
+		end        int
+		nextToken  string
+		valid      bool
+	}{
+		{0, 0, "", 0, 0, "", true},
+		{5, 0, "", 0, 5, "", true},
+		{5, 2, "", 0, 2, "2", true},
+		{5, 2, "2", 2, 4, "4", true},
+		{5, 2, "4", 4, 5, "", true},
+		{5, 5, "", 0, 5, "", true},
+		{5, 10, "3", 3, 5, "", true},
+		// volumes may be deleted between pages
+		{3, 2, "4", 0, 0, "", false},
+		{3, 2, "3", 3, 3, "", true},
+		{5, 2, "invalid-token", 0, 0, "", false},
+		{5, 2, "-1", 0, 0, "", false},
+	}
+
+	for _, c := range cases {
+		start, end, nextToken, err := pageRange(c.total, c.maxEntries, c.token)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("%+v: should be error", c)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", c, err)
+			continue
+		}
+		if start != c.start || end != c.end || nextToken != c.nextToken {
+			t.Errorf("%+v: actual start=%d end=%d nextToken=%q", c, start, end, nextToken)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/cgroup"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// podUIDKey is the key of volume context that holds the UID of the pod.
+// It is given when podInfoOnMount of CSIDriver is true.
+const podUIDKey = "csi.storage.k8s.io/pod.uid"
+
+// ioLimitsFromParameters returns the IO limits specified by StorageClass parameters.
+// It returns nil if no limit is specified.
+func ioLimitsFromParameters(params map[string]string) (*topolvmv1.IOLimits, error) {
+	limits := &topolvmv1.IOLimits{}
+	for key, field := range map[string]*int64{
+		topolvm.ReadBPSKey:   &limits.ReadBPS,
+		topolvm.WriteBPSKey:  &limits.WriteBPS,
+		topolvm.ReadIOPSKey:  &limits.ReadIOPS,
+		topolvm.WriteIOPSKey: &limits.WriteIOPS,
+	} {
+		v, ok := params[key]
+		if !ok {
+			continue
+		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s: %v", key, v, err)
+		}
+		if q.Sign() < 0 {
+			return nil, fmt.Errorf("%s must not be negative: %s", key, v)
+		}
+		*field = q.Value()
+	}
+	if limits.IsZero() {
+		return nil, nil
+	}
+	return limits, nil
+}
+
+// ApplyIOLimits sets the IO limits of the device to the cgroup of the pod.
+// If limits is nil, the limits of the device are removed.
+// It returns true if the cgroup is updated.
+func ApplyIOLimits(cgroupRoot, podUID string, major, minor uint32, limits *topolvmv1.IOLimits) (bool, error) {
+	updated, err := applyIOLimits(cgroupRoot, podUID, major, minor, limits)
+	// If the cgroup or its io.max does not exist, there is no limit to be removed.
+	if limits.IsZero() && errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return updated, err
+}
+
+func applyIOLimits(cgroupRoot, podUID string, major, minor uint32, limits *topolvmv1.IOLimits) (bool, error) {
+	cgroupPath, err := cgroup.FindPodCgroup(cgroupRoot, podUID)
+	if err != nil {
+		return false, err
+	}
+
+	var m cgroup.IOMax
+	if limits != nil {
+		m = cgroup.IOMax{
+			ReadBPS:   uint64(limits.ReadBPS),
+			WriteBPS:  uint64(limits.WriteBPS),
+			ReadIOPS:  uint64(limits.ReadIOPS),
+			WriteIOPS: uint64(limits.WriteIOPS),
+		}
+	}
+	return cgroup.SetIOMax(cgroupPath, major, minor, m)
+}
@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+)
+
+func TestIOLimitsFromParameters(t *testing.T) {
+	limits, err := ioLimitsFromParameters(map[string]string{topolvm.DeviceClassKey: "ssd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits != nil {
+		t.Errorf("should be nil: %+v", limits)
+	}
+
+	limits, err = ioLimitsFromParameters(map[string]string{
+		topolvm.ReadBPSKey:   "100Mi",
+		topolvm.WriteBPSKey:  "50Mi",
+		topolvm.ReadIOPSKey:  "1k",
+		topolvm.WriteIOPSKey: "500",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := topolvmv1.IOLimits{
+		ReadBPS:   100 << 20,
+		WriteBPS:  50 << 20,
+		ReadIOPS:  1000,
+		WriteIOPS: 500,
+	}
+	if limits == nil || *limits != expected {
+		t.Errorf("expected %+v, actual %+v", expected, limits)
+	}
+
+	_, err = ioLimitsFromParameters(map[string]string{topolvm.ReadBPSKey: "fast"})
+	if err == nil {
+		t.Error("should be error")
+	}
+
+	_, err = ioLimitsFromParameters(map[string]string{topolvm.WriteIOPSKey: "-1"})
+	if err == nil {
+		t.Error("should be error")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -54,7 +55,7 @@ func NewLogicalVolumeService(mgr manager.Manager) (*LogicalVolumeService, error)
 }
 
 // CreateVolume creates volume
-func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, name string, requestGb int64) (string, error) {
+func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, name string, requestGb int64, ioLimits *topolvmv1.IOLimits) (string, error) {
 	logger.Info("k8s.CreateVolume called", "name", name, "node", node, "size_gb", requestGb)
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -72,6 +73,7 @@ func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, name
 			NodeName:    node,
 			DeviceClass: dc,
 			Size:        *resource.NewQuantity(requestGb<<30, resource.BinarySI),
+			IOLimits:    ioLimits,
 		},
 	}
 
@@ -115,13 +117,22 @@ func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, name
 			logger.Info("end k8s.LogicalVolume", "volume_id", newLV.Status.VolumeID)
 			return newLV.Status.VolumeID, nil
 		}
-		if newLV.Status.Code != codes.OK {
+		var failure error
+		created := meta.FindStatusCondition(newLV.Status.Conditions, topolvmv1.LogicalVolumeCreated)
+		switch {
+		case created != nil && created.Status == metav1.ConditionFalse:
+			failure = conditionError(created)
+		case created == nil && newLV.Status.Code != codes.OK:
+			// topolvm-node older than the conditions sets only the deprecated code.
+			failure = status.Error(newLV.Status.Code, newLV.Status.Message)
+		}
+		if failure != nil {
 			err := s.Delete(ctx, &newLV)
 			if err != nil {
-				// log this error but do not return this error, because newLV.Status.Message is more important
+				// log this error but do not return this error, because the failure is more important
 				logger.Error(err, "failed to delete LogicalVolume")
 			}
-			return "", status.Error(newLV.Status.Code, newLV.Status.Message)
+			return "", failure
 		}
 	}
 }
@@ -178,14 +189,14 @@ func (s *LogicalVolumeService) ExpandVolume(ctx context.Context, volumeID string
 		return err
 	}
 
-	err = s.UpdateSpecSize(ctx, volumeID, resource.NewQuantity(requestGb<<30, resource.BinarySI))
+	generation, err := s.UpdateSpecSize(ctx, volumeID, resource.NewQuantity(requestGb<<30, resource.BinarySI))
 	if err != nil {
 		return err
 	}
 
 	// wait until topolvm-node expands the target volume
 	for {
-		logger.Info("waiting for update of 'status.currentSize'", "name", lv.Name)
+		logger.Info("waiting for Resizing condition", "name", lv.Name, "generation", generation)
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -201,19 +212,35 @@ func (s *LogicalVolumeService) ExpandVolume(ctx context.Context, volumeID string
 		if changedLV.Status.CurrentSize == nil {
 			return errors.New("status.currentSize should not be nil")
 		}
-		if changedLV.Status.CurrentSize.Value() != changedLV.Spec.Size.Value() {
-			logger.Info("failed to match current size and requested size", "current", changedLV.Status.CurrentSize.Value(), "requested", changedLV.Spec.Size.Value())
+		resizing := meta.FindStatusCondition(changedLV.Status.Conditions, topolvmv1.LogicalVolumeResizing)
+		if resizing == nil {
+			// topolvm-node older than the conditions sets only status.currentSize and the deprecated code.
+			if changedLV.Status.CurrentSize.Value() != changedLV.Spec.Size.Value() {
+				continue
+			}
+			if changedLV.Status.Code != codes.OK {
+				return status.Error(changedLV.Status.Code, changedLV.Status.Message)
+			}
+			return nil
+		}
+		if resizing.ObservedGeneration < generation {
 			continue
 		}
-
-		if changedLV.Status.Code != codes.OK {
-			return status.Error(changedLV.Status.Code, changedLV.Status.Message)
+		if resizing.Status == metav1.ConditionFalse {
+			return nil
+		}
+		if resizing.Reason != topolvmv1.ReasonResizing {
+			return conditionError(resizing)
 		}
-
-		return nil
 	}
 }
 
+// conditionError returns the error of the failure recorded in the condition.
+// The reason of the condition is the name of the gRPC code.
+func conditionError(cond *metav1.Condition) error {
+	return status.Error(topolvmv1.ReasonCode(cond.Reason), cond.Message)
+}
+
 // GetVolume returns LogicalVolume by volume ID.
 func (s *LogicalVolumeService) GetVolume(ctx context.Context, volumeID string) (*topolvmv1.LogicalVolume, error) {
 	lvList := new(topolvmv1.LogicalVolumeList)
@@ -230,18 +257,29 @@ func (s *LogicalVolumeService) GetVolume(ctx context.Context, volumeID string) (
 	return &lvList.Items[0], nil
 }
 
+// ListVolumes returns all LogicalVolumes.
+func (s *LogicalVolumeService) ListVolumes(ctx context.Context) ([]topolvmv1.LogicalVolume, error) {
+	lvList := new(topolvmv1.LogicalVolumeList)
+	err := s.List(ctx, lvList)
+	if err != nil {
+		return nil, err
+	}
+	return lvList.Items, nil
+}
+
 // UpdateSpecSize updates .Spec.Size of LogicalVolume.
-func (s *LogicalVolumeService) UpdateSpecSize(ctx context.Context, volumeID string, size *resource.Quantity) error {
+// It returns the generation of the updated LogicalVolume.
+func (s *LogicalVolumeService) UpdateSpecSize(ctx context.Context, volumeID string, size *resource.Quantity) (int64, error) {
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return 0, ctx.Err()
 		case <-time.After(1 * time.Second):
 		}
 
 		lv, err := s.GetVolume(ctx, volumeID)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		lv.Spec.Size = *size
@@ -256,10 +294,10 @@ func (s *LogicalVolumeService) UpdateSpecSize(ctx context.Context, volumeID stri
 				continue
 			}
 			logger.Error(err, "failed to update LogicalVolume spec", "name", lv.Name)
-			return err
+			return 0, err
 		}
 
-		return nil
+		return lv.Generation, nil
 	}
 }
 
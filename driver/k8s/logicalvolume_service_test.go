@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// testLogicalVolume returns LogicalVolume updated by topolvm-node older than the conditions.
+func testLogicalVolume(size resource.Quantity) *topolvmv1.LogicalVolume {
+	return &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			Name:        "pvc-1",
+			NodeName:    "node1",
+			DeviceClass: "ssd",
+			Size:        size,
+		},
+	}
+}
+
+func newTestLogicalVolumeService(t *testing.T, lv *topolvmv1.LogicalVolume) *LogicalVolumeService {
+	scheme := runtime.NewScheme()
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lv).Build()
+	return &LogicalVolumeService{Client: c}
+}
+
+func TestCreateVolumeWithoutConditions(t *testing.T) {
+	lv := testLogicalVolume(resource.MustParse("1Gi"))
+	lv.Status.Code = codes.ResourceExhausted
+	lv.Status.Message = "no enough space left on VG"
+	s := newTestLogicalVolumeService(t, lv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := s.CreateVolume(ctx, "node1", "ssd", "pvc-1", 1, nil)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("the deprecated code should be returned: %v", err)
+	}
+	err = s.Get(ctx, client.ObjectKey{Name: "pvc-1"}, new(topolvmv1.LogicalVolume))
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("the failed LogicalVolume should be deleted: %v", err)
+	}
+}
+
+func TestExpandVolumeWithoutConditions(t *testing.T) {
+	lv := testLogicalVolume(resource.MustParse("1Gi"))
+	lv.Status.VolumeID = "vol1"
+	// topolvm-node has already expanded the volume.
+	lv.Status.CurrentSize = resource.NewQuantity(2<<30, resource.BinarySI)
+	s := newTestLogicalVolumeService(t, lv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.ExpandVolume(ctx, "vol1", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	lv2 := new(topolvmv1.LogicalVolume)
+	if err := s.Get(ctx, client.ObjectKey{Name: "pvc-1"}, lv2); err != nil {
+		t.Fatal(err)
+	}
+	lv2.Spec.Size = resource.MustParse("3Gi")
+	lv2.Status.Code = codes.Internal
+	lv2.Status.Message = "failed to resize"
+	lv2.Status.CurrentSize = resource.NewQuantity(3<<30, resource.BinarySI)
+	if err := s.Update(ctx, lv2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ExpandVolume(ctx, "vol1", 3); status.Code(err) != codes.Internal {
+		t.Errorf("the deprecated code should be returned: %v", err)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -15,14 +18,19 @@ import (
 var ErrNodeNotFound = errors.New("node not found")
 var ErrDeviceClassNotFound = errors.New("device class not found")
 
+//+kubebuilder:rbac:groups=topolvm.cybozu.com,resources=topolvmnodes,verbs=get;list;watch
+
 // NodeService represents node service.
 type NodeService struct {
 	client.Client
+	topolvmNode bool
 }
 
 // NewNodeService returns NodeService.
-func NewNodeService(mgr manager.Manager) *NodeService {
-	return &NodeService{Client: mgr.GetClient()}
+// If topolvmNode is true, the capacity of nodes is read from TopolvmNode instead of
+// the annotations of Node.
+func NewNodeService(mgr manager.Manager, topolvmNode bool) *NodeService {
+	return &NodeService{Client: mgr.GetClient(), topolvmNode: topolvmNode}
 }
 
 func (s NodeService) getNodes(ctx context.Context) (*corev1.NodeList, error) {
@@ -34,6 +42,29 @@ func (s NodeService) getNodes(ctx context.Context) (*corev1.NodeList, error) {
 	return nl, nil
 }
 
+func (s NodeService) extractCapacity(ctx context.Context, node *corev1.Node, deviceClass string) (int64, error) {
+	if !s.topolvmNode {
+		return s.extractCapacityFromAnnotation(node, deviceClass)
+	}
+
+	tn := new(topolvmv1.TopolvmNode)
+	err := s.Get(ctx, client.ObjectKey{Name: node.Name}, tn)
+	if apierrors.IsNotFound(err) {
+		return 0, ErrDeviceClassNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	dc := tn.Status.DeviceClass(deviceClass)
+	if dc == nil {
+		return 0, ErrDeviceClassNotFound
+	}
+	if tn.Status.Stale(time.Now()) {
+		return 0, nil
+	}
+	return dc.AvailableBytes(), nil
+}
+
 func (s NodeService) extractCapacityFromAnnotation(node *corev1.Node, deviceClass string) (int64, error) {
 	if deviceClass == topolvm.DefaultDeviceClassName {
 		deviceClass = topolvm.DefaultDeviceClassAnnotationName
@@ -45,6 +76,16 @@ func (s NodeService) extractCapacityFromAnnotation(node *corev1.Node, deviceClas
 	return strconv.ParseInt(c, 10, 64)
 }
 
+// GetNode returns Node by name.
+func (s NodeService) GetNode(ctx context.Context, name string) (*corev1.Node, error) {
+	n := new(corev1.Node)
+	err := s.Get(ctx, client.ObjectKey{Name: name}, n)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
 // GetCapacityByName returns VG capacity of specified node by name.
 func (s NodeService) GetCapacityByName(ctx context.Context, name, deviceClass string) (int64, error) {
 	n := new(corev1.Node)
@@ -53,7 +94,7 @@ func (s NodeService) GetCapacityByName(ctx context.Context, name, deviceClass st
 		return 0, err
 	}
 
-	return s.extractCapacityFromAnnotation(n, deviceClass)
+	return s.extractCapacity(ctx, n, deviceClass)
 }
 
 // GetCapacityByTopologyLabel returns VG capacity of specified node by TopoLVM's topology label.
@@ -68,7 +109,7 @@ func (s NodeService) GetCapacityByTopologyLabel(ctx context.Context, topology, d
 			if v != topology {
 				continue
 			}
-			return s.extractCapacityFromAnnotation(&node, dc)
+			return s.extractCapacity(ctx, &node, dc)
 		}
 	}
 
@@ -84,7 +125,7 @@ func (s NodeService) GetTotalCapacity(ctx context.Context, dc string) (int64, er
 
 	capacity := int64(0)
 	for _, node := range nl.Items {
-		c, _ := s.extractCapacityFromAnnotation(&node, dc)
+		c, _ := s.extractCapacity(ctx, &node, dc)
 		capacity += c
 	}
 	return capacity, nil
@@ -99,7 +140,7 @@ func (s NodeService) GetMaxCapacity(ctx context.Context, deviceClass string) (st
 	var nodeName string
 	var maxCapacity int64
 	for _, node := range nl.Items {
-		c, _ := s.extractCapacityFromAnnotation(&node, deviceClass)
+		c, _ := s.extractCapacity(ctx, &node, deviceClass)
 		if maxCapacity < c {
 			maxCapacity = c
 			nodeName = node.Name
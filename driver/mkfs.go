@@ -0,0 +1,109 @@
+package driver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// mkfsFlags is the allowlist of mkfs flags for each filesystem type.
+// The value is true if the flag takes an argument.
+var mkfsFlags = map[string]map[string]bool{
+	"ext4": extMkfsFlags,
+	"ext3": extMkfsFlags,
+	"xfs": {
+		"-b": true,
+		"-d": true,
+		"-i": true,
+		"-l": true,
+		"-m": true,
+		"-n": true,
+		"-L": true,
+		"-s": true,
+		"-K": false,
+	},
+	"btrfs": {
+		"-d": true,
+		"-m": true,
+		"-n": true,
+		"-s": true,
+		"-L": true,
+		"-O": true,
+		"-R": true,
+		"-K": false,
+	},
+}
+
+var extMkfsFlags = map[string]bool{
+	"-b": true,
+	"-E": true,
+	"-i": true,
+	"-I": true,
+	"-J": true,
+	"-L": true,
+	"-m": true,
+	"-N": true,
+	"-O": true,
+	"-T": true,
+}
+
+// forceFormatFlag is the flag added to mkfs to format the device without confirmation.
+var forceFormatFlag = map[string]string{
+	"ext4": "-F",
+	"ext3": "-F",
+}
+
+// fsTypeOrDefault returns fsType, or the default filesystem type if it is empty.
+func fsTypeOrDefault(fsType string) string {
+	if fsType == "" {
+		return "ext4"
+	}
+	return fsType
+}
+
+var mkfsArgPattern = regexp.MustCompile(`^[A-Za-z0-9_.,:=+^-]+$`)
+
+// parseMkfsOptions validates the mkfs options against the allowlist of fsType
+// and returns the arguments for mkfs.
+// Both "-m 0" and "-m0" forms are accepted for the flags that take an argument.
+func parseMkfsOptions(fsType, options string) ([]string, error) {
+	flags, ok := mkfsFlags[fsType]
+	if !ok {
+		return nil, fmt.Errorf("mkfs options are not supported for %s", fsType)
+	}
+
+	var args []string
+	fields := strings.Fields(options)
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 2 || f[0] != '-' {
+			return nil, fmt.Errorf("unexpected mkfs argument: %s", f)
+		}
+		flag := f[:2]
+		hasArg, ok := flags[flag]
+		if !ok {
+			return nil, fmt.Errorf("mkfs flag %s is not allowed for %s", flag, fsType)
+		}
+		if !hasArg {
+			if len(f) != 2 {
+				return nil, fmt.Errorf("mkfs flag %s does not take an argument", flag)
+			}
+			args = append(args, flag)
+			continue
+		}
+
+		value := f[2:]
+		if value == "" {
+			if i+1 == len(fields) {
+				return nil, fmt.Errorf("mkfs flag %s requires an argument", flag)
+			}
+			i++
+			value = fields[i]
+		}
+		if !mkfsArgPattern.MatchString(value) || value[0] == '-' {
+			return nil, fmt.Errorf("invalid argument for mkfs flag %s: %s", flag, value)
+		}
+		args = append(args, flag, value)
+	}
+	return args, nil
+}
@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMkfsOptions(t *testing.T) {
+	cases := []struct {
+		fsType   string
+		options  string
+		expected []string
+		valid    bool
+	}{
+		{"ext4", "-m 0 -E lazy_itable_init=0,nodiscard", []string{"-m", "0", "-E", "lazy_itable_init=0,nodiscard"}, true},
+		{"ext4", "-m0 -L data", []string{"-m", "0", "-L", "data"}, true},
+		{"ext3", "-O ^has_journal", []string{"-O", "^has_journal"}, true},
+		{"xfs", "-i size=512 -K", []string{"-i", "size=512", "-K"}, true},
+		{"btrfs", "-L cache -O no-holes -K", []string{"-L", "cache", "-O", "no-holes", "-K"}, true},
+		{"xfs", "-E nodiscard", nil, false},
+		{"ext4", "-m", nil, false},
+		{"ext4", "-F", nil, false},
+		{"ext4", "/dev/sda", nil, false},
+		{"ext4", "-L -F", nil, false},
+		{"ext4", "-L a;b", nil, false},
+		{"xfs", "-Kf", nil, false},
+		{"vfat", "-n data", nil, false},
+	}
+
+	for _, c := range cases {
+		args, err := parseMkfsOptions(c.fsType, c.options)
+		if c.valid && err != nil {
+			t.Errorf("%s %q: unexpected error: %v", c.fsType, c.options, err)
+			continue
+		}
+		if !c.valid {
+			if err == nil {
+				t.Errorf("%s %q: should be invalid", c.fsType, c.options)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(args, c.expected) {
+			t.Errorf("%s %q: expected %v, actual %v", c.fsType, c.options, c.expected, args)
+		}
+	}
+}
@@ -2,15 +2,16 @@ package driver
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	"github.com/topolvm/topolvm/csi"
 	"github.com/topolvm/topolvm/driver/k8s"
 	"github.com/topolvm/topolvm/filesystem"
@@ -40,9 +41,10 @@ const (
 var nodeLogger = ctrl.Log.WithName("driver").WithName("node")
 
 // NewNodeService returns a new NodeServer.
-func NewNodeService(nodeName string, conn *grpc.ClientConn, service *k8s.LogicalVolumeService) csi.NodeServer {
+func NewNodeService(nodeName string, conn *grpc.ClientConn, service *k8s.LogicalVolumeService, cgroupRoot string) csi.NodeServer {
 	return &nodeService{
 		nodeName:     nodeName,
+		cgroupRoot:   cgroupRoot,
 		client:       proto.NewVGServiceClient(conn),
 		lvService:    proto.NewLVServiceClient(conn),
 		k8sLVService: service,
@@ -60,10 +62,153 @@ type nodeService struct {
 	client       proto.VGServiceClient
 	lvService    proto.LVServiceClient
 	k8sLVService *k8s.LogicalVolumeService
+	cgroupRoot   string
 	mu           sync.Mutex
 	mounter      mountutil.SafeFormatAndMount
 }
 
+func (s *nodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
+	volumeID := req.GetVolumeId()
+	stagingPath := req.GetStagingTargetPath()
+
+	nodeLogger.Info("NodeStageVolume called",
+		"volume_id", volumeID,
+		"publish_context", req.GetPublishContext(),
+		"staging_target_path", stagingPath,
+		"volume_capability", req.GetVolumeCapability(),
+		"num_secrets", len(req.GetSecrets()),
+		"volume_context", req.GetVolumeContext())
+
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no volume_id is provided")
+	}
+	if len(stagingPath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no staging_target_path is provided")
+	}
+	if req.GetVolumeCapability() == nil {
+		return nil, status.Error(codes.InvalidArgument, "no volume_capability is provided")
+	}
+	isBlockVol := req.GetVolumeCapability().GetBlock() != nil
+	isFsVol := req.GetVolumeCapability().GetMount() != nil
+	if !(isBlockVol || isFsVol) {
+		return nil, status.Errorf(codes.InvalidArgument, "no supported volume capability: %v", req.GetVolumeCapability())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lvr, err := s.k8sLVService.GetVolume(ctx, volumeID)
+	if err == k8s.ErrVolumeNotFound {
+		return nil, status.Errorf(codes.NotFound, "failed to find LogicalVolume: %s", volumeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if name := lvr.Annotations[topolvm.MigratingKey]; name != "" {
+		return nil, status.Errorf(codes.Unavailable, "volume %s is being migrated by VolumeMigration %s", volumeID, name)
+	}
+	lv, err := s.getLvFromContext(ctx, lvr.Spec.DeviceClass, lvr.LVName())
+	if err != nil {
+		return nil, err
+	}
+	if lv == nil {
+		return nil, status.Errorf(codes.NotFound, "failed to find LV: %s", volumeID)
+	}
+
+	// The device file is kept until the volume is unstaged.
+	device := filepath.Join(DeviceDirectory, volumeID)
+	if err := s.createDeviceIfNeeded(device, lv); err != nil {
+		return nil, err
+	}
+
+	if isBlockVol {
+		nodeLogger.Info("NodeStageVolume(block) succeeded",
+			"volume_id", volumeID,
+			"staging_target_path", stagingPath)
+		return &csi.NodeStageVolumeResponse{}, nil
+	}
+
+	mountOption := req.GetVolumeCapability().GetMount()
+	mountOption.FsType = fsTypeOrDefault(mountOption.FsType)
+	accessMode := req.GetVolumeCapability().GetAccessMode().GetMode()
+	if !isSupportedAccessMode(accessMode) {
+		return nil, status.Errorf(codes.FailedPrecondition, "unsupported access mode: %s (%d)", accessModeName(accessMode), accessMode)
+	}
+
+	// The staging path is shared by all publishes on this node, so it is mounted
+	// as read-only only if the access mode does not allow any writer.
+	// Each publish can be made read-only by its bind mount.
+	var mountOptions []string
+	if isReadOnlyAccessMode(accessMode) {
+		mountOptions = append(mountOptions, "ro")
+	}
+	for _, f := range mountOption.MountFlags {
+		if f == "rw" && isReadOnlyAccessMode(accessMode) {
+			return nil, status.Errorf(codes.InvalidArgument, "mount option \"rw\" is specified even though access mode is %s", accessModeName(accessMode))
+		}
+	}
+	mountOptions = append(mountOptions, mountOption.MountFlags...)
+
+	err = s.mountFilesystem(volumeID, device, stagingPath, mountOption, mountOptions, req.GetVolumeContext())
+	if err != nil {
+		return nil, err
+	}
+
+	nodeLogger.Info("NodeStageVolume(fs) succeeded",
+		"volume_id", volumeID,
+		"staging_target_path", stagingPath,
+		"fstype", mountOption.FsType)
+	return &csi.NodeStageVolumeResponse{}, nil
+}
+
+func (s *nodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
+	volumeID := req.GetVolumeId()
+	stagingPath := req.GetStagingTargetPath()
+
+	nodeLogger.Info("NodeUnstageVolume called",
+		"volume_id", volumeID,
+		"staging_target_path", stagingPath)
+
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no volume_id is provided")
+	}
+	if len(stagingPath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no staging_target_path is provided")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device := filepath.Join(DeviceDirectory, volumeID)
+
+	_, err := os.Stat(stagingPath)
+	switch {
+	case err == nil:
+		mounted, err := filesystem.IsMounted(device, stagingPath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", stagingPath, err)
+		}
+		if mounted {
+			if err := s.mounter.Unmount(stagingPath); err != nil {
+				return nil, status.Errorf(codes.Internal, "unmount failed for %s: error=%v", stagingPath, err)
+			}
+		}
+	case os.IsNotExist(err):
+	default:
+		return nil, status.Errorf(codes.Internal, "stat failed for %s: %v", stagingPath, err)
+	}
+
+	err = os.Remove(device)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.Internal, "remove device failed for %s: error=%v", device, err)
+	}
+
+	nodeLogger.Info("NodeUnstageVolume is succeeded",
+		"volume_id", volumeID,
+		"staging_target_path", stagingPath)
+	return &csi.NodeUnstageVolumeResponse{}, nil
+}
+
 func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
 	volumeContext := req.GetVolumeContext()
 	volumeID := req.GetVolumeId()
@@ -71,6 +216,7 @@ func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	nodeLogger.Info("NodePublishVolume called",
 		"volume_id", volumeID,
 		"publish_context", req.GetPublishContext(),
+		"staging_target_path", req.GetStagingTargetPath(),
 		"target_path", req.GetTargetPath(),
 		"volume_capability", req.GetVolumeCapability(),
 		"read_only", req.GetReadonly(),
@@ -92,38 +238,43 @@ func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return nil, status.Errorf(codes.InvalidArgument, "no supported volume capability: %v", req.GetVolumeCapability())
 	}
 	isInlineEphemeralVolumeReq := volumeContext[ephVolConKey] == "true"
+	if !isInlineEphemeralVolumeReq && len(req.GetStagingTargetPath()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no staging_target_path is provided")
+	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	var lv *proto.LogicalVolume
+	var ioLimits *topolvmv1.IOLimits
+	var deviceClass string
 	var err error
 	if isInlineEphemeralVolumeReq {
-		lv, err = s.getLvFromContext(ctx, topolvm.DefaultDeviceClassName, volumeID)
+		deviceClass = topolvm.EphemeralVolumeDeviceClass(volumeContext)
+		lv, err = s.getLvFromContext(ctx, deviceClass, volumeID)
 		if err != nil {
 			return nil, err
 		}
 		// Need to check if the LV already exists so this block is idempotent.
 		if lv == nil {
-			var reqGb uint64 = topolvm.DefaultSizeGb
-			if sizeStr, ok := volumeContext[topolvm.EphemeralVolumeSizeKey]; ok {
-				var err error
-				reqGb, err = strconv.ParseUint(sizeStr, 10, 64)
-				if err != nil {
-					return nil, status.Errorf(codes.InvalidArgument, "Invalid size: %s", sizeStr)
-				}
+			size, err := topolvm.EphemeralVolumeSize(volumeContext)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid size: %v", err)
 			}
-			nodeLogger.Info("Processing ephemeral inline volume request", "reqGb", reqGb)
-			_, err := s.lvService.CreateLV(ctx, &proto.CreateLVRequest{
+			reqGb := uint64(size >> 30)
+			nodeLogger.Info("Processing ephemeral inline volume request",
+				"reqGb", reqGb,
+				"device_class", deviceClass)
+			_, err = s.lvService.CreateLV(ctx, &proto.CreateLVRequest{
 				Name:        volumeID,
-				DeviceClass: topolvm.DefaultDeviceClassName,
+				DeviceClass: deviceClass,
 				SizeGb:      reqGb,
 				Tags:        []string{"ephemeral"},
 			})
 			if err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to create LV %v", err)
 			}
-			lv, err = s.getLvFromContext(ctx, topolvm.DefaultDeviceClassName, volumeID)
+			lv, err = s.getLvFromContext(ctx, deviceClass, volumeID)
 			if err != nil {
 				return nil, err
 			}
@@ -133,10 +284,11 @@ func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		if err != nil {
 			return nil, err
 		}
-		lv, err = s.getLvFromContext(ctx, lvr.Spec.DeviceClass, volumeID)
+		lv, err = s.getLvFromContext(ctx, lvr.Spec.DeviceClass, lvr.LVName())
 		if err != nil {
 			return nil, err
 		}
+		ioLimits = lvr.Spec.IOLimits
 	}
 	if lv == nil {
 		return nil, status.Errorf(codes.NotFound, "failed to find LV: %s", volumeID)
@@ -145,7 +297,7 @@ func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	if isBlockVol {
 		err = s.nodePublishBlockVolume(req, lv)
 	} else if isFsVol {
-		err = s.nodePublishFilesystemVolume(req, lv)
+		err = s.nodePublishFilesystemVolume(req, lv, isInlineEphemeralVolumeReq)
 	}
 
 	if err != nil {
@@ -154,25 +306,44 @@ func (s *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 			// guarantee that NodePublishVolume will be called again, so if
 			// anything fails after the volume is created we need to attempt to
 			// clean up the LVM so we don't leak storage space.
-			if _, err := s.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: volumeID, DeviceClass: topolvm.DefaultDeviceClassName}); err != nil {
+			if _, err := s.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: volumeID, DeviceClass: deviceClass}); err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to remove LV for %s: %v", volumeID, err)
 			}
 		}
 		return nil, err
 	}
+
+	// If the pod UID is not given, the limits will be applied by the IO limits runner later.
+	if podUID := volumeContext[podUIDKey]; !ioLimits.IsZero() && podUID != "" {
+		if _, err := ApplyIOLimits(s.cgroupRoot, podUID, lv.DevMajor, lv.DevMinor, ioLimits); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to apply IO limits: volume=%s, pod=%s, error=%v", volumeID, podUID, err)
+		}
+		nodeLogger.Info("applied IO limits",
+			"volume_id", volumeID,
+			"pod_uid", podUID,
+			"io_limits", ioLimits)
+	}
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
-func (s *nodeService) nodePublishFilesystemVolume(req *csi.NodePublishVolumeRequest, lv *proto.LogicalVolume) error {
+func (s *nodeService) nodePublishFilesystemVolume(req *csi.NodePublishVolumeRequest, lv *proto.LogicalVolume, isInlineEphemeralVolumeReq bool) error {
 	// Check request
 	mountOption := req.GetVolumeCapability().GetMount()
-	if mountOption.FsType == "" {
-		mountOption.FsType = "ext4"
-	}
+	mountOption.FsType = fsTypeOrDefault(mountOption.FsType)
 	accessMode := req.GetVolumeCapability().GetAccessMode().GetMode()
-	if accessMode != csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER {
-		modeName := csi.VolumeCapability_AccessMode_Mode_name[int32(accessMode)]
-		return status.Errorf(codes.FailedPrecondition, "unsupported access mode: %s (%d)", modeName, accessMode)
+	if !isSupportedAccessMode(accessMode) {
+		return status.Errorf(codes.FailedPrecondition, "unsupported access mode: %s (%d)", accessModeName(accessMode), accessMode)
+	}
+
+	readonly := req.GetReadonly() || isReadOnlyAccessMode(accessMode)
+	var mountOptions []string
+	if readonly {
+		mountOptions = append(mountOptions, "ro")
+	}
+	for _, f := range mountOption.MountFlags {
+		if f == "rw" && readonly {
+			return status.Error(codes.InvalidArgument, "mount option \"rw\" is specified even though read only mode is specified")
+		}
 	}
 
 	// Find lv and create a block device with it
@@ -182,16 +353,35 @@ func (s *nodeService) nodePublishFilesystemVolume(req *csi.NodePublishVolumeRequ
 		return err
 	}
 
-	var mountOptions []string
-	if req.GetReadonly() {
-		mountOptions = append(mountOptions, "ro")
+	// Inline ephemeral volumes are not staged, so they are mounted directly on the target path.
+	if isInlineEphemeralVolumeReq {
+		err := s.mountFilesystem(req.GetVolumeId(), device, req.GetTargetPath(), mountOption,
+			append(mountOptions, mountOption.MountFlags...), req.GetVolumeContext())
+		if err != nil {
+			return err
+		}
+		nodeLogger.Info("NodePublishVolume(fs) succeeded",
+			"volume_id", req.GetVolumeId(),
+			"target_path", req.GetTargetPath(),
+			"fstype", mountOption.FsType)
+		return nil
 	}
 
-	for _, f := range mountOption.MountFlags {
-		if f == "rw" && req.GetReadonly() {
-			return status.Error(codes.InvalidArgument, "mount option \"rw\" is specified even though read only mode is specified")
-		}
-		mountOptions = append(mountOptions, f)
+	fsType, err := filesystem.DetectFilesystem(device)
+	if err != nil {
+		return status.Errorf(codes.Internal, "filesystem check failed: volume=%s, error=%v", req.GetVolumeId(), err)
+	}
+	if fsType != mountOption.FsType {
+		return status.Errorf(codes.Internal, "target device is formatted with different filesystem: volume=%s, current=%s, new:%s", req.GetVolumeId(), fsType, mountOption.FsType)
+	}
+
+	stagingPath := req.GetStagingTargetPath()
+	staged, err := filesystem.IsMounted(device, stagingPath)
+	if err != nil {
+		return status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", stagingPath, err)
+	}
+	if !staged {
+		return status.Errorf(codes.FailedPrecondition, "volume is not staged: volume=%s, staging_target_path=%s", req.GetVolumeId(), stagingPath)
 	}
 
 	err = os.MkdirAll(req.GetTargetPath(), 0755)
@@ -199,34 +389,110 @@ func (s *nodeService) nodePublishFilesystemVolume(req *csi.NodePublishVolumeRequ
 		return status.Errorf(codes.Internal, "mkdir failed: target=%s, error=%v", req.GetTargetPath(), err)
 	}
 
+	mounted, err := filesystem.IsMounted(device, req.GetTargetPath())
+	if err != nil {
+		return status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", req.GetTargetPath(), err)
+	}
+	if !mounted && accessMode == csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER {
+		if err := checkNotPublished(device, stagingPath); err != nil {
+			return err
+		}
+	}
+	if !mounted {
+		if err := s.mounter.Mount(stagingPath, req.GetTargetPath(), "", append([]string{"bind"}, mountOptions...)); err != nil {
+			return status.Errorf(codes.Internal, "bind mount failed: volume=%s, error=%v", req.GetVolumeId(), err)
+		}
+	}
+
+	nodeLogger.Info("NodePublishVolume(fs) succeeded",
+		"volume_id", req.GetVolumeId(),
+		"staging_target_path", stagingPath,
+		"target_path", req.GetTargetPath(),
+		"fstype", mountOption.FsType)
+
+	return nil
+}
+
+// checkNotPublished returns FailedPrecondition if device is mounted anywhere other than stagingPath.
+func checkNotPublished(device, stagingPath string) error {
+	mountPoints, err := filesystem.MountPoints(device)
+	if err != nil {
+		return status.Errorf(codes.Internal, "mount check failed: device=%s, error=%v", device, err)
+	}
+	stagingPath, err = filepath.EvalSymlinks(stagingPath)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to evaluate %s: %v", stagingPath, err)
+	}
+	for _, p := range mountPoints {
+		if p != stagingPath {
+			return status.Errorf(codes.FailedPrecondition, "volume is already published at %s", p)
+		}
+	}
+	return nil
+}
+
+// mountFilesystem formats device if needed and mounts it on target.
+func (s *nodeService) mountFilesystem(volumeID, device, target string, mountOption *csi.VolumeCapability_MountVolume, mountOptions []string, volumeContext map[string]string) error {
+	if mountOption.FsType == btrfsType {
+		opts, err := btrfsMountOptions(volumeContext)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		mountOptions = append(mountOptions, opts...)
+	}
+
+	err := os.MkdirAll(target, 0755)
+	if err != nil {
+		return status.Errorf(codes.Internal, "mkdir failed: target=%s, error=%v", target, err)
+	}
+
 	fsType, err := filesystem.DetectFilesystem(device)
 	if err != nil {
-		return status.Errorf(codes.Internal, "filesystem check failed: volume=%s, error=%v", req.GetVolumeId(), err)
+		return status.Errorf(codes.Internal, "filesystem check failed: volume=%s, error=%v", volumeID, err)
 	}
 
 	if fsType != "" && fsType != mountOption.FsType {
-		return status.Errorf(codes.Internal, "target device is already formatted with different filesystem: volume=%s, current=%s, new:%s", req.GetVolumeId(), fsType, mountOption.FsType)
+		return status.Errorf(codes.Internal, "target device is already formatted with different filesystem: volume=%s, current=%s, new:%s", volumeID, fsType, mountOption.FsType)
 	}
 
-	mounted, err := filesystem.IsMounted(device, req.GetTargetPath())
+	mounted, err := filesystem.IsMounted(device, target)
 	if err != nil {
-		return status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", req.GetTargetPath(), err)
+		return status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", target, err)
+	}
+
+	// mkfs options are applied only at the first format.
+	if mkfsOptions := volumeContext[topolvm.MkfsOptionsKey]; fsType == "" && mkfsOptions != "" {
+		if err := s.format(device, mountOption.FsType, mkfsOptions); err != nil {
+			return status.Errorf(codes.Internal, "format failed: volume=%s, error=%v", volumeID, err)
+		}
 	}
 
 	if !mounted {
-		if err := s.mounter.FormatAndMount(device, req.GetTargetPath(), mountOption.FsType, mountOptions); err != nil {
-			return status.Errorf(codes.Internal, "mount failed: volume=%s, error=%v", req.GetVolumeId(), err)
+		if err := s.mounter.FormatAndMount(device, target, mountOption.FsType, mountOptions); err != nil {
+			return status.Errorf(codes.Internal, "mount failed: volume=%s, error=%v", volumeID, err)
 		}
-		if err := os.Chmod(req.GetTargetPath(), 0777|os.ModeSetgid); err != nil {
-			return status.Errorf(codes.Internal, "chmod 2777 failed: target=%s, error=%v", req.GetTargetPath(), err)
+		if err := os.Chmod(target, 0777|os.ModeSetgid); err != nil {
+			return status.Errorf(codes.Internal, "chmod 2777 failed: target=%s, error=%v", target, err)
 		}
 	}
+	return nil
+}
 
-	nodeLogger.Info("NodePublishVolume(fs) succeeded",
-		"volume_id", req.GetVolumeId(),
-		"target_path", req.GetTargetPath(),
-		"fstype", mountOption.FsType)
+func (s *nodeService) format(device, fsType, mkfsOptions string) error {
+	args, err := parseMkfsOptions(fsType, mkfsOptions)
+	if err != nil {
+		return err
+	}
+	if flag, ok := forceFormatFlag[fsType]; ok {
+		args = append([]string{flag}, args...)
+	}
+	args = append(args, device)
 
+	out, err := s.mounter.Exec.Command("mkfs."+fsType, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mkfs.%s failed: args=%v, output=%s, error=%v", fsType, args, string(out), err)
+	}
+	nodeLogger.Info("formatted device", "device", device, "fstype", fsType, "args", args)
 	return nil
 }
 
@@ -299,12 +565,52 @@ func (s *nodeService) findVolumeByID(listResp *proto.GetLVListResponse, name str
 	return nil
 }
 
-func (s *nodeService) getLvFromContext(ctx context.Context, deviceClass, volumeID string) (*proto.LogicalVolume, error) {
+func (s *nodeService) getLvFromContext(ctx context.Context, deviceClass, name string) (*proto.LogicalVolume, error) {
 	listResp, err := s.client.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: deviceClass})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list LV: %v", err)
 	}
-	return s.findVolumeByID(listResp, volumeID), nil
+	return s.findVolumeByID(listResp, name), nil
+}
+
+// findEphemeralVolume looks for the inline ephemeral volume from all the device-classes.
+// It returns nil if volumeID is not an inline ephemeral volume.
+func (s *nodeService) findEphemeralVolume(ctx context.Context, volumeID string) (*proto.LogicalVolume, string, error) {
+	// Most inline ephemeral volumes are in the default device-class.
+	lv, err := s.getLvFromContext(ctx, topolvm.DefaultDeviceClassName, volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	if lv != nil {
+		if !s.isEphemeralVolume(lv) {
+			return nil, "", nil
+		}
+		return lv, topolvm.DefaultDeviceClassName, nil
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wc, err := s.client.Watch(wctx, &proto.Empty{})
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "failed to list device-classes: %v", err)
+	}
+	res, err := wc.Recv()
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "failed to list device-classes: %v", err)
+	}
+	for _, item := range res.GetItems() {
+		lv, err := s.getLvFromContext(ctx, item.GetDeviceClass(), volumeID)
+		if err != nil {
+			return nil, "", err
+		}
+		if lv != nil {
+			if !s.isEphemeralVolume(lv) {
+				return nil, "", nil
+			}
+			return lv, item.GetDeviceClass(), nil
+		}
+	}
+	return nil, "", nil
 }
 
 func (s *nodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (*csi.NodeUnpublishVolumeResponse, error) {
@@ -328,8 +634,6 @@ func (s *nodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpu
 
 	info, err := os.Stat(target)
 	if os.IsNotExist(err) {
-		// target_path does not exist, but device for mount-type PV may still exist.
-		_ = os.Remove(device)
 		return &csi.NodeUnpublishVolumeResponse{}, nil
 	} else if err != nil {
 		return nil, status.Errorf(codes.Internal, "stat failed for %s: %v", target, err)
@@ -341,12 +645,17 @@ func (s *nodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpu
 		if err != nil {
 			return unpublishResp, err
 		}
-		volume, err := s.getLvFromContext(ctx, topolvm.DefaultDeviceClassName, volID)
+		volume, deviceClass, err := s.findEphemeralVolume(ctx, volID)
 		if err != nil {
 			return nil, err
 		}
-		if volume != nil && s.isEphemeralVolume(volume) {
-			if _, err = s.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: volID, DeviceClass: topolvm.DefaultDeviceClassName}); err != nil {
+		// Inline ephemeral volumes are not staged, so the device file is removed here.
+		if volume != nil {
+			err = os.Remove(device)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, status.Errorf(codes.Internal, "remove device failed for %s: error=%v", device, err)
+			}
+			if _, err = s.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: volID, DeviceClass: deviceClass}); err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to remove LV for %s: %v", volID, err)
 			}
 		}
@@ -381,11 +690,6 @@ func (s *nodeService) nodeUnpublishFilesystemVolume(req *csi.NodeUnpublishVolume
 		return nil, status.Errorf(codes.Internal, "remove dir failed for %s: error=%v", target, err)
 	}
 
-	err = os.Remove(device)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, status.Errorf(codes.Internal, "remove device failed for %s: error=%v", device, err)
-	}
-
 	nodeLogger.Info("NodeUnpublishVolume(fs) is succeeded",
 		"volume_id", req.GetVolumeId(),
 		"target_path", target)
@@ -432,8 +736,10 @@ func (s *nodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "seek on %s was failed: %v", p, err)
 		}
+		cond := s.volumeConditionOrNil(ctx, volID, p, true)
 		return &csi.NodeGetVolumeStatsResponse{
-			Usage: []*csi.VolumeUsage{{Total: pos, Unit: csi.VolumeUsage_BYTES}},
+			Usage:           []*csi.VolumeUsage{{Total: pos, Unit: csi.VolumeUsage_BYTES}},
+			VolumeCondition: cond,
 		}, nil
 	}
 
@@ -455,6 +761,7 @@ func (s *nodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 			Available: int64(sfs.Bavail) * sfs.Frsize,
 		})
 	}
+	// Some filesystems such as btrfs do not have a fixed number of inodes and report zero.
 	if sfs.Files > 0 {
 		usage = append(usage, &csi.VolumeUsage{
 			Unit:      csi.VolumeUsage_INODES,
@@ -463,7 +770,19 @@ func (s *nodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 			Available: int64(sfs.Ffree),
 		})
 	}
-	return &csi.NodeGetVolumeStatsResponse{Usage: usage}, nil
+	cond := s.volumeConditionOrNil(ctx, volID, p, false)
+	return &csi.NodeGetVolumeStatsResponse{Usage: usage, VolumeCondition: cond}, nil
+}
+
+// volumeConditionOrNil returns nil if the condition of the volume cannot be checked,
+// so that NodeGetVolumeStats still reports the usage.
+func (s *nodeService) volumeConditionOrNil(ctx context.Context, volID, p string, isBlock bool) *csi.VolumeCondition {
+	cond, err := s.volumeCondition(ctx, volID, p, isBlock)
+	if err != nil {
+		nodeLogger.Error(err, "failed to check volume condition", "volume_id", volID, "volume_path", p)
+		return nil
+	}
+	return cond
 }
 
 func (s *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
@@ -511,14 +830,15 @@ func (s *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 	}
 
 	device := filepath.Join(DeviceDirectory, vid)
+	var lv *proto.LogicalVolume
 	lvr, err := s.k8sLVService.GetVolume(ctx, vid)
-	deviceClass := topolvm.DefaultDeviceClassName
-	if err == nil {
-		deviceClass = lvr.Spec.DeviceClass
-	} else if err != k8s.ErrVolumeNotFound {
-		return nil, err
+	switch {
+	case err == nil:
+		lv, err = s.getLvFromContext(ctx, lvr.Spec.DeviceClass, lvr.LVName())
+	case err == k8s.ErrVolumeNotFound:
+		// Inline ephemeral volumes do not have LogicalVolume.
+		lv, _, err = s.findEphemeralVolume(ctx, vid)
 	}
-	lv, err := s.getLvFromContext(ctx, deviceClass, vid)
 	if err != nil {
 		return nil, err
 	}
@@ -530,7 +850,13 @@ func (s *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 		return nil, err
 	}
 
-	args := []string{"-o", "source", "--noheadings", "--target", req.GetVolumePath()}
+	// The filesystem is resized through the staging path if it is given.
+	mountPath := vpath
+	if sp := req.GetStagingTargetPath(); sp != "" {
+		mountPath = sp
+	}
+
+	args := []string{"-o", "source", "--noheadings", "--target", mountPath}
 	output, err := s.mounter.Exec.Command(findmntCmd, args...).Output()
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "findmnt error occured: %v", err)
@@ -538,20 +864,30 @@ func (s *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 
 	devicePath := strings.TrimSpace(string(output))
 	if len(devicePath) == 0 {
-		return nil, status.Errorf(codes.Internal, "filesystem %s is not mounted at %s", vid, vpath)
+		return nil, status.Errorf(codes.Internal, "filesystem %s is not mounted at %s", vid, mountPath)
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	r := mountutil.NewResizeFs(s.mounter.Exec)
-	if _, err := r.Resize(device, vpath); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to resize filesystem %s (mounted at: %s): %v", vid, vpath, err)
+	fsType, err := filesystem.DetectFilesystem(device)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "filesystem check failed: volume=%s, error=%v", vid, err)
+	}
+
+	// ResizeFs does not support btrfs.
+	if fsType == btrfsType {
+		err = resizeBtrfs(s.mounter.Exec, mountPath)
+	} else {
+		_, err = mountutil.NewResizeFs(s.mounter.Exec).Resize(device, mountPath)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to resize filesystem %s (mounted at: %s): %v", vid, mountPath, err)
 	}
 
 	nodeLogger.Info("NodeExpandVolume(fs) is succeeded",
 		"volume_id", vid,
-		"target_path", vpath,
+		"target_path", mountPath,
 	)
 
 	// `capacity_bytes` in NodeExpandVolumeResponse is defined as OPTIONAL.
@@ -563,8 +899,11 @@ func (s *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 
 func (s *nodeService) NodeGetCapabilities(context.Context, *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
 	capabilities := []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
 		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
 		csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
+		csi.NodeServiceCapability_RPC_SINGLE_NODE_MULTI_WRITER,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
 	}
 
 	csiCaps := make([]*csi.NodeServiceCapability, len(capabilities))
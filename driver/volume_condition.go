@@ -0,0 +1,124 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/csi"
+	"github.com/topolvm/topolvm/driver/k8s"
+	"github.com/topolvm/topolvm/filesystem"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func normalCondition() *csi.VolumeCondition {
+	return &csi.VolumeCondition{Abnormal: false, Message: "volume is healthy"}
+}
+
+func abnormalCondition(format string, args ...interface{}) *csi.VolumeCondition {
+	return &csi.VolumeCondition{Abnormal: true, Message: fmt.Sprintf(format, args...)}
+}
+
+// lvCondition returns the condition of the logical volume reported by lvmd.
+func lvCondition(lv *proto.LogicalVolume) *csi.VolumeCondition {
+	switch {
+	case lv == nil:
+		return abnormalCondition("logical volume is not found")
+	case lv.GetInactive():
+		return abnormalCondition("logical volume is not active")
+	case lv.GetPartial():
+		return abnormalCondition("one or more physical volumes of the logical volume are missing")
+	case lv.GetPoolDataPercent() >= 100:
+		return abnormalCondition("thin pool is out of data space")
+	}
+	return nil
+}
+
+// deviceCondition returns an abnormal condition if the device number of path does not match lv.
+func deviceCondition(path string, lv *proto.LogicalVolume) (*csi.VolumeCondition, error) {
+	var st unix.Stat_t
+	err := filesystem.Stat(path, &st)
+	switch err {
+	case nil:
+	case unix.ENOENT:
+		return nil, nil
+	default:
+		return nil, status.Errorf(codes.Internal, "stat on %s was failed: %v", path, err)
+	}
+
+	expected := unix.Mkdev(lv.GetDevMajor(), lv.GetDevMinor())
+	if st.Rdev != expected {
+		return abnormalCondition("device number of %s does not match the logical volume: expected=%d:%d, actual=%d:%d",
+			path, lv.GetDevMajor(), lv.GetDevMinor(), unix.Major(st.Rdev), unix.Minor(st.Rdev)), nil
+	}
+	return nil, nil
+}
+
+// volumeCondition checks the health of the volume published at volumePath.
+func (s *nodeService) volumeCondition(ctx context.Context, volumeID, volumePath string, isBlock bool) (*csi.VolumeCondition, error) {
+	var lv *proto.LogicalVolume
+	lvr, err := s.k8sLVService.GetVolume(ctx, volumeID)
+	switch {
+	case err == nil:
+		lv, err = s.getLvFromContext(ctx, lvr.Spec.DeviceClass, lvr.LVName())
+	case err == k8s.ErrVolumeNotFound:
+		// Inline ephemeral volumes do not have LogicalVolume.
+		lv, _, err = s.findEphemeralVolume(ctx, volumeID)
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to get LogicalVolume: %v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lvr == nil && lv == nil {
+		return abnormalCondition("LogicalVolume is not found"), nil
+	}
+	if cond := lvCondition(lv); cond != nil {
+		return cond, nil
+	}
+
+	devicePath := filepath.Join(DeviceDirectory, volumeID)
+	if isBlock {
+		devicePath = volumePath
+	}
+	cond, err := deviceCondition(devicePath, lv)
+	if err != nil || cond != nil {
+		return cond, err
+	}
+
+	if !isBlock {
+		readOnly, err := filesystem.IsReadOnlySuperblock(volumePath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "mount check failed: target=%s, error=%v", volumePath, err)
+		}
+		if readOnly {
+			return abnormalCondition("filesystem is remounted read-only, it may have errors"), nil
+		}
+	}
+	return normalCondition(), nil
+}
+
+// volumeCondition checks the health of the volume from the controller's point of view.
+func (s controllerService) volumeCondition(ctx context.Context, lv *topolvmv1.LogicalVolume) (*csi.VolumeCondition, error) {
+	if created := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.LogicalVolumeCreated); created != nil && created.Status == metav1.ConditionFalse {
+		return abnormalCondition("%s: %s", created.Reason, created.Message), nil
+	}
+	if degraded := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.LogicalVolumeDegraded); degraded != nil && degraded.Status == metav1.ConditionTrue {
+		return abnormalCondition("%s", degraded.Message), nil
+	}
+	_, err := s.nodeService.GetNode(ctx, lv.Spec.NodeName)
+	if apierrors.IsNotFound(err) {
+		return abnormalCondition("node %s is not found", lv.Spec.NodeName), nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get node: %v", err)
+	}
+	return normalCondition(), nil
+}
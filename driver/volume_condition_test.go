@@ -0,0 +1,56 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/topolvm/topolvm/csi"
+	"github.com/topolvm/topolvm/driver/k8s"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLVCondition(t *testing.T) {
+	cases := []struct {
+		lv       *proto.LogicalVolume
+		abnormal bool
+	}{
+		{nil, true},
+		{&proto.LogicalVolume{Name: "vol"}, false},
+		{&proto.LogicalVolume{Name: "vol", Inactive: true}, true},
+		{&proto.LogicalVolume{Name: "vol", Partial: true}, true},
+		{&proto.LogicalVolume{Name: "vol", PoolDataPercent: 99.9}, false},
+		{&proto.LogicalVolume{Name: "vol", PoolDataPercent: 100}, true},
+	}
+
+	for _, c := range cases {
+		cond := lvCondition(c.lv)
+		if c.abnormal && (cond == nil || !cond.Abnormal) {
+			t.Errorf("%v: should be abnormal", c.lv)
+		}
+		if !c.abnormal && cond != nil {
+			t.Errorf("%v: should not have condition: %v", c.lv, cond)
+		}
+	}
+}
+
+func TestNodeGetVolumeStatsWithoutCondition(t *testing.T) {
+	// LogicalVolume cannot be listed because it is not registered in the scheme.
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	s := &nodeService{k8sLVService: &k8s.LogicalVolumeService{Client: c}}
+
+	res, err := s.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   "vol1",
+		VolumePath: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetUsage()) == 0 {
+		t.Error("usage should be reported")
+	}
+	if res.GetVolumeCondition() != nil {
+		t.Errorf("condition should not be reported: %v", res.GetVolumeCondition())
+	}
+}
@@ -21,6 +21,9 @@ const cleanupTest = "cleanup-test"
 //go:embed testdata/cleanup/statefulset-template.yaml
 var statefulSetTemplateYAML string
 
+//go:embed testdata/cleanup/retain-pvc-pod.yaml
+var retainPVCPodYAML []byte
+
 func testCleanup() {
 
 	BeforeEach(func() {
@@ -33,6 +36,48 @@ func testCleanup() {
 	})
 
 	var targetLVs []topolvmv1.LogicalVolume
+	var retainedLV, retainedVolumeID string
+
+	It("should retain the volume after the claim is deleted", func() {
+		By("creating a PVC with Retain reclaim policy on topolvm-e2e-worker3")
+		stdout, stderr, err := kubectlWithInput(retainPVCPodYAML, "apply", "-f", "-")
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+
+		var volumeName string
+		Eventually(func() error {
+			stdout, stderr, err := kubectl("-n", cleanupTest, "get", "pvc", "retain-pvc", "-o=json")
+			if err != nil {
+				return fmt.Errorf("%v: stdout=%s, stderr=%s", err, stdout, stderr)
+			}
+			var pvc corev1.PersistentVolumeClaim
+			if err := json.Unmarshal(stdout, &pvc); err != nil {
+				return err
+			}
+			if pvc.Status.Phase != corev1.ClaimBound {
+				return fmt.Errorf("pvc is not bound: %s", pvc.Status.Phase)
+			}
+			volumeName = pvc.Spec.VolumeName
+			return nil
+		}).Should(Succeed())
+		retainedLV = volumeName
+
+		By("deleting the pod and the PVC")
+		stdout, stderr, err = kubectlWithInput(retainPVCPodYAML, "delete", "-f", "-")
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+
+		By("confirming the LogicalVolume is released")
+		Eventually(func() error {
+			lv, err := getLogicalVolume(retainedLV)
+			if err != nil {
+				return err
+			}
+			if lv.Status.PersistentVolumePhase != topolvmv1.PersistentVolumeReleased {
+				return fmt.Errorf("logicalvolume is not released: %s", lv.Status.PersistentVolumePhase)
+			}
+			retainedVolumeID = lv.Status.VolumeID
+			return nil
+		}).Should(Succeed())
+	})
 
 	It("should finalize the delete node", func() {
 		By("checking Node finalizer")
@@ -135,7 +180,7 @@ func testCleanup() {
 		Expect(err).ShouldNot(HaveOccurred())
 
 		for _, lv := range logicalVolumeList.Items {
-			if lv.Spec.NodeName == targetNode {
+			if lv.Spec.NodeName == targetNode && lv.Name != retainedLV {
 				targetLVs = append(targetLVs, lv)
 			}
 		}
@@ -238,6 +283,21 @@ func testCleanup() {
 		}).Should(Succeed())
 	})
 
+	It("should keep the retained LogicalVolume connected to the deleted node", func() {
+		Consistently(func() error {
+			_, err := getLogicalVolume(retainedLV)
+			return err
+		}, "30s").Should(Succeed())
+
+		By("deleting the retained PV and LogicalVolume")
+		stdout, stderr, err := kubectl("delete", "pv", retainedLV)
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+		stdout, stderr, err = kubectl("patch", "logicalvolume", retainedLV, "--type=merge", `-p={"metadata":{"finalizers":null}}`)
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+		stdout, stderr, err = kubectl("delete", "logicalvolume", retainedLV)
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+	})
+
 	It("should delete namespace", func() {
 		stdout, stderr, err := kubectl("delete", "ns", cleanupTest)
 		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
@@ -286,5 +346,20 @@ func testCleanup() {
 			stdout, stderr, err = execAtLocal("sudo", nil, "lvremove", "-y", "--select", "lv_name="+lv.Status.VolumeID)
 			Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
 		}
+
+		stdout, stderr, err := execAtLocal("sudo", nil, "lvremove", "-y", "--select", "lv_name="+retainedVolumeID)
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
 	})
 }
+
+func getLogicalVolume(name string) (*topolvmv1.LogicalVolume, error) {
+	stdout, stderr, err := kubectl("get", "logicalvolume", name, "-o=json")
+	if err != nil {
+		return nil, fmt.Errorf("%v: stdout=%s, stderr=%s", err, stdout, stderr)
+	}
+	var lv topolvmv1.LogicalVolume
+	if err := json.Unmarshal(stdout, &lv); err != nil {
+		return nil, err
+	}
+	return &lv, nil
+}
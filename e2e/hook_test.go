@@ -23,6 +23,18 @@ var podWithPVCYAML []byte
 //go:embed testdata/hook/ephemeral-volume-pod-with-pvc.yaml
 var ephemeralVolumePodWithPVCYAML []byte
 
+//go:embed testdata/hook/generic-ephemeral-volume-pod.yaml
+var genericEphemeralVolumePodYAML []byte
+
+func kubernetesMinorVersion() int64 {
+	kubernetesVersionStr := os.Getenv("TEST_KUBERNETES_VERSION")
+	kubernetesVersion := strings.Split(kubernetesVersionStr, ".")
+	ExpectWithOffset(1, len(kubernetesVersion)).To(Equal(2))
+	minor, err := strconv.ParseInt(kubernetesVersion[1], 10, 64)
+	ExpectWithOffset(1, err).ShouldNot(HaveOccurred())
+	return minor
+}
+
 func hasTopoLVMFinalizer(pvc *corev1.PersistentVolumeClaim) bool {
 	for _, fin := range pvc.Finalizers {
 		if fin == topolvm.PVCFinalizer {
@@ -74,13 +86,7 @@ func testHook() {
 
 		By("creating pod with TopoLVM PVC")
 		const minVerDryRun int64 = 18
-		kubernetesVersionStr := os.Getenv("TEST_KUBERNETES_VERSION")
-		kubernetesVersion := strings.Split(kubernetesVersionStr, ".")
-		Expect(len(kubernetesVersion)).To(Equal(2))
-		kubernetesMinorVersion, err := strconv.ParseInt(kubernetesVersion[1], 10, 64)
-		Expect(err).ShouldNot(HaveOccurred())
-
-		if kubernetesMinorVersion < minVerDryRun {
+		if kubernetesMinorVersion() < minVerDryRun {
 			stdout, stderr, err := kubectlWithInput(podWithPVCYAML, "-n", nsHookTest, "apply", "-f", "-", "--server-dry-run")
 			Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
 		} else {
@@ -215,4 +221,73 @@ func testHook() {
 		}).Should(Succeed())
 
 	})
+
+	It("should test hooks for generic ephemeral volumes", func() {
+		if isStorageCapacity() {
+			Skip(skipMessageForStorageCapacity)
+			return
+		}
+		// GenericEphemeralVolume feature gate is enabled by default since Kubernetes 1.21.
+		const minVerGenericEphemeralVolume int64 = 21
+		if kubernetesMinorVersion() < minVerGenericEphemeralVolume {
+			Skip("generic ephemeral volumes are not enabled")
+			return
+		}
+
+		By("creating pod with TopoLVM generic ephemeral volumes")
+		stdout, stderr, err := kubectlWithInput(genericEphemeralVolumePodYAML, "-n", nsHookTest, "apply", "-f", "-")
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+
+		By("checking pod is annotated with topolvm.cybozu.com/capacity")
+		Eventually(func() error {
+			result, stderr, err := kubectl("get", "-n", nsHookTest, "pods/testhttpd", "-o=json")
+			if err != nil {
+				return fmt.Errorf("%v: stdout=%s, stderr=%s", err, result, stderr)
+			}
+
+			var pod corev1.Pod
+			err = json.Unmarshal(result, &pod)
+			if err != nil {
+				return err
+			}
+
+			resources := pod.Spec.Containers[0].Resources
+			_, ok := resources.Limits[topolvm.CapacityResource]
+			if !ok {
+				return errors.New("resources.Limits is not mutated")
+			}
+			_, ok = resources.Requests[topolvm.CapacityResource]
+			if !ok {
+				return errors.New("resources.Requests is not mutated")
+			}
+
+			capacity, ok := pod.Annotations[topolvm.CapacityKeyPrefix+"ssd"]
+			if !ok {
+				return errors.New("not annotated")
+			}
+			if capacity != strconv.Itoa(3<<30) {
+				return fmt.Errorf("wrong capacity: actual=%s, expect=%d", capacity, 3<<30)
+			}
+
+			return nil
+		}).Should(Succeed())
+
+		By("checking the pod is running with the volumes")
+		Eventually(func() error {
+			result, stderr, err := kubectl("get", "-n", nsHookTest, "pods/testhttpd", "-o=json")
+			if err != nil {
+				return fmt.Errorf("%v: stdout=%s, stderr=%s", err, result, stderr)
+			}
+
+			var pod corev1.Pod
+			err = json.Unmarshal(result, &pod)
+			if err != nil {
+				return err
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				return errors.New("pod is not yet running")
+			}
+			return nil
+		}).Should(Succeed())
+	})
 }
@@ -24,20 +24,25 @@ var lvTemplateYAML string
 //go:embed testdata/publish/pod-with-mount-option-pvc.yaml
 var podWithMountOptionPVCYAML []byte
 
+type cleanupPaths struct {
+	targetPath  string
+	stagingPath string
+}
+
 type cleanup struct {
-	// key is volumeID, value is target path
-	volumes map[string]string
+	// key is volumeID
+	volumes map[string]cleanupPaths
 }
 
-func (c *cleanup) register(volumeID, targetPath string) {
+func (c *cleanup) register(volumeID, targetPath, stagingPath string) {
 	By("[cleanup] register")
 	if c.volumes == nil {
-		c.volumes = make(map[string]string)
+		c.volumes = make(map[string]cleanupPaths)
 	}
-	c.volumes[volumeID] = targetPath
+	c.volumes[volumeID] = cleanupPaths{targetPath: targetPath, stagingPath: stagingPath}
 }
 
-func (c *cleanup) unregister(volumeID, targetPath string) {
+func (c *cleanup) unregister(volumeID string) {
 	By("[cleanup] unregister")
 	if c.volumes != nil {
 		delete(c.volumes, volumeID)
@@ -46,15 +51,23 @@ func (c *cleanup) unregister(volumeID, targetPath string) {
 
 func (c *cleanup) unpublishVolumes(nc csi.NodeClient) {
 	By("[cleanup] unpublishVolumes")
-	for volumeID, targetPath := range c.volumes {
+	for volumeID, paths := range c.volumes {
 		req := &csi.NodeUnpublishVolumeRequest{
 			VolumeId:   volumeID,
-			TargetPath: targetPath,
+			TargetPath: paths.targetPath,
 		}
 		_, err := nc.NodeUnpublishVolume(context.Background(), req)
 		if err != nil {
 			fmt.Printf("failed to unpublish volume: %v", req)
 		}
+		unstageReq := &csi.NodeUnstageVolumeRequest{
+			VolumeId:          volumeID,
+			StagingTargetPath: paths.stagingPath,
+		}
+		_, err = nc.NodeUnstageVolume(context.Background(), unstageReq)
+		if err != nil {
+			fmt.Printf("failed to unstage volume: %v", unstageReq)
+		}
 	}
 	c.volumes = nil
 }
@@ -99,6 +112,7 @@ func testPublishVolume() {
 
 	It("should publish filesystem", func() {
 		mountTargetPath := "/mnt/csi-node-test"
+		stagingPath := "/mnt/csi-node-test-staging"
 
 		nodeName := "topolvm-e2e-worker"
 		if isDaemonsetLvmdEnvSet() {
@@ -140,7 +154,7 @@ func testPublishVolume() {
 			return nil
 		}).Should(Succeed())
 
-		cl.register(volumeID, mountTargetPath)
+		cl.register(volumeID, mountTargetPath, stagingPath)
 
 		By("creating Filesystem volume")
 		mountVolCap := &csi.VolumeCapability{
@@ -152,12 +166,34 @@ func testPublishVolume() {
 			},
 		}
 
+		By("publishing the volume before staging")
 		req := &csi.NodePublishVolumeRequest{
-			PublishContext:   map[string]string{},
-			TargetPath:       mountTargetPath,
-			VolumeCapability: mountVolCap,
-			VolumeId:         volumeID,
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        mountTargetPath,
+			VolumeCapability:  mountVolCap,
+			VolumeId:          volumeID,
+		}
+		_, err = nc.NodePublishVolume(context.Background(), req)
+		Expect(err).Should(HaveOccurred())
+
+		By("staging the volume")
+		stageReq := &csi.NodeStageVolumeRequest{
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			VolumeCapability:  mountVolCap,
+			VolumeId:          volumeID,
 		}
+		stageResp, err := nc.NodeStageVolume(context.Background(), stageReq)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(stageResp).ShouldNot(BeNil())
+
+		By("staging the volume again to check idempotency")
+		stageResp, err = nc.NodeStageVolume(context.Background(), stageReq)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(stageResp).ShouldNot(BeNil())
+
+		By("publishing the volume")
 		resp, err := nc.NodePublishVolume(context.Background(), req)
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(resp).ShouldNot(BeNil())
@@ -169,8 +205,9 @@ func testPublishVolume() {
 
 		By("publishing volume on same target path, but requested volume and existing one are different")
 		_, err = nc.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
-			PublishContext: map[string]string{},
-			TargetPath:     mountTargetPath,
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        mountTargetPath,
 			VolumeCapability: &csi.VolumeCapability{
 				AccessType: &csi.VolumeCapability_Mount{
 					Mount: &csi.VolumeCapability_MountVolume{FsType: "ext4"},
@@ -197,7 +234,21 @@ func testPublishVolume() {
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(unpubResp).ShouldNot(BeNil())
 
-		cl.unregister(volumeID, mountTargetPath)
+		By("unstaging the volume")
+		unstageReq := csi.NodeUnstageVolumeRequest{
+			VolumeId:          volumeID,
+			StagingTargetPath: stagingPath,
+		}
+		unstageResp, err := nc.NodeUnstageVolume(context.Background(), &unstageReq)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(unstageResp).ShouldNot(BeNil())
+
+		By("unstaging the volume again to check idempotency")
+		unstageResp, err = nc.NodeUnstageVolume(context.Background(), &unstageReq)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(unstageResp).ShouldNot(BeNil())
+
+		cl.unregister(volumeID)
 
 		By("cleaning logicalvolume")
 		stdout, stderr, err := kubectl("delete", "logicalvolume", "csi-node-test-fs")
@@ -206,6 +257,7 @@ func testPublishVolume() {
 
 	It("should be worked NodePublishVolume successfully to create a block device", func() {
 		deviceTargetPath := "/dev/csi-node-test"
+		stagingPath := "/mnt/csi-node-test-staging"
 
 		By("creating a logical volume resource")
 		nodeName := "topolvm-e2e-worker"
@@ -238,7 +290,7 @@ func testPublishVolume() {
 			return nil
 		}).Should(Succeed())
 
-		cl.register(volumeID, deviceTargetPath)
+		cl.register(volumeID, deviceTargetPath, stagingPath)
 
 		By("creating raw block volume")
 		blockVolCap := &csi.VolumeCapability{
@@ -250,11 +302,21 @@ func testPublishVolume() {
 			},
 		}
 
+		stageResp, err := nc.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			VolumeCapability:  blockVolCap,
+			VolumeId:          volumeID,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(stageResp).ShouldNot(BeNil())
+
 		req := &csi.NodePublishVolumeRequest{
-			PublishContext:   map[string]string{},
-			TargetPath:       deviceTargetPath,
-			VolumeCapability: blockVolCap,
-			VolumeId:         volumeID,
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        deviceTargetPath,
+			VolumeCapability:  blockVolCap,
+			VolumeId:          volumeID,
 		}
 		resp, err := nc.NodePublishVolume(context.Background(), req)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -267,10 +329,11 @@ func testPublishVolume() {
 
 		By("creating volume on the same target path, but requested volume and existing one are different")
 		_, err = nc.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
-			PublishContext:   map[string]string{},
-			TargetPath:       deviceTargetPath,
-			VolumeCapability: blockVolCap,
-			VolumeId:         volumeID + "-invalid",
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        deviceTargetPath,
+			VolumeCapability:  blockVolCap,
+			VolumeId:          volumeID + "-invalid",
 		})
 		Expect(err).Should(HaveOccurred())
 
@@ -288,7 +351,15 @@ func testPublishVolume() {
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(unpubResp).ShouldNot(BeNil())
 
-		cl.unregister(volumeID, deviceTargetPath)
+		By("unstaging the volume")
+		unstageResp, err := nc.NodeUnstageVolume(context.Background(), &csi.NodeUnstageVolumeRequest{
+			VolumeId:          volumeID,
+			StagingTargetPath: stagingPath,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(unstageResp).ShouldNot(BeNil())
+
+		cl.unregister(volumeID)
 
 		By("cleaning logicalvolume")
 		stdout, stderr, err := kubectl("delete", "logicalvolume", "csi-node-test-block")
@@ -351,8 +422,133 @@ func testPublishVolume() {
 		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
 	})
 
+	It("should publish filesystem according to single node access modes", func() {
+		mountTargetPath := "/mnt/csi-node-test"
+		mountTargetPath2 := "/mnt/csi-node-test2"
+		stagingPath := "/mnt/csi-node-test-staging"
+
+		By("creating a logical volume resource")
+		nodeName := "topolvm-e2e-worker"
+		if isDaemonsetLvmdEnvSet() {
+			nodeName = getDaemonsetLvmdNodeName()
+		}
+
+		name := "csi-node-test-fs"
+		lvYaml := []byte(fmt.Sprintf(lvTemplateYAML, name, name, nodeName))
+		_, _, err := kubectlWithInput(lvYaml, "apply", "-f", "-")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var volumeID string
+		Eventually(func() error {
+			stdout, stderr, err := kubectl("get", "logicalvolume", name, "-o", "yaml")
+			if err != nil {
+				return fmt.Errorf("failed to get logical volume. stdout: %s, stderr: %s, err: %v", stdout, stderr, err)
+			}
+
+			var lv topolvmv1.LogicalVolume
+			err = yaml.Unmarshal(stdout, &lv)
+			if err != nil {
+				return err
+			}
+
+			if len(lv.Status.VolumeID) == 0 {
+				return errors.New("VolumeID is not set")
+			}
+			volumeID = lv.Status.VolumeID
+			return nil
+		}).Should(Succeed())
+
+		cl.register(volumeID, mountTargetPath, stagingPath)
+
+		volCap := func(mode csi.VolumeCapability_AccessMode_Mode) *csi.VolumeCapability {
+			return &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{FsType: "xfs"},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: mode,
+				},
+			}
+		}
+		publish := func(target string, mode csi.VolumeCapability_AccessMode_Mode, readonly bool) error {
+			_, err := nc.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				PublishContext:    map[string]string{},
+				StagingTargetPath: stagingPath,
+				TargetPath:        target,
+				VolumeCapability:  volCap(mode),
+				VolumeId:          volumeID,
+				Readonly:          readonly,
+			})
+			return err
+		}
+		unpublish := func(target string) {
+			_, err := nc.NodeUnpublishVolume(context.Background(), &csi.NodeUnpublishVolumeRequest{
+				VolumeId:   volumeID,
+				TargetPath: target,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		By("staging the volume")
+		_, err = nc.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			VolumeCapability:  volCap(csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER),
+			VolumeId:          volumeID,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("publishing the volume with SINGLE_NODE_SINGLE_WRITER")
+		err = publish(mountTargetPath, csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER, false)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("publishing the volume with SINGLE_NODE_SINGLE_WRITER again to check idempotency")
+		err = publish(mountTargetPath, csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER, false)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("publishing the volume with SINGLE_NODE_SINGLE_WRITER on another target path")
+		err = publish(mountTargetPath2, csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER, false)
+		Expect(err).Should(HaveOccurred())
+		unpublish(mountTargetPath2)
+		unpublish(mountTargetPath)
+
+		By("publishing the volume with SINGLE_NODE_MULTI_WRITER on two target paths")
+		err = publish(mountTargetPath, csi.VolumeCapability_AccessMode_SINGLE_NODE_MULTI_WRITER, false)
+		Expect(err).ShouldNot(HaveOccurred())
+		err = publish(mountTargetPath2, csi.VolumeCapability_AccessMode_SINGLE_NODE_MULTI_WRITER, true)
+		Expect(err).ShouldNot(HaveOccurred())
+		unpublish(mountTargetPath2)
+		unpublish(mountTargetPath)
+
+		By("publishing the volume with SINGLE_NODE_READER_ONLY and mount option \"rw\"")
+		_, err = nc.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        mountTargetPath,
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{
+						FsType:     "xfs",
+						MountFlags: []string{"rw"},
+					},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+				},
+			},
+			VolumeId: volumeID,
+		})
+		Expect(err).Should(HaveOccurred())
+
+		By("cleaning logicalvolume")
+		cl.unpublishVolumes(nc)
+		stdout, stderr, err := kubectl("delete", "logicalvolume", "csi-node-test-fs")
+		Expect(err).ShouldNot(HaveOccurred(), "stdout=%s, stderr=%s", stdout, stderr)
+	})
+
 	It("validate mount options", func() {
 		mountTargetPath := "/mnt/csi-node-test"
+		stagingPath := "/mnt/csi-node-test-staging"
 
 		By("creating a logical volume resource")
 		nodeName := "topolvm-e2e-worker"
@@ -385,7 +581,7 @@ func testPublishVolume() {
 			return nil
 		}).Should(Succeed())
 
-		cl.register(volumeID, mountTargetPath)
+		cl.register(volumeID, mountTargetPath, stagingPath)
 
 		By("mount option \"rw\" is specified even though read only mode is specified")
 		mountVolCap := &csi.VolumeCapability{
@@ -401,11 +597,12 @@ func testPublishVolume() {
 		}
 
 		req := &csi.NodePublishVolumeRequest{
-			PublishContext:   map[string]string{},
-			TargetPath:       mountTargetPath,
-			VolumeCapability: mountVolCap,
-			VolumeId:         volumeID,
-			Readonly:         true,
+			PublishContext:    map[string]string{},
+			StagingTargetPath: stagingPath,
+			TargetPath:        mountTargetPath,
+			VolumeCapability:  mountVolCap,
+			VolumeId:          volumeID,
+			Readonly:          true,
 		}
 		_, err = nc.NodePublishVolume(context.Background(), req)
 		Expect(err).Should(HaveOccurred())
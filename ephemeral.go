@@ -0,0 +1,55 @@
+package topolvm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// EphemeralVolumeSize returns the size in bytes of an inline ephemeral volume
+// requested by the volume attributes.
+//
+// The size is given by EphemeralVolumeSizeKey either as a Kubernetes quantity such as "500Mi"
+// or as an integer without unit, which is interpreted as GiB for backward compatibility.
+// The size is rounded up to GiB because logical volumes are allocated in GiB.
+// DefaultSize is returned if the size is not specified.
+func EphemeralVolumeSize(attrs map[string]string) (int64, error) {
+	sizeStr, ok := attrs[EphemeralVolumeSizeKey]
+	if !ok {
+		return DefaultSize, nil
+	}
+
+	var size int64
+	if gb, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
+		if gb > math.MaxInt64>>30 {
+			return 0, fmt.Errorf("too large size: %s", sizeStr)
+		}
+		size = gb << 30
+	} else {
+		q, err := resource.ParseQuantity(sizeStr)
+		if err != nil {
+			return 0, fmt.Errorf("invalid size %q: %w", sizeStr, err)
+		}
+		if q.CmpInt64(math.MaxInt64) > 0 {
+			return 0, fmt.Errorf("too large size: %s", sizeStr)
+		}
+		size = q.Value()
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("size must be positive: %s", sizeStr)
+	}
+
+	const gb = int64(1 << 30)
+	if size > math.MaxInt64-(gb-1) {
+		return 0, fmt.Errorf("too large size: %s", sizeStr)
+	}
+	return (size + gb - 1) / gb * gb, nil
+}
+
+// EphemeralVolumeDeviceClass returns the device-class of an inline ephemeral volume
+// requested by the volume attributes.  DefaultDeviceClassName is returned if it is not specified.
+func EphemeralVolumeDeviceClass(attrs map[string]string) string {
+	return attrs[DeviceClassKey]
+}
@@ -0,0 +1,39 @@
+package topolvm
+
+import "testing"
+
+func TestEphemeralVolumeSize(t *testing.T) {
+	cases := []struct {
+		attrs    map[string]string
+		expected int64
+		err      bool
+	}{
+		{nil, DefaultSize, false},
+		{map[string]string{EphemeralVolumeSizeKey: "2"}, 2 << 30, false},
+		{map[string]string{EphemeralVolumeSizeKey: "20Gi"}, 20 << 30, false},
+		{map[string]string{EphemeralVolumeSizeKey: "500Mi"}, 1 << 30, false},
+		{map[string]string{EphemeralVolumeSizeKey: "1.5Gi"}, 2 << 30, false},
+		{map[string]string{EphemeralVolumeSizeKey: "2G"}, 2 << 30, false},
+		{map[string]string{EphemeralVolumeSizeKey: "0"}, 0, true},
+		{map[string]string{EphemeralVolumeSizeKey: "-1Gi"}, 0, true},
+		{map[string]string{EphemeralVolumeSizeKey: "9223372036854775807"}, 0, true},
+		{map[string]string{EphemeralVolumeSizeKey: "abc"}, 0, true},
+	}
+
+	for _, c := range cases {
+		size, err := EphemeralVolumeSize(c.attrs)
+		if c.err {
+			if err == nil {
+				t.Errorf("%v: error expected", c.attrs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", c.attrs, err)
+			continue
+		}
+		if size != c.expected {
+			t.Errorf("%v: expected=%d, actual=%d", c.attrs, c.expected, size)
+		}
+	}
+}
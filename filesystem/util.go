@@ -2,18 +2,30 @@ package filesystem
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"unsafe"
 
 	"golang.org/x/sys/unix"
 )
 
 const (
 	blkidCmd = "/sbin/blkid"
+
+	// fitrim is the FITRIM ioctl request, _IOWR('X', 121, struct fstrim_range).
+	fitrim = 0xc0185879
 )
 
+// fstrimRange corresponds to struct fstrim_range in linux/fs.h.
+type fstrimRange struct {
+	start  uint64
+	len    uint64
+	minlen uint64
+}
+
 type temporaryer interface {
 	Temporary() bool
 }
@@ -87,6 +99,91 @@ func IsMounted(device, target string) (bool, error) {
 	return false, nil
 }
 
+// MountPoints returns the paths where device is mounted.
+func MountPoints(device string) ([]string, error) {
+	data, err := os.ReadFile("/proc/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("could not read /proc/mounts: %v", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ok, err := isSameDevice(device, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		d, err := filepath.EvalSymlinks(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, d)
+	}
+
+	return paths, nil
+}
+
+// IsReadOnlySuperblock returns true if the filesystem mounted on target is read-only
+// while the mount point itself is read-write.
+// This happens when the filesystem is remounted read-only because of errors.
+func IsReadOnlySuperblock(target string) (bool, error) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return false, err
+	}
+	target, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return false, fmt.Errorf("could not read /proc/self/mountinfo: %v", err)
+	}
+	return isReadOnlySuperblock(data, target), nil
+}
+
+func isReadOnlySuperblock(mountinfo []byte, target string) bool {
+	// The format of mountinfo is described in proc(5).
+	// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+	var result bool
+	for _, line := range strings.Split(string(mountinfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[4] != target {
+			continue
+		}
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || sep+3 >= len(fields) {
+			continue
+		}
+		// The last mount on target hides the others.
+		result = hasOption(fields[5], "rw") && hasOption(fields[sep+3], "ro")
+	}
+	return result
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectFilesystem returns filesystem type if device has a filesystem.
 // This returns an empty string if no filesystem exists.
 func DetectFilesystem(device string) (string, error) {
@@ -159,3 +256,25 @@ func Statfs(path string, buf *unix.Statfs_t) (err error) {
 		return err
 	}
 }
+
+// Fstrim discards the unused blocks of the filesystem mounted on path.
+// It returns the number of bytes trimmed as reported by the filesystem.
+func Fstrim(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := fstrimRange{len: math.MaxUint64}
+	for {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), fitrim, uintptr(unsafe.Pointer(&r)))
+		if errno == unix.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return 0, fmt.Errorf("FITRIM failed for %s: %v", path, errno)
+		}
+		return r.len, nil
+	}
+}
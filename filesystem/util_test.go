@@ -67,3 +67,32 @@ func TestDetectFilesystem(t *testing.T) {
 		t.Error("fs is not ext4", fs)
 	}
 }
+
+func TestIsReadOnlySuperblock(t *testing.T) {
+	mountinfo := []byte(`22 1 253:0 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+100 22 252:1 / /mnt/staging rw,relatime shared:50 - ext4 /dev/topolvm/vol1 ro,errors=remount-ro
+101 22 252:1 / /mnt/target1 ro,relatime shared:50 - ext4 /dev/topolvm/vol1 ro,errors=remount-ro
+102 22 252:2 / /mnt/target2 ro,relatime shared:51 - xfs /dev/topolvm/vol2 rw,attr2
+103 22 252:3 / /mnt/target3 rw,relatime master:1 shared:52 - xfs /dev/topolvm/vol3 ro,attr2
+104 22 252:4 / /mnt/target4 rw,relatime shared:53 - xfs /dev/topolvm/vol4 ro
+105 22 252:4 / /mnt/target4 rw,relatime shared:54 - xfs /dev/topolvm/vol4 rw
+`)
+
+	cases := []struct {
+		target   string
+		expected bool
+	}{
+		{"/", false},
+		{"/mnt/staging", true},
+		{"/mnt/target1", false},
+		{"/mnt/target2", false},
+		{"/mnt/target3", true},
+		{"/mnt/target4", false},
+		{"/mnt/not-mounted", false},
+	}
+	for _, c := range cases {
+		if actual := isReadOnlySuperblock(mountinfo, c.target); actual != c.expected {
+			t.Errorf("%s: expected %v, actual %v", c.target, c.expected, actual)
+		}
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"strconv"
 
 	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,19 +23,28 @@ import (
 
 var pmLogger = ctrl.Log.WithName("pod-mutator")
 
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
 //+kubebuilder:webhook:failurePolicy=fail,matchPolicy=equivalent,groups=core,resources=pods,verbs=create,versions=v1,name=pod-hook.topolvm.cybozu.com,path=/pod/mutate,mutating=true,sideEffects=none,admissionReviewVersions={v1,v1beta1}
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
 
-// podMutator mutates pods using PVC for TopoLVM.
+// podMutator mutates pods using PVC or ephemeral volumes for TopoLVM.
 type podMutator struct {
-	client  client.Client
-	decoder *admission.Decoder
+	client            client.Client
+	decoder           *admission.Decoder
+	extendedResources bool
 }
 
 // PodMutator creates a mutating webhook for Pods.
-func PodMutator(c client.Client, dec *admission.Decoder) http.Handler {
-	return &webhook.Admission{Handler: podMutator{c, dec}}
+// If extendedResources is true, the capacity of each device-class is requested
+// as an extended resource so that kube-scheduler can filter nodes by itself.
+func PodMutator(c client.Client, dec *admission.Decoder, extendedResources bool) http.Handler {
+	return &webhook.Admission{Handler: podMutator{c, dec, extendedResources}}
 }
 
 // Handle implements admission.Handler interface.
@@ -70,33 +82,31 @@ func (m podMutator) Handle(ctx context.Context, req admission.Request) admission
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	ephemeralCapacity, err := m.requestedEphemeralCapacity(pod)
+	genericEphemeralCapacities := m.requestedGenericEphemeralCapacity(pod, targets)
+	for dc, capacity := range genericEphemeralCapacities {
+		if pvcCapacities == nil {
+			pvcCapacities = make(map[string]int64)
+		}
+		pvcCapacities[dc] += capacity
+	}
+
+	ephemeralCapacities, err := m.requestedEphemeralCapacity(pod)
 	if err != nil {
 		pmLogger.Error(err, "requestedEphemeralCapacity failed")
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-
-	if ephemeralCapacity != 0 {
+	for dc, capacity := range ephemeralCapacities {
 		if pvcCapacities == nil {
 			pvcCapacities = make(map[string]int64)
 		}
-		pvcCapacities[topolvm.DefaultDeviceClassAnnotationName] += ephemeralCapacity
+		pvcCapacities[dc] += capacity
 	}
 
 	if len(pvcCapacities) == 0 {
 		return admission.Allowed("no request for TopoLVM")
 	}
 
-	ctnr := &pod.Spec.Containers[0]
-	quantity := resource.NewQuantity(1, resource.DecimalSI)
-	if ctnr.Resources.Requests == nil {
-		ctnr.Resources.Requests = corev1.ResourceList{}
-	}
-	ctnr.Resources.Requests[topolvm.CapacityResource] = *quantity
-	if ctnr.Resources.Limits == nil {
-		ctnr.Resources.Limits = corev1.ResourceList{}
-	}
-	ctnr.Resources.Limits[topolvm.CapacityResource] = *quantity
+	setCapacityRequests(&pod.Spec.Containers[0], pvcCapacities, m.extendedResources)
 
 	if pod.Annotations == nil {
 		pod.Annotations = make(map[string]string)
@@ -113,6 +123,31 @@ func (m podMutator) Handle(ctx context.Context, req admission.Request) admission
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
 }
 
+// setCapacityRequests adds the resources to route the pod to topolvm-scheduler, or with extendedResources,
+// the capacity extended resources of the requested device-classes to the container.
+// Requests and limits of extended resources must be the same.
+func setCapacityRequests(ctnr *corev1.Container, capacities map[string]int64, extendedResources bool) {
+	if ctnr.Resources.Requests == nil {
+		ctnr.Resources.Requests = corev1.ResourceList{}
+	}
+	if ctnr.Resources.Limits == nil {
+		ctnr.Resources.Limits = corev1.ResourceList{}
+	}
+
+	if !extendedResources {
+		quantity := resource.NewQuantity(1, resource.DecimalSI)
+		ctnr.Resources.Requests[topolvm.CapacityResource] = *quantity
+		ctnr.Resources.Limits[topolvm.CapacityResource] = *quantity
+		return
+	}
+	for dc, capacity := range capacities {
+		name := corev1.ResourceName(topolvm.CapacityResourcePrefix + dc)
+		quantity := resource.NewQuantity(capacity, resource.BinarySI)
+		ctnr.Resources.Requests[name] = *quantity
+		ctnr.Resources.Limits[name] = *quantity
+	}
+}
+
 func (m podMutator) targetStorageClasses(ctx context.Context) (map[string]storagev1.StorageClass, error) {
 	var scl storagev1.StorageClassList
 	if err := m.client.List(ctx, &scl); err != nil {
@@ -155,7 +190,26 @@ func (m podMutator) requestedPVCCapacity(ctx context.Context, pod *corev1.Pod, t
 				return nil, err
 			}
 			// Pods should be created even if their PVCs do not exist yet.
-			// TopoLVM does not care about such pods after they are created, though.
+			// The PVCs of StatefulSet pods are created from the templates just before the pods,
+			// but may not be found in the cache yet.  Their capacities are calculated from the
+			// templates.  TopoLVM does not care about the other pods, though.
+			tmpl, err := m.statefulSetClaimTemplate(ctx, pod, pvcName)
+			if err != nil {
+				pmLogger.Error(err, "failed to get volumeClaimTemplate",
+					"pod", pod.Name,
+					"namespace", pod.Namespace,
+					"pvc", pvcName,
+				)
+				return nil, err
+			}
+			if tmpl == nil {
+				continue
+			}
+			sc := targetStorageClass(tmpl.Spec.StorageClassName, targets)
+			if sc == nil {
+				continue
+			}
+			capacities[deviceClass(sc)] += requestedSize(&tmpl.Spec)
 			continue
 		}
 
@@ -176,43 +230,132 @@ func (m podMutator) requestedPVCCapacity(ctx context.Context, pod *corev1.Pod, t
 			return nil, nil
 		}
 
-		var requested int64 = topolvm.DefaultSize
-		if req, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
-			if req.Value() > topolvm.DefaultSize {
-				requested = ((req.Value()-1)>>30 + 1) << 30
-			}
+		capacities[deviceClass(&sc)] += requestedSize(&pvc.Spec)
+	}
+	return capacities, nil
+}
+
+// requestedGenericEphemeralCapacity returns the capacities requested by generic ephemeral volumes.
+// Their PVCs are created from the templates after the pod is created, so the capacities
+// are calculated from the templates.
+func (m podMutator) requestedGenericEphemeralCapacity(pod *corev1.Pod, targets map[string]storagev1.StorageClass) map[string]int64 {
+	capacities := make(map[string]int64)
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Ephemeral == nil || vol.Ephemeral.VolumeClaimTemplate == nil {
+			continue
 		}
-		dc, ok := sc.Parameters[topolvm.DeviceClassKey]
-		if !ok {
-			dc = topolvm.DefaultDeviceClassAnnotationName
+		spec := &vol.Ephemeral.VolumeClaimTemplate.Spec
+		sc := targetStorageClass(spec.StorageClassName, targets)
+		if sc == nil {
+			continue
 		}
 
-		capacities[dc] += requested
+		capacities[deviceClass(sc)] += requestedSize(spec)
 	}
-	return capacities, nil
+	return capacities
+}
+
+// statefulSetClaimTemplate returns the volumeClaimTemplate of the StatefulSet controlling the pod
+// from which the PVC is created, or nil if the PVC is not created by the StatefulSet.
+// The StatefulSet controller names PVCs as "<template name>-<pod name>".
+func (m podMutator) statefulSetClaimTemplate(ctx context.Context, pod *corev1.Pod, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return nil, nil
+	}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil || gv.Group != appsv1.GroupName {
+		return nil, nil
+	}
+
+	var sts appsv1.StatefulSet
+	name := types.NamespacedName{
+		Namespace: pod.Namespace,
+		Name:      owner.Name,
+	}
+	if err := m.client.Get(ctx, name, &sts); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if sts.UID != owner.UID {
+		return nil, nil
+	}
+
+	for i := range sts.Spec.VolumeClaimTemplates {
+		tmpl := &sts.Spec.VolumeClaimTemplates[i]
+		if tmpl.Name+"-"+pod.Name == pvcName {
+			return tmpl, nil
+		}
+	}
+	return nil, nil
 }
 
-func (m podMutator) requestedEphemeralCapacity(pod *corev1.Pod) (int64, error) {
-	var total int64
+// targetStorageClass returns the StorageClass for TopoLVM of the given name, or nil if there is none.
+// If name is nil, the default StorageClass is returned because it will be set to the PVC by
+// DefaultStorageClass admission plugin.
+func targetStorageClass(name *string, targets map[string]storagev1.StorageClass) *storagev1.StorageClass {
+	if name == nil {
+		return defaultStorageClass(targets)
+	}
+	if sc, ok := targets[*name]; ok {
+		return &sc
+	}
+	return nil
+}
+
+// defaultStorageClass returns the default StorageClass among targets, or nil if there is none.
+func defaultStorageClass(targets map[string]storagev1.StorageClass) *storagev1.StorageClass {
+	for _, sc := range targets {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return &sc
+		}
+	}
+	return nil
+}
+
+// requestedSize returns the size of the volume requested by the PVC spec rounded up to GiB.
+func requestedSize(spec *corev1.PersistentVolumeClaimSpec) int64 {
+	var requested int64 = topolvm.DefaultSize
+	if req, ok := spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		if req.Value() > topolvm.DefaultSize {
+			requested = ((req.Value()-1)>>30 + 1) << 30
+		}
+	}
+	return requested
+}
+
+// deviceClass returns the device-class of the StorageClass.
+func deviceClass(sc *storagev1.StorageClass) string {
+	dc, ok := sc.Parameters[topolvm.DeviceClassKey]
+	if !ok {
+		dc = topolvm.DefaultDeviceClassAnnotationName
+	}
+	return dc
+}
+
+func (m podMutator) requestedEphemeralCapacity(pod *corev1.Pod) (map[string]int64, error) {
+	capacities := make(map[string]int64)
 	for _, vol := range pod.Spec.Volumes {
 		if vol.CSI == nil {
 			// We only want to look at CSI volumes
 			continue
 		}
 		if vol.CSI.Driver == topolvm.PluginName {
-			if volSizeStr, ok := vol.CSI.VolumeAttributes[topolvm.EphemeralVolumeSizeKey]; ok {
-				volSize, err := strconv.ParseInt(volSizeStr, 10, 64)
-				if err != nil {
-					pmLogger.Error(err, "Invalid volume size",
-						topolvm.EphemeralVolumeSizeKey, volSizeStr,
-					)
-					return 0, err
-				}
-				total += volSize << 30
-			} else {
-				total += topolvm.DefaultSize
+			volSize, err := topolvm.EphemeralVolumeSize(vol.CSI.VolumeAttributes)
+			if err != nil {
+				pmLogger.Error(err, "Invalid volume size",
+					topolvm.EphemeralVolumeSizeKey, vol.CSI.VolumeAttributes[topolvm.EphemeralVolumeSizeKey],
+				)
+				return nil, err
 			}
+			dc := topolvm.EphemeralVolumeDeviceClass(vol.CSI.VolumeAttributes)
+			if dc == topolvm.DefaultDeviceClassName {
+				dc = topolvm.DefaultDeviceClassAnnotationName
+			}
+			capacities[dc] += volSize
 		}
 	}
-	return total, nil
+	return capacities, nil
 }
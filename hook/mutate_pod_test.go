@@ -1,13 +1,19 @@
 package hook
 
 import (
+	"errors"
 	"strconv"
+	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,6 +29,22 @@ func pvcSource(name string) *corev1.PersistentVolumeClaimVolumeSource {
 	}
 }
 
+func ephemeralSource(storageClassName *string, size int64) *corev1.EphemeralVolumeSource {
+	return &corev1.EphemeralVolumeSource{
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: storageClassName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						"storage": *resource.NewQuantity(size, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	}
+}
+
 func setupMutatePodResources() {
 	// Namespace and namespace resources
 	ns := &corev1.Namespace{}
@@ -174,6 +196,78 @@ var _ = Describe("pod mutation webhook", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 	})
 
+	It("should mutate StatefulSet pod before its PVC is created", func() {
+		sts := &appsv1.StatefulSet{}
+		sts.Namespace = mutatePodNamespace
+		sts.Name = "sts"
+		sts.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sts"}}
+		sts.Spec.Template.Labels = map[string]string{"app": "sts"}
+		sts.Spec.Template.Spec.Containers = testPod().Spec.Containers
+		sts.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: strPtr(topolvmProvisionerStorageClassName),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: *resource.NewQuantity(3<<30, resource.BinarySI),
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "local"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: strPtr(hostLocalStorageClassName),
+				},
+			},
+		}
+		err := k8sClient.Create(testCtx, sts)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(testCtx, sts)
+			Expect(err).ShouldNot(HaveOccurred())
+		}()
+
+		pod := testPod()
+		pod.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(sts, appsv1.SchemeGroupVersion.WithKind("StatefulSet")),
+		}
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: pvcSource("data-" + defaultPodName),
+				},
+			},
+			{
+				Name: "local",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: pvcSource("local-" + defaultPodName),
+				},
+			},
+		}
+		Eventually(func() error {
+			// The cache of the webhook may not have the StatefulSet yet.
+			if err := k8sClient.Create(testCtx, pod.DeepCopy()); err != nil {
+				return err
+			}
+			if _, ok := getPod().Annotations[topolvm.CapacityKeyPrefix+"ssd"]; !ok {
+				k8sClient.Delete(testCtx, pod, client.GracePeriodSeconds(0))
+				return errors.New("pod is not mutated")
+			}
+			return nil
+		}).Should(Succeed())
+
+		pod = getPod()
+		request := pod.Spec.Containers[0].Resources.Requests[topolvm.CapacityResource]
+		Expect(request.Value()).Should(BeNumerically("==", 1))
+		Expect(pod.Annotations).To(HaveLen(1))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+"ssd"]).Should(Equal(strconv.Itoa(3 << 30)))
+	})
+
 	It("should mutate pod w/ TopoLVM PVC", func() {
 		pod := testPod()
 		pod.Spec.Volumes = []corev1.Volume{
@@ -300,6 +394,165 @@ var _ = Describe("pod mutation webhook", func() {
 		Expect(capacity).Should(Equal(strconv.Itoa(1 << 30)))
 	})
 
+	It("should mutate pod with TopoLVM inline ephemeral volumes with device-class and quantity size", func() {
+		pod := testPod()
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "vol1",
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver: "topolvm.cybozu.com",
+						VolumeAttributes: map[string]string{
+							topolvm.EphemeralVolumeSizeKey: "500Mi",
+							topolvm.DeviceClassKey:         "ssd",
+						},
+					},
+				},
+			},
+			{
+				Name: "vol2",
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver: "topolvm.cybozu.com",
+						VolumeAttributes: map[string]string{
+							topolvm.EphemeralVolumeSizeKey: "20Gi",
+							topolvm.DeviceClassKey:         "ssd",
+						},
+					},
+				},
+			},
+			{
+				Name: "vol3",
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver: "topolvm.cybozu.com",
+						VolumeAttributes: map[string]string{
+							topolvm.EphemeralVolumeSizeKey: "3Gi",
+						},
+					},
+				},
+			},
+		}
+		err := k8sClient.Create(testCtx, pod)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = getPod()
+		request := pod.Spec.Containers[0].Resources.Requests[topolvm.CapacityResource]
+		limit := pod.Spec.Containers[0].Resources.Limits[topolvm.CapacityResource]
+		Expect(request.Value()).Should(BeNumerically("==", 1))
+		Expect(limit.Value()).Should(BeNumerically("==", 1))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+"ssd"]).Should(Equal(strconv.Itoa(21 << 30)))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+topolvm.DefaultDeviceClassAnnotationName]).Should(Equal(strconv.Itoa(3 << 30)))
+	})
+
+	It("should mutate pod with TopoLVM generic ephemeral volumes", func() {
+		pod := testPod()
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "vol1",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(strPtr(topolvmProvisionerStorageClassName), 100<<20),
+				},
+			},
+			{
+				Name: "vol2",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(strPtr(topolvmProvisionerStorageClassName), 2<<30-1),
+				},
+			},
+			{
+				Name: "vol3",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(strPtr(topolvmProvisioner2StorageClassName), 3<<30),
+				},
+			},
+			{
+				Name: "vol4",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(strPtr(hostLocalStorageClassName), 10<<30),
+				},
+			},
+			{
+				Name: "vol5",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: pvcSource("pvc1"),
+				},
+			},
+		}
+		err := k8sClient.Create(testCtx, pod)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = getPod()
+		request := pod.Spec.Containers[0].Resources.Requests[topolvm.CapacityResource]
+		limit := pod.Spec.Containers[0].Resources.Limits[topolvm.CapacityResource]
+		Expect(request.Value()).Should(BeNumerically("==", 1))
+		Expect(limit.Value()).Should(BeNumerically("==", 1))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+"ssd"]).Should(Equal(strconv.Itoa(4 << 30)))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+"hdd1"]).Should(Equal(strconv.Itoa(3 << 30)))
+	})
+
+	It("should mutate pod with generic ephemeral volume w/o storage class", func() {
+		pod := testPod()
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "vol1",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(nil, 2<<30),
+				},
+			},
+		}
+
+		By("creating a pod without the default StorageClass")
+		err := k8sClient.Create(testCtx, pod)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = getPod()
+		Expect(pod.Spec.Containers[0].Resources.Requests).To(BeEmpty())
+		Expect(pod.Spec.Containers[0].Resources.Limits).To(BeEmpty())
+		Expect(pod.Annotations).NotTo(HaveKey(topolvm.CapacityKeyPrefix))
+
+		err = k8sClient.Delete(testCtx, pod, client.GracePeriodSeconds(0))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("creating a pod with the default StorageClass of TopoLVM")
+		sc := &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "topolvm-provisioner-default",
+				Annotations: map[string]string{
+					"storageclass.kubernetes.io/is-default-class": "true",
+				},
+			},
+			Provisioner:       "topolvm.cybozu.com",
+			VolumeBindingMode: modePtr(storagev1.VolumeBindingWaitForFirstConsumer),
+			Parameters: map[string]string{
+				topolvm.DeviceClassKey: "hdd2",
+			},
+		}
+		err = k8sClient.Create(testCtx, sc)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(testCtx, sc)
+			Expect(err).ShouldNot(HaveOccurred())
+		}()
+
+		pod = testPod()
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "vol1",
+				VolumeSource: corev1.VolumeSource{
+					Ephemeral: ephemeralSource(nil, 2<<30),
+				},
+			},
+		}
+		err = k8sClient.Create(testCtx, pod)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = getPod()
+		request := pod.Spec.Containers[0].Resources.Requests[topolvm.CapacityResource]
+		Expect(request.Value()).Should(BeNumerically("==", 1))
+		Expect(pod.Annotations[topolvm.CapacityKeyPrefix+"hdd2"]).Should(Equal(strconv.Itoa(2 << 30)))
+	})
+
 	It("should keep existing resources", func() {
 		pod := testPod()
 		pod.Spec.Volumes = []corev1.Volume{
@@ -409,3 +662,43 @@ var _ = Describe("pod mutation webhook", func() {
 		Expect(pod.Annotations).NotTo(HaveKey(topolvm.CapacityKeyPrefix))
 	})
 })
+
+func TestSetCapacityRequests(t *testing.T) {
+	capacities := map[string]int64{
+		"ssd":                                    1 << 30,
+		topolvm.DefaultDeviceClassAnnotationName: 2 << 30,
+	}
+
+	ctnr := &corev1.Container{}
+	setCapacityRequests(ctnr, capacities, false)
+	expected := corev1.ResourceList{
+		topolvm.CapacityResource: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	if !equality.Semantic.DeepEqual(ctnr.Resources.Requests, expected) || !equality.Semantic.DeepEqual(ctnr.Resources.Limits, expected) {
+		t.Errorf("unexpected resources: %#v", ctnr.Resources)
+	}
+
+	ctnr = &corev1.Container{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+	}
+	setCapacityRequests(ctnr, capacities, true)
+	expected = corev1.ResourceList{
+		corev1.ResourceCPU:                     resource.MustParse("1"),
+		topolvm.CapacityResourcePrefix + "ssd": *resource.NewQuantity(1<<30, resource.BinarySI),
+		topolvm.CapacityResourcePrefix + topolvm.DefaultDeviceClassAnnotationName: *resource.NewQuantity(2<<30, resource.BinarySI),
+	}
+	if !equality.Semantic.DeepEqual(ctnr.Resources.Requests, expected) {
+		t.Errorf("unexpected requests: %#v", ctnr.Resources.Requests)
+	}
+	delete(expected, corev1.ResourceCPU)
+	if !equality.Semantic.DeepEqual(ctnr.Resources.Limits, expected) {
+		t.Errorf("unexpected limits: %#v", ctnr.Resources.Limits)
+	}
+	if _, ok := ctnr.Resources.Requests[topolvm.CapacityResource]; ok {
+		t.Errorf("%s should not be requested", topolvm.CapacityResource)
+	}
+}
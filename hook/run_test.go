@@ -32,7 +32,7 @@ func run(ctx context.Context, cfg *rest.Config, scheme *runtime.Scheme, opts *en
 
 	dec, _ := admission.NewDecoder(scheme)
 	wh := mgr.GetWebhookServer()
-	wh.Register(podMutatingWebhookPath, &webhook.Admission{Handler: podMutator{mgr.GetClient(), dec}})
+	wh.Register(podMutatingWebhookPath, &webhook.Admission{Handler: podMutator{client: mgr.GetClient(), decoder: dec}})
 	wh.Register(pvcMutatingWebhookPath, &webhook.Admission{Handler: persistentVolumeClaimMutator{mgr.GetClient(), dec}})
 
 	if err := mgr.Start(ctx); err != nil {
@@ -133,8 +133,8 @@ var _ = BeforeSuite(func() {
 	failPolicy := admissionregistrationv1.Fail
 	sideEffects := admissionregistrationv1.SideEffectClassNone
 	webhookInstallOptions := envtest.WebhookInstallOptions{
-		MutatingWebhooks: []client.Object{
-			&admissionregistrationv1.MutatingWebhookConfiguration{
+		MutatingWebhooks: []admissionregistrationv1.MutatingWebhookConfiguration{
+			{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "topolvm-hook",
 				},
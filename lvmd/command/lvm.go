@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,11 +16,17 @@ import (
 )
 
 const (
-	nsenter  = "/usr/bin/nsenter"
-	lvm      = "/sbin/lvm"
-	blockdev = "/sbin/blockdev"
-	cowMin   = 50
-	cowMax   = 300
+	nsenter    = "/usr/bin/nsenter"
+	lvm        = "/sbin/lvm"
+	blockdev   = "/sbin/blockdev"
+	blkdiscard = "/sbin/blkdiscard"
+	cryptsetup = "/sbin/cryptsetup"
+	dd         = "/bin/dd"
+	cowMin     = 50
+	cowMax     = 300
+
+	// zeroFillChunk is the amount of bytes written by one dd invocation.
+	zeroFillChunk = 1 << 30
 )
 
 var Containerized bool = false
@@ -166,6 +173,22 @@ func (g *VolumeGroup) Free() (uint64, error) {
 	return vgFree, nil
 }
 
+// IsPartial checks if one or more physical volumes of the volume group are missing.
+// See the partial bit of vg_attr in vgs(8).
+func (g *VolumeGroup) IsPartial() (bool, error) {
+	infoList, err := parseOutput("vgs", "vg_attr", g.name)
+	if err != nil {
+		return false, err
+	}
+
+	if len(infoList) != 1 {
+		return false, errors.New("volume group not found: " + g.name)
+	}
+
+	attr := infoList[0]["vg_attr"]
+	return len(attr) > 3 && attr[3] == 'p', nil
+}
+
 // CreateVolumeGroup calls "vgcreate" to create a volume group.
 // name is for creating volume name. device is path to a PV.
 func CreateVolumeGroup(name, device string) (*VolumeGroup, error) {
@@ -222,7 +245,7 @@ func (g *VolumeGroup) FindVolume(name string) (*LogicalVolume, error) {
 func (g *VolumeGroup) ListVolumes() ([]*LogicalVolume, error) {
 	infoList, err := parseOutput(
 		"lvs",
-		"lv_name,lv_path,lv_size,lv_kernel_major,lv_kernel_minor,origin,origin_size,pool_lv,thin_count,lv_tags",
+		"lv_name,lv_path,lv_size,lv_kernel_major,lv_kernel_minor,origin,origin_size,pool_lv,thin_count,lv_tags,lv_attr",
 		g.Name())
 	if err != nil {
 		return nil, err
@@ -271,6 +294,7 @@ func (g *VolumeGroup) ListVolumes() ([]*LogicalVolume, error) {
 			uint32(major),
 			uint32(minor),
 			strings.Split(info["lv_tags"], ","),
+			info["lv_attr"],
 		))
 	}
 	return ret, nil
@@ -316,7 +340,7 @@ func (g *VolumeGroup) FindPool(name string) (*ThinPool, error) {
 
 // ListPools lists all thin pool volumes in this volume group.
 func (g *VolumeGroup) ListPools() ([]*ThinPool, error) {
-	infoList, err := parseOutput("lvs", "lv_name,lv_size,thin_count", g.Name())
+	infoList, err := parseOutput("lvs", "lv_name,lv_size,thin_count,data_percent", g.Name())
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +353,15 @@ func (g *VolumeGroup) ListPools() ([]*ThinPool, error) {
 		if err != nil {
 			return nil, err
 		}
-		ret = append(ret, newThinPool(info["lv_name"], g, lvSize))
+		// data_percent is empty if the pool is not active.
+		var dataPercent float64
+		if len(info["data_percent"]) > 0 {
+			dataPercent, err = strconv.ParseFloat(info["data_percent"], 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, newThinPool(info["lv_name"], g, lvSize, dataPercent))
 	}
 	return ret, nil
 }
@@ -345,23 +377,25 @@ func (g *VolumeGroup) CreatePool(name string, size uint64) (*ThinPool, error) {
 
 // ThinPool represents a lvm thin pool.
 type ThinPool struct {
-	fullname string
-	name     string
-	vg       *VolumeGroup
-	size     uint64
+	fullname    string
+	name        string
+	vg          *VolumeGroup
+	size        uint64
+	dataPercent float64
 }
 
 func fullName(name string, vg *VolumeGroup) string {
 	return fmt.Sprintf("%v/%v", vg.Name(), name)
 }
 
-func newThinPool(name string, vg *VolumeGroup, size uint64) *ThinPool {
+func newThinPool(name string, vg *VolumeGroup, size uint64, dataPercent float64) *ThinPool {
 	fullname := fullName(name, vg)
 	return &ThinPool{
 		fullname,
 		name,
 		vg,
 		size,
+		dataPercent,
 	}
 }
 
@@ -385,6 +419,11 @@ func (t *ThinPool) Size() uint64 {
 	return t.size
 }
 
+// DataPercent returns the data usage of the thin pool in percent.
+func (t *ThinPool) DataPercent() float64 {
+	return t.dataPercent
+}
+
 // Resize the thin pool capacity.
 func (t *ThinPool) Resize(newSize uint64) error {
 	if t.size == newSize {
@@ -433,9 +472,10 @@ type LogicalVolume struct {
 	devMajor uint32
 	devMinor uint32
 	tags     []string
+	attr     string
 }
 
-func newLogicalVolume(name, path string, vg *VolumeGroup, size uint64, origin, pool *string, major, minor uint32, tags []string) *LogicalVolume {
+func newLogicalVolume(name, path string, vg *VolumeGroup, size uint64, origin, pool *string, major, minor uint32, tags []string, attr string) *LogicalVolume {
 	fullname := fullName(name, vg)
 	return &LogicalVolume{
 		fullname,
@@ -448,6 +488,7 @@ func newLogicalVolume(name, path string, vg *VolumeGroup, size uint64, origin, p
 		major,
 		minor,
 		tags,
+		attr,
 	}
 }
 
@@ -494,6 +535,14 @@ func (l *LogicalVolume) IsThin() bool {
 	return l.pool != nil
 }
 
+// PoolName returns the name of the thin pool if this is a thin volume, or an empty string if not.
+func (l *LogicalVolume) PoolName() string {
+	if l.pool == nil {
+		return ""
+	}
+	return *l.pool
+}
+
 // Pool returns thin pool if this is a thin pool, or nil if not.
 func (l *LogicalVolume) Pool() (*ThinPool, error) {
 	if l.pool == nil {
@@ -502,6 +551,18 @@ func (l *LogicalVolume) Pool() (*ThinPool, error) {
 	return l.vg.FindPool(*l.pool)
 }
 
+// IsActive checks if the volume is activated or not.
+// See the state bit of lv_attr in lvs(8).
+func (l *LogicalVolume) IsActive() bool {
+	return len(l.attr) > 4 && l.attr[4] == 'a'
+}
+
+// IsPartial checks if one or more physical volumes of the volume are missing.
+// See the volume health bit of lv_attr in lvs(8).
+func (l *LogicalVolume) IsPartial() bool {
+	return len(l.attr) > 8 && l.attr[8] == 'p'
+}
+
 // MajorNumber returns the device major number.
 func (l *LogicalVolume) MajorNumber() uint32 {
 	return l.devMajor
@@ -517,6 +578,15 @@ func (l *LogicalVolume) Tags() []string {
 	return l.tags
 }
 
+// AddTag adds a tag to the volume.
+func (l *LogicalVolume) AddTag(tag string) error {
+	if err := CallLVM("lvchange", "--addtag", tag, l.fullname); err != nil {
+		return err
+	}
+	l.tags = append(l.tags, tag)
+	return nil
+}
+
 // Snapshot takes a snapshot of this volume.
 //
 // If this is a thin-provisioning volume, snapshots can be
@@ -539,8 +609,9 @@ func (l *LogicalVolume) Snapshot(name string, cowSize uint64) (*LogicalVolume, e
 		if gbSize < cowMin {
 			gbSize = cowMin
 		}
+		// The snapshot never needs to be larger than the origin.
 		if l.size < (gbSize << 30) {
-			gbSize = (l.size >> 30) << 30
+			gbSize = (l.size + (1 << 30) - 1) >> 30
 		}
 		if err := CallLVM("lvcreate", "-s", "-n", name, "-L", fmt.Sprintf("%vg", gbSize), l.path); err != nil {
 			return nil, err
@@ -591,6 +662,83 @@ func (l *LogicalVolume) Remove() error {
 	return CallLVM("lvremove", "-f", l.path)
 }
 
+// Activate activates this volume.
+// The activation skip flag, which is set to thin snapshots by default, is ignored.
+func (l *LogicalVolume) Activate() error {
+	return CallLVM("lvchange", "-ay", "-K", l.fullname)
+}
+
+// Deactivate deactivates this volume.
+func (l *LogicalVolume) Deactivate() error {
+	return CallLVM("lvchange", "-an", l.fullname)
+}
+
+// callWithLog calls cmd with args and logs the invocation.
+func callWithLog(cmd string, args ...string) error {
+	c := wrapExecCommand(cmd, args...)
+	log.Info("invoking command", map[string]interface{}{
+		"command": cmd,
+		"args":    args,
+	})
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// Discard discards all blocks of this volume with blkdiscard.
+func (l *LogicalVolume) Discard() error {
+	return callWithLog(blkdiscard, l.path)
+}
+
+// ZeroFill overwrites this volume with zeros from offset to the end.
+// progress is called with the number of bytes written so far, including offset, after each chunk.
+// The operation stops when ctx is canceled.
+func (l *LogicalVolume) ZeroFill(ctx context.Context, offset uint64, progress func(written uint64)) error {
+	written := offset
+	for written < l.size {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n := l.size - written
+		if n > zeroFillChunk {
+			n = zeroFillChunk
+		}
+		c := wrapExecCommand(dd, "if=/dev/zero", "of="+l.path, "bs=1M",
+			fmt.Sprintf("seek=%d", written), fmt.Sprintf("count=%d", n),
+			"iflag=count_bytes", "oflag=direct,seek_bytes", "conv=notrunc", "status=none")
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return err
+		}
+		written += n
+		if progress != nil {
+			progress(written)
+		}
+	}
+	return nil
+}
+
+// IsLUKS checks if this volume is formatted with LUKS.
+func (l *LogicalVolume) IsLUKS() (bool, error) {
+	err := wrapExecCommand(cryptsetup, "isLuks", l.path).Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, err
+}
+
+// EraseLUKSKeys destroys all LUKS key slots of this volume.
+// Data encrypted with the destroyed keys can never be decrypted again.
+func (l *LogicalVolume) EraseLUKSKeys() error {
+	return callWithLog(cryptsetup, "erase", "-q", l.path)
+}
+
 // Rename this volume.
 // This method also updates properties such as Name() or Path().
 func (l *LogicalVolume) Rename(name string) error {
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// volumeReader reads the contents of a logical volume through dd.
+type volumeReader struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+}
+
+// NewReader returns a reader of the contents of this volume.
+// The volume is read with O_DIRECT to bypass the page cache.
+// The caller must call Close of the returned reader.
+func (l *LogicalVolume) NewReader() (io.ReadCloser, error) {
+	c := wrapExecCommand(dd, "if="+l.path, "bs=1M", "iflag=direct", "status=none")
+	c.Stderr = os.Stderr
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return &volumeReader{cmd: c, stdout: stdout}, nil
+}
+
+func (r *volumeReader) Read(p []byte) (int, error) {
+	return r.stdout.Read(p)
+}
+
+// Close stops dd if it is still running and waits for it to exit.
+func (r *volumeReader) Close() error {
+	r.stdout.Close()
+	return r.cmd.Wait()
+}
+
+// VolumeWriter writes data at arbitrary offsets of a logical volume through dd.
+//
+// Consecutive writes are passed to a single dd process, so sequential writes
+// of the whole volume run as fast as one dd invocation.
+type VolumeWriter struct {
+	path string
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	next uint64
+}
+
+// NewWriter returns a writer for the contents of this volume.
+// The caller must call Close of the returned writer to flush the data.
+func (l *LogicalVolume) NewWriter() *VolumeWriter {
+	return &VolumeWriter{path: l.path}
+}
+
+// WriteAt writes p at offset off of the volume.
+func (w *VolumeWriter) WriteAt(p []byte, off uint64) error {
+	if w.cmd != nil && off != w.next {
+		if err := w.finish(); err != nil {
+			return err
+		}
+	}
+	if w.cmd == nil {
+		c := wrapExecCommand(dd, "of="+w.path, "bs=1M", fmt.Sprintf("seek=%d", off),
+			"iflag=fullblock", "oflag=seek_bytes", "conv=notrunc,fdatasync", "status=none")
+		c.Stderr = os.Stderr
+		in, err := c.StdinPipe()
+		if err != nil {
+			return err
+		}
+		if err := c.Start(); err != nil {
+			return err
+		}
+		w.cmd = c
+		w.in = in
+	}
+
+	if _, err := w.in.Write(p); err != nil {
+		return err
+	}
+	w.next = off + uint64(len(p))
+	return nil
+}
+
+func (w *VolumeWriter) finish() error {
+	w.in.Close()
+	err := w.cmd.Wait()
+	w.cmd = nil
+	w.in = nil
+	return err
+}
+
+// Close flushes the written data to the volume.
+func (w *VolumeWriter) Close() error {
+	if w.cmd == nil {
+		return nil
+	}
+	return w.finish()
+}
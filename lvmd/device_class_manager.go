@@ -14,12 +14,29 @@ var ErrNotFound = errors.New("device-class not found")
 const defaultSpareGB = 10
 
 // This regexp is based on the following validation:
-//   https://github.com/kubernetes/apimachinery/blob/v0.18.3/pkg/util/validation/validation.go#L42
+//
+//	https://github.com/kubernetes/apimachinery/blob/v0.18.3/pkg/util/validation/validation.go#L42
 var qualifiedNameRegexp = regexp.MustCompile("^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$")
 
 // This regexp is used to check StripeSize format
 var stripeSizeRegexp = regexp.MustCompile("(?i)^([0-9]*)(k|m|g|t|p|e|b|s)?$")
 
+// WipePolicy is the way to wipe the data of a logical volume before it is removed.
+type WipePolicy string
+
+const (
+	// WipePolicyNone removes logical volumes without wiping.
+	WipePolicyNone = WipePolicy("none")
+	// WipePolicyBlkdiscard discards all blocks of logical volumes by blkdiscard.
+	WipePolicyBlkdiscard = WipePolicy("blkdiscard")
+	// WipePolicyZeroFill overwrites logical volumes with zeros.
+	// As it may take long, it runs asynchronously.
+	WipePolicyZeroFill = WipePolicy("zero-fill")
+	// WipePolicyCryptoErase destroys the dm-crypt (LUKS) key slots of logical volumes.
+	// Logical volumes that are not formatted with LUKS are overwritten with zeros instead.
+	WipePolicyCryptoErase = WipePolicy("crypto-erase")
+)
+
 // DeviceClass maps between device-classes and volume groups.
 type DeviceClass struct {
 	// Name for the device-class name
@@ -34,6 +51,8 @@ type DeviceClass struct {
 	Stripe *uint `json:"stripe"`
 	// StripeSize is the amount of data that is written to one device before moving to the next device
 	StripeSize string `json:"stripe-size"`
+	// WipePolicy is the way to wipe logical volumes before removing them
+	WipePolicy WipePolicy `json:"wipe-policy"`
 }
 
 // GetSpare returns spare in bytes for the device-class
@@ -44,6 +63,14 @@ func (c DeviceClass) GetSpare() uint64 {
 	return *c.SpareGB << 30
 }
 
+// GetWipePolicy returns the wipe policy for the device-class
+func (c DeviceClass) GetWipePolicy() WipePolicy {
+	if c.WipePolicy == "" {
+		return WipePolicyNone
+	}
+	return c.WipePolicy
+}
+
 // ValidateDeviceClasses validates device-classes
 func ValidateDeviceClasses(deviceClasses []*DeviceClass) error {
 	if len(deviceClasses) < 1 {
@@ -78,6 +105,11 @@ func ValidateDeviceClasses(deviceClasses []*DeviceClass) error {
 		if dc.StripeSize != "" && !stripeSizeRegexp.MatchString(dc.StripeSize) {
 			return fmt.Errorf("stripe-size format is \"Size[k|UNIT]\": %s", dc.Name)
 		}
+		switch dc.GetWipePolicy() {
+		case WipePolicyNone, WipePolicyBlkdiscard, WipePolicyZeroFill, WipePolicyCryptoErase:
+		default:
+			return fmt.Errorf("unknown wipe-policy: %s, %s", dc.Name, dc.WipePolicy)
+		}
 	}
 	if countDefault != 1 {
 		return errors.New("should have only one default device-class")
@@ -124,6 +124,33 @@ func TestValidateDeviceClasses(t *testing.T) {
 			},
 			valid: false,
 		},
+		{
+			deviceClasses: []*DeviceClass{
+				{
+					Name:        "zero-fill",
+					VolumeGroup: "node1-myvg1",
+					WipePolicy:  WipePolicyZeroFill,
+					Default:     true,
+				},
+				{
+					Name:        "crypto-erase",
+					VolumeGroup: "node1-myvg2",
+					WipePolicy:  WipePolicyCryptoErase,
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*DeviceClass{
+				{
+					Name:        "invalid-wipe-policy",
+					VolumeGroup: "node1-myvg1",
+					WipePolicy:  "shred",
+					Default:     true,
+				},
+			},
+			valid: false,
+		},
 	}
 
 	for i, c := range cases {
@@ -194,4 +221,7 @@ func TestDeviceClassManager(t *testing.T) {
 	if dc.GetSpare() != defaultSpareGB<<30 {
 		t.Error("ssd's spare should be default")
 	}
+	if dc.GetWipePolicy() != WipePolicyNone {
+		t.Error("ssd's wipe policy should be none")
+	}
 }
@@ -0,0 +1,202 @@
+package lvmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cybozu-go/log"
+	"github.com/klauspost/compress/zstd"
+	"github.com/topolvm/topolvm/lvmd/command"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportSnapshotPrefix is the prefix of the snapshots taken by ExportLV.
+const exportSnapshotPrefix = "export-"
+
+// IsExportSnapshot returns true if lv is a snapshot taken by ExportLV.
+func IsExportSnapshot(lv *command.LogicalVolume) bool {
+	return lv.IsSnapshot() && strings.HasPrefix(lv.Name(), exportSnapshotPrefix)
+}
+
+// RemoveExportSnapshots removes the snapshots left by ExportLV interrupted by the crash of lvmd.
+// This must be called before lvmd starts serving.
+func RemoveExportSnapshots(deviceClasses []*DeviceClass) error {
+	for _, dc := range deviceClasses {
+		vg, err := command.FindVolumeGroup(dc.VolumeGroup)
+		if err != nil {
+			return err
+		}
+		lvs, err := vg.ListVolumes()
+		if err != nil {
+			return err
+		}
+		for _, lv := range lvs {
+			if !IsExportSnapshot(lv) {
+				continue
+			}
+			if err := lv.Remove(); err != nil {
+				return err
+			}
+			log.Info("removed stale snapshot for export", map[string]interface{}{
+				"name":         lv.Name(),
+				"device_class": dc.Name,
+			})
+		}
+	}
+	return nil
+}
+
+// maxChunkSize is the maximum size of the decompressed data of a chunk.
+// This is the same as the maximum message size of gRPC.
+const maxChunkSize = 4 << 20
+
+var zeroBlock = make([]byte, streamBlockSize)
+
+// exportChunks reads total bytes from r and sends them as chunks of ExportLV.
+// If sparse is true, the blocks filled with zeros are not sent.  This is zero-block
+// detection rather than sparse copy; all blocks are read regardless of the thin mapping.
+// The checksum of all the bytes read from r is sent at the end.
+func exportChunks(r io.Reader, total uint64, compression proto.Compression, sparse bool, send func(*proto.LVChunk) error) error {
+	var enc *zstd.Encoder
+	switch compression {
+	case proto.Compression_NONE:
+	case proto.Compression_ZSTD:
+		var err error
+		enc, err = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		defer enc.Close()
+	default:
+		return fmt.Errorf("unknown compression: %v", compression)
+	}
+
+	if err := send(&proto.LVChunk{TotalBytes: total, Compression: compression}); err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	buf := make([]byte, streamBlockSize)
+	var encoded []byte
+	for offset := uint64(0); offset < total; {
+		n := total - offset
+		if n > streamBlockSize {
+			n = streamBlockSize
+		}
+		data := buf[:n]
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+		h.Write(data)
+		if sparse && bytes.Equal(data, zeroBlock[:n]) {
+			offset += n
+			continue
+		}
+		if enc != nil {
+			encoded = enc.EncodeAll(data, encoded[:0])
+			data = encoded
+		}
+		if err := send(&proto.LVChunk{Offset: offset, Data: data}); err != nil {
+			return err
+		}
+		offset += n
+	}
+
+	return send(&proto.LVChunk{Sha256: hex.EncodeToString(h.Sum(nil))})
+}
+
+// importChunks receives the chunks following header with recv and writes them with write
+// until it receives the checksum.
+// If zeroFill is true, the ranges not covered by the chunks are overwritten with zeros.
+// The returned error is a gRPC status error if the chunks are invalid or the checksum does not match.
+func importChunks(header *proto.LVChunk, recv func() (*proto.LVChunk, error), write func([]byte, uint64) error, zeroFill bool) error {
+	total := header.GetTotalBytes()
+
+	var dec *zstd.Decoder
+	switch header.GetCompression() {
+	case proto.Compression_NONE:
+	case proto.Compression_ZSTD:
+		var err error
+		dec, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxChunkSize))
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown compression: %v", header.GetCompression())
+	}
+
+	h := sha256.New()
+	var next uint64
+	fill := func(end uint64) error {
+		for next < end {
+			n := end - next
+			if n > streamBlockSize {
+				n = streamBlockSize
+			}
+			h.Write(zeroBlock[:n])
+			if zeroFill {
+				if err := write(zeroBlock[:n], next); err != nil {
+					return err
+				}
+			}
+			next += n
+		}
+		return nil
+	}
+
+	var decoded []byte
+	for {
+		chunk, err := recv()
+		if err == io.EOF {
+			return status.Error(codes.DataLoss, "the stream ended without checksum")
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunk.GetSha256() != "" {
+			if err := fill(total); err != nil {
+				return err
+			}
+			sum := hex.EncodeToString(h.Sum(nil))
+			if sum != chunk.GetSha256() {
+				return status.Errorf(codes.DataLoss, "checksum mismatch: expected=%s, actual=%s", chunk.GetSha256(), sum)
+			}
+			return nil
+		}
+
+		data := chunk.GetData()
+		if dec != nil {
+			decoded, err = dec.DecodeAll(data, decoded[:0])
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "failed to decompress the chunk at %d: %v", chunk.GetOffset(), err)
+			}
+			data = decoded
+		}
+		offset := chunk.GetOffset()
+		if offset < next {
+			return status.Errorf(codes.InvalidArgument, "chunks are not in ascending order: offset=%d, expected>=%d", offset, next)
+		}
+		end := offset + uint64(len(data))
+		if end > total {
+			return status.Errorf(codes.OutOfRange, "chunk exceeds the volume size: offset=%d, length=%d, size=%d",
+				offset, len(data), total)
+		}
+
+		if err := fill(offset); err != nil {
+			return err
+		}
+		if err := write(data, offset); err != nil {
+			return err
+		}
+		h.Write(data)
+		next = end
+	}
+}
@@ -0,0 +1,266 @@
+package lvmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/topolvm/topolvm/lvmd/command"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func exportForTest(t *testing.T, data []byte, compression proto.Compression, sparse bool) []*proto.LVChunk {
+	var chunks []*proto.LVChunk
+	send := func(c *proto.LVChunk) error {
+		c2 := &proto.LVChunk{
+			TotalBytes:  c.TotalBytes,
+			Compression: c.Compression,
+			Offset:      c.Offset,
+			Sha256:      c.Sha256,
+		}
+		c2.Data = append([]byte(nil), c.Data...)
+		chunks = append(chunks, c2)
+		return nil
+	}
+	err := exportChunks(bytes.NewReader(data), uint64(len(data)), compression, sparse, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chunks
+}
+
+func importForTest(chunks []*proto.LVChunk, dest []byte, zeroFill bool) error {
+	recv := func() (*proto.LVChunk, error) {
+		if len(chunks) == 0 {
+			return nil, io.EOF
+		}
+		c := chunks[0]
+		chunks = chunks[1:]
+		return c, nil
+	}
+	write := func(p []byte, off uint64) error {
+		copy(dest[off:], p)
+		return nil
+	}
+	return importChunks(chunks[0], recv, write, zeroFill)
+}
+
+func TestExportImport(t *testing.T) {
+	const total = streamBlockSize*4 + 4096
+
+	data := make([]byte, total)
+	rand.Read(data[:streamBlockSize])
+	// the second and third blocks are zeros
+	rand.Read(data[streamBlockSize*3:])
+
+	cases := []struct {
+		compression proto.Compression
+		sparse      bool
+		dataChunks  int
+	}{
+		{proto.Compression_NONE, false, 5},
+		{proto.Compression_NONE, true, 3},
+		{proto.Compression_ZSTD, false, 5},
+		{proto.Compression_ZSTD, true, 3},
+	}
+
+	for _, c := range cases {
+		chunks := exportForTest(t, data, c.compression, c.sparse)
+		if chunks[0].TotalBytes != total || chunks[0].Compression != c.compression {
+			t.Errorf("%v/%v: unexpected header: %v", c.compression, c.sparse, chunks[0])
+		}
+		if len(chunks) != c.dataChunks+2 {
+			t.Errorf("%v/%v: unexpected number of chunks: %d", c.compression, c.sparse, len(chunks))
+		}
+		if chunks[len(chunks)-1].Sha256 == "" {
+			t.Errorf("%v/%v: the last chunk should have the checksum", c.compression, c.sparse)
+		}
+
+		dest := make([]byte, total)
+		for i := range dest {
+			dest[i] = 0xff
+		}
+		err := importForTest(chunks, dest, true)
+		if err != nil {
+			t.Errorf("%v/%v: import failed: %v", c.compression, c.sparse, err)
+			continue
+		}
+		if !bytes.Equal(dest, data) {
+			t.Errorf("%v/%v: data mismatch", c.compression, c.sparse)
+		}
+	}
+}
+
+func TestImportInvalidChunks(t *testing.T) {
+	data := make([]byte, streamBlockSize*2)
+	rand.Read(data)
+
+	chunks := exportForTest(t, data, proto.Compression_NONE, false)
+	chunks[1].Data[0] ^= 0xff
+	err := importForTest(chunks, make([]byte, len(data)), true)
+	if status.Code(err) != codes.DataLoss {
+		t.Errorf("corrupted data should be detected: %v", err)
+	}
+
+	chunks = exportForTest(t, data, proto.Compression_NONE, false)
+	err = importForTest(chunks[:len(chunks)-1], make([]byte, len(data)), true)
+	if status.Code(err) != codes.DataLoss {
+		t.Errorf("missing checksum should be detected: %v", err)
+	}
+
+	chunks = exportForTest(t, data, proto.Compression_NONE, false)
+	chunks[1], chunks[2] = chunks[2], chunks[1]
+	err = importForTest(chunks, make([]byte, len(data)), true)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("chunks out of order should be rejected: %v", err)
+	}
+
+	chunks = exportForTest(t, data, proto.Compression_NONE, false)
+	chunks[2].Offset = streamBlockSize + 1
+	err = importForTest(chunks, make([]byte, len(data)), true)
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("chunks beyond the volume size should be rejected: %v", err)
+	}
+}
+
+func TestRemoveExportSnapshots(t *testing.T) {
+	uid := os.Getuid()
+	if uid != 0 {
+		t.Skip("run as root")
+	}
+
+	vgName := "test_export"
+	loop, err := MakeLoopbackDevice(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MakeLoopbackVG(vgName, loop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CleanLoopbackVG(vgName, []string{loop}, []string{vgName})
+
+	vg, err := command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lv, err := vg.CreateVolume("test1", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A volume having the prefix but not a snapshot must be kept.
+	_, err = vg.CreateVolume(exportSnapshotPrefix+"data", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The snapshot left by the crash of lvmd during ExportLV.
+	_, err = lv.Snapshot(exportSnapshotPrefix+"test1-1", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dcs := []*DeviceClass{{Name: vgName, VolumeGroup: vgName}}
+	vgService, _ := NewVGService(NewDeviceClassManager(dcs))
+	res, err := vgService.GetLVList(context.Background(), &proto.GetLVListRequest{DeviceClass: vgName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range res.GetVolumes() {
+		if v.GetName() == exportSnapshotPrefix+"test1-1" {
+			t.Error("the snapshot for export should not be listed")
+		}
+	}
+
+	err = RemoveExportSnapshots(dcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vg, err = command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.FindVolume(exportSnapshotPrefix + "test1-1"); err != command.ErrNotFound {
+		t.Errorf("the snapshot for export should be removed: %v", err)
+	}
+	for _, name := range []string{"test1", exportSnapshotPrefix + "data"} {
+		if _, err := vg.FindVolume(name); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+}
+
+type exportLVServer struct {
+	grpc.ServerStream
+	chunks []*proto.LVChunk
+}
+
+func (s *exportLVServer) Send(c *proto.LVChunk) error {
+	s.chunks = append(s.chunks, &proto.LVChunk{TotalBytes: c.TotalBytes, Offset: c.Offset, Sha256: c.Sha256})
+	return nil
+}
+
+func (s *exportLVServer) Context() context.Context {
+	return context.Background()
+}
+
+func TestExportLV(t *testing.T) {
+	uid := os.Getuid()
+	if uid != 0 {
+		t.Skip("run as root")
+	}
+
+	vgName := "test_export"
+	loop, err := MakeLoopbackDevice(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MakeLoopbackVG(vgName, loop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CleanLoopbackVG(vgName, []string{loop}, []string{vgName})
+
+	vg, err := command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A thick volume smaller than the minimum size of snapshots.
+	_, err = vg.CreateVolume("test1", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewDeviceClassManager([]*DeviceClass{{Name: vgName, VolumeGroup: vgName}})
+	lvService := NewLVService(manager, nil, nil, func() {})
+	server := &exportLVServer{}
+	err = lvService.ExportLV(&proto.ExportLVRequest{Name: "test1", DeviceClass: vgName, Sparse: true}, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.chunks) < 2 || server.chunks[0].GetTotalBytes() != 1<<30 {
+		t.Fatalf("unexpected chunks: %d", len(server.chunks))
+	}
+	if server.chunks[len(server.chunks)-1].GetSha256() == "" {
+		t.Error("the last chunk should have the checksum")
+	}
+
+	vg, err = command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lvs, err := vg.ListVolumes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lv := range lvs {
+		if IsExportSnapshot(lv) {
+			t.Errorf("the snapshot for export should be removed: %s", lv.Name())
+		}
+	}
+}
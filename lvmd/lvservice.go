@@ -2,26 +2,41 @@ package lvmd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/cybozu-go/log"
 	"github.com/topolvm/topolvm/lvmd/command"
 	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 )
 
-// NewLVService creates a new LVServiceServer
-func NewLVService(mapper *DeviceClassManager, notifyFunc func()) proto.LVServiceServer {
+// OwnerTagPrefix is the prefix of the tag to record the owner of an adopted volume.
+const OwnerTagPrefix = "topolvm/owner="
+
+// NewLVService creates a new LVServiceServer.
+// streamCreds is the credentials to connect to LVStreamService of other lvmds.
+// If it is nil, PullLV is not available.
+func NewLVService(mapper *DeviceClassManager, wiper *Wiper, streamCreds credentials.TransportCredentials, notifyFunc func()) proto.LVServiceServer {
 	return &lvService{
-		mapper:     mapper,
-		notifyFunc: notifyFunc,
+		mapper:      mapper,
+		wiper:       wiper,
+		streamCreds: streamCreds,
+		notifyFunc:  notifyFunc,
 	}
 }
 
 type lvService struct {
 	proto.UnimplementedLVServiceServer
-	mapper     *DeviceClassManager
-	notifyFunc func()
+	mapper      *DeviceClassManager
+	wiper       *Wiper
+	streamCreds credentials.TransportCredentials
+	notifyFunc  func()
 }
 
 func (s *lvService) notify() {
@@ -80,10 +95,12 @@ func (s *lvService) CreateLV(_ context.Context, req *proto.CreateLVRequest) (*pr
 
 	return &proto.CreateLVResponse{
 		Volume: &proto.LogicalVolume{
-			Name:     lv.Name(),
-			SizeGb:   lv.Size() >> 30,
-			DevMajor: lv.MajorNumber(),
-			DevMinor: lv.MinorNumber(),
+			Name:      lv.Name(),
+			SizeGb:    lv.Size() >> 30,
+			SizeBytes: lv.Size(),
+			DevMajor:  lv.MajorNumber(),
+			DevMinor:  lv.MinorNumber(),
+			Path:      lv.Path(),
 		},
 	}, nil
 }
@@ -110,7 +127,7 @@ func (s *lvService) RemoveLV(_ context.Context, req *proto.RemoveLVRequest) (*pr
 			continue
 		}
 
-		err = lv.Remove()
+		err = s.wiper.WipeAndRemove(lv, dc)
 		if err != nil {
 			log.Error("failed to remove volume", map[string]interface{}{
 				log.FnError: err,
@@ -206,3 +223,445 @@ func (s *lvService) ResizeLV(_ context.Context, req *proto.ResizeLVRequest) (*pr
 
 	return &proto.Empty{}, nil
 }
+
+func (s *lvService) AdoptLV(_ context.Context, req *proto.AdoptLVRequest) (*proto.AdoptLVResponse, error) {
+	if req.GetOwner() == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner is not provided")
+	}
+	dc, err := s.mapper.DeviceClass(req.DeviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
+	}
+	vg, err := command.FindVolumeGroup(dc.VolumeGroup)
+	if err != nil {
+		return nil, err
+	}
+	lv, err := vg.FindVolume(req.GetName())
+	if err == command.ErrNotFound || (err == nil && IsWipingVolume(lv.Name())) {
+		log.Error("logical volume is not found", map[string]interface{}{
+			"name": req.GetName(),
+		})
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
+	}
+	if err != nil {
+		log.Error("failed to find volume", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if lv.IsSnapshot() {
+		return nil, status.Errorf(codes.FailedPrecondition, "snapshot %s cannot be adopted", req.GetName())
+	}
+
+	ownerTag := OwnerTagPrefix + req.GetOwner()
+	owned := false
+	for _, tag := range lv.Tags() {
+		if tag == ownerTag {
+			owned = true
+			continue
+		}
+		if strings.HasPrefix(tag, OwnerTagPrefix) {
+			return nil, status.Errorf(codes.FailedPrecondition, "logical volume %s is already owned by %s", req.GetName(), strings.TrimPrefix(tag, OwnerTagPrefix))
+		}
+	}
+
+	if !owned {
+		if err := lv.AddTag(ownerTag); err != nil {
+			log.Error("failed to add owner tag", map[string]interface{}{
+				log.FnError: err,
+				"name":      req.GetName(),
+				"owner":     req.GetOwner(),
+			})
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		log.Info("adopted a LV", map[string]interface{}{
+			"name":  req.GetName(),
+			"owner": req.GetOwner(),
+		})
+	}
+
+	return &proto.AdoptLVResponse{
+		Volume: &proto.LogicalVolume{
+			Name:      lv.Name(),
+			SizeGb:    (lv.Size() + (1 << 30) - 1) >> 30,
+			SizeBytes: lv.Size(),
+			DevMajor:  lv.MajorNumber(),
+			DevMinor:  lv.MinorNumber(),
+			Path:      lv.Path(),
+			Tags:      lv.Tags(),
+		},
+	}, nil
+}
+
+func (s *lvService) SnapshotLV(_ context.Context, req *proto.SnapshotLVRequest) (*proto.SnapshotLVResponse, error) {
+	if req.GetSnapshotName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot name is not provided")
+	}
+	lv, err := findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := findVolume(s.mapper, req.GetDeviceClass(), req.GetSnapshotName())
+	switch status.Code(err) {
+	case codes.OK:
+		origin, err := snap.Origin()
+		if err != nil || origin.Name() != lv.Name() {
+			return nil, status.Errorf(codes.AlreadyExists, "logical volume %s already exists", req.GetSnapshotName())
+		}
+	case codes.NotFound:
+		// The snapshot of a thick volume is as large as the volume so that it does not
+		// overflow even if the whole volume is rewritten while it is copied for VolumeMigration.
+		snap, err = lv.Snapshot(req.GetSnapshotName(), lv.Size())
+		if err != nil {
+			log.Error("failed to take a snapshot", map[string]interface{}{
+				log.FnError: err,
+				"name":      req.GetName(),
+				"snapshot":  req.GetSnapshotName(),
+			})
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		s.notify()
+
+		log.Info("took a snapshot of a LV", map[string]interface{}{
+			"name":     req.GetName(),
+			"snapshot": req.GetSnapshotName(),
+		})
+	default:
+		return nil, err
+	}
+
+	// Thin snapshots are not activated by default, but they are read by LVStreamService.
+	// They are deactivated when they are removed.
+	if !snap.IsActive() {
+		if err := snap.Activate(); err != nil {
+			log.Error("failed to activate the snapshot", map[string]interface{}{
+				log.FnError: err,
+				"snapshot":  req.GetSnapshotName(),
+			})
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		snap, err = findVolume(s.mapper, req.GetDeviceClass(), req.GetSnapshotName())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &proto.SnapshotLVResponse{
+		Snapshot: &proto.LogicalVolume{
+			Name:      snap.Name(),
+			SizeGb:    snap.Size() >> 30,
+			SizeBytes: snap.Size(),
+			DevMajor:  snap.MajorNumber(),
+			DevMinor:  snap.MinorNumber(),
+			Path:      snap.Path(),
+			Tags:      snap.Tags(),
+		},
+	}, nil
+}
+
+func (s *lvService) PullLV(req *proto.PullLVRequest, server proto.LVService_PullLVServer) error {
+	if req.GetSourceAddress() == "" || req.GetSourceName() == "" {
+		return status.Error(codes.InvalidArgument, "source is not provided")
+	}
+	if s.streamCreds == nil {
+		return status.Error(codes.FailedPrecondition, "stream-tls is not configured")
+	}
+	ctx := server.Context()
+
+	lv, err := findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	if status.Code(err) == codes.NotFound {
+		_, err = s.CreateLV(ctx, &proto.CreateLVRequest{
+			Name:        req.GetName(),
+			SizeGb:      req.GetSizeGb(),
+			DeviceClass: req.GetDeviceClass(),
+		})
+		if err != nil {
+			return err
+		}
+		lv, err = findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	}
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.DialContext(ctx, req.GetSourceAddress(), grpc.WithTransportCredentials(s.streamCreds))
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to connect to %s: %v", req.GetSourceAddress(), err)
+	}
+	defer conn.Close()
+
+	stream, err := proto.NewLVStreamServiceClient(conn).ReadLV(ctx, &proto.ReadLVRequest{
+		Name:        req.GetSourceName(),
+		DeviceClass: req.GetSourceDeviceClass(),
+		BaseName:    req.GetSourceBaseName(),
+	})
+	if err != nil {
+		return err
+	}
+	header, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	total := header.GetTotalBytes()
+	if total > lv.Size() {
+		return status.Errorf(codes.FailedPrecondition, "logical volume %s is smaller than the source: size=%d, source=%d",
+			req.GetName(), lv.Size(), total)
+	}
+
+	log.Info("start pulling a LV", map[string]interface{}{
+		"name":           req.GetName(),
+		"source_address": req.GetSourceAddress(),
+		"source_name":    req.GetSourceName(),
+		"source_base":    req.GetSourceBaseName(),
+		"size":           total,
+	})
+
+	w := lv.NewWriter()
+	var lastProgress time.Time
+	progress := func(copied, written uint64) error {
+		if copied < total && time.Since(lastProgress) < pullProgressInterval {
+			return nil
+		}
+		lastProgress = time.Now()
+		return server.Send(&proto.PullLVResponse{
+			CopiedBytes:  copied,
+			TotalBytes:   total,
+			WrittenBytes: written,
+		})
+	}
+	err = receiveBlocks(stream.Recv, w.WriteAt, total, progress)
+	if err2 := w.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		log.Error("failed to pull LV", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	log.Info("pulled a LV", map[string]interface{}{
+		"name":        req.GetName(),
+		"source_name": req.GetSourceName(),
+	})
+	return nil
+}
+
+// pullProgressInterval is the minimum interval of the progress reports of PullLV.
+const pullProgressInterval = 5 * time.Second
+
+// receiveBlocks receives blocks with recv until EOF and writes them with write.
+// progress is called after each block with the bytes covered by the received blocks
+// and the bytes written so far.  It is called with total at the end.
+func receiveBlocks(recv func() (*proto.LVBlock, error), write func([]byte, uint64) error,
+	total uint64, progress func(copied, written uint64) error) error {
+	var written uint64
+	for {
+		block, err := recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		end := block.GetOffset() + uint64(len(block.GetData()))
+		if end > total {
+			return fmt.Errorf("block exceeds the volume size: offset=%d, length=%d, size=%d",
+				block.GetOffset(), len(block.GetData()), total)
+		}
+		if err := write(block.GetData(), block.GetOffset()); err != nil {
+			return err
+		}
+		written += uint64(len(block.GetData()))
+		if end == total {
+			continue
+		}
+		if err := progress(end, written); err != nil {
+			return err
+		}
+	}
+	return progress(total, written)
+}
+
+func (s *lvService) ExportLV(req *proto.ExportLVRequest, server proto.LVService_ExportLVServer) error {
+	if _, ok := proto.Compression_name[int32(req.GetCompression())]; !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown compression: %v", req.GetCompression())
+	}
+	lv, err := findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return err
+	}
+
+	snapName := fmt.Sprintf("%s%s-%d", exportSnapshotPrefix, lv.Name(), time.Now().UnixNano())
+	// The snapshot of a thick volume is as large as the volume
+	// so that it does not overflow even if the whole volume is rewritten while exporting.
+	snap, err := lv.Snapshot(snapName, lv.Size())
+	if err != nil {
+		log.Error("failed to take a snapshot", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+			"snapshot":  snapName,
+		})
+		return status.Error(codes.Internal, err.Error())
+	}
+	s.notify()
+	defer func() {
+		if err := snap.Remove(); err != nil {
+			log.Error("failed to remove the snapshot for export", map[string]interface{}{
+				log.FnError: err,
+				"snapshot":  snapName,
+			})
+			return
+		}
+		s.notify()
+	}()
+	if !snap.IsActive() {
+		if err := snap.Activate(); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	r, err := snap.NewReader()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer r.Close()
+
+	log.Info("start exporting a LV", map[string]interface{}{
+		"name":        req.GetName(),
+		"snapshot":    snapName,
+		"compression": req.GetCompression().String(),
+		"sparse":      req.GetSparse(),
+		"size":        lv.Size(),
+	})
+	err = exportChunks(r, lv.Size(), req.GetCompression(), req.GetSparse(), server.Send)
+	if err != nil {
+		log.Error("failed to export LV", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	log.Info("exported a LV", map[string]interface{}{
+		"name": req.GetName(),
+	})
+	return nil
+}
+
+func (s *lvService) ImportLV(server proto.LVService_ImportLVServer) error {
+	ctx := server.Context()
+	req, err := server.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no request is sent")
+	}
+	if err != nil {
+		return err
+	}
+	if req.GetName() == "" {
+		return status.Error(codes.InvalidArgument, "name is not provided")
+	}
+	header := req.GetChunk()
+	total := header.GetTotalBytes()
+	if total == 0 {
+		return status.Error(codes.InvalidArgument, "the first chunk does not have the size of the volume")
+	}
+	sizeGb := req.GetSizeGb()
+	if sizeGb == 0 {
+		sizeGb = (total + (1 << 30) - 1) >> 30
+	}
+	if sizeGb<<30 < total {
+		return status.Errorf(codes.InvalidArgument, "size_gb is smaller than the exported volume: size_gb=%d, exported=%d",
+			sizeGb, total)
+	}
+
+	_, err = findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	switch status.Code(err) {
+	case codes.OK:
+		return status.Errorf(codes.AlreadyExists, "logical volume %s already exists", req.GetName())
+	case codes.NotFound:
+	default:
+		return err
+	}
+	_, err = s.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        req.GetName(),
+		SizeGb:      sizeGb,
+		DeviceClass: req.GetDeviceClass(),
+	})
+	if err != nil {
+		return err
+	}
+	lv, err := findVolume(s.mapper, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return err
+	}
+
+	log.Info("start importing a LV", map[string]interface{}{
+		"name":        req.GetName(),
+		"compression": header.GetCompression().String(),
+		"size":        total,
+	})
+	recv := func() (*proto.LVChunk, error) {
+		for {
+			req, err := server.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if req.GetChunk() != nil {
+				return req.GetChunk(), nil
+			}
+		}
+	}
+	w := lv.NewWriter()
+	err = importChunks(header, recv, w.WriteAt, !lv.IsThin())
+	if err2 := w.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		log.Error("failed to import LV", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		// The volume is removed with the wipe policy as it has a part of the contents.
+		// ctx of the stream may have been canceled.
+		_, err2 := s.RemoveLV(context.Background(), &proto.RemoveLVRequest{
+			Name:        req.GetName(),
+			DeviceClass: req.GetDeviceClass(),
+		})
+		if err2 != nil {
+			log.Error("failed to remove the incomplete LV", map[string]interface{}{
+				log.FnError: err2,
+				"name":      req.GetName(),
+			})
+		}
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	log.Info("imported a LV", map[string]interface{}{
+		"name": req.GetName(),
+	})
+	return server.SendAndClose(&proto.ImportLVResponse{
+		Volume: &proto.LogicalVolume{
+			Name:      lv.Name(),
+			SizeGb:    lv.Size() >> 30,
+			SizeBytes: lv.Size(),
+			DevMajor:  lv.MajorNumber(),
+			DevMinor:  lv.MinorNumber(),
+			Path:      lv.Path(),
+			Tags:      lv.Tags(),
+		},
+	})
+}
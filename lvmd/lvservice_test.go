@@ -39,7 +39,8 @@ func TestLVService(t *testing.T) {
 	notifier := func() {
 		count++
 	}
-	lvService := NewLVService(NewDeviceClassManager([]*DeviceClass{{Name: vgName, VolumeGroup: vgName}}), notifier)
+	manager := NewDeviceClassManager([]*DeviceClass{{Name: vgName, VolumeGroup: vgName}})
+	lvService := NewLVService(manager, NewWiper(manager, notifier), nil, notifier)
 	res, err := lvService.CreateLV(context.Background(), &proto.CreateLVRequest{
 		Name:        "test1",
 		DeviceClass: vgName,
@@ -118,6 +119,64 @@ func TestLVService(t *testing.T) {
 		t.Errorf("unexpected count: %d", count)
 	}
 
+	_, err = vg.CreateVolume("existing", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptRes, err := lvService.AdoptLV(context.Background(), &proto.AdoptLVRequest{
+		Name:        "existing",
+		DeviceClass: vgName,
+		Owner:       "owner1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adoptRes.GetVolume().GetName() != "existing" {
+		t.Errorf(`res.Volume.Name != "existing": %s`, adoptRes.GetVolume().GetName())
+	}
+	if adoptRes.GetVolume().GetSizeGb() != 1 {
+		t.Errorf(`res.Volume.SizeGb != 1: %d`, adoptRes.GetVolume().GetSizeGb())
+	}
+	if adoptRes.GetVolume().GetSizeBytes() != 1<<30 {
+		t.Errorf(`res.Volume.SizeBytes != 1Gi: %d`, adoptRes.GetVolume().GetSizeBytes())
+	}
+	lv, err = vg.FindVolume("existing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsString(lv.Tags(), OwnerTagPrefix+"owner1") {
+		t.Errorf("owner tag is not present on volume: %v", lv.Tags())
+	}
+
+	_, err = lvService.AdoptLV(context.Background(), &proto.AdoptLVRequest{
+		Name:        "existing",
+		DeviceClass: vgName,
+		Owner:       "owner1",
+	})
+	if err != nil {
+		t.Error("adopting again by the same owner should succeed: ", err)
+	}
+
+	_, err = lvService.AdoptLV(context.Background(), &proto.AdoptLVRequest{
+		Name:        "existing",
+		DeviceClass: vgName,
+		Owner:       "owner2",
+	})
+	code = status.Code(err)
+	if code != codes.FailedPrecondition {
+		t.Errorf(`code is not codes.FailedPrecondition: %s`, code)
+	}
+
+	_, err = lvService.AdoptLV(context.Background(), &proto.AdoptLVRequest{
+		Name:        "not-exist",
+		DeviceClass: vgName,
+		Owner:       "owner1",
+	})
+	code = status.Code(err)
+	if code != codes.NotFound {
+		t.Errorf(`code is not codes.NotFound: %s`, code)
+	}
+
 	_, err = lvService.RemoveLV(context.Background(), &proto.RemoveLVRequest{
 		Name:        "test1",
 		DeviceClass: vgName,
@@ -134,3 +193,83 @@ func TestLVService(t *testing.T) {
 	}
 
 }
+
+func TestSnapshotLV(t *testing.T) {
+	uid := os.Getuid()
+	if uid != 0 {
+		t.Skip("run as root")
+	}
+
+	vgName := "test_lvservice"
+	loop, err := MakeLoopbackDevice(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = MakeLoopbackVG(vgName, loop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CleanLoopbackVG(vgName, []string{loop}, []string{vgName})
+
+	vg, err := command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A thick volume smaller than the minimum size of snapshots.
+	_, err = vg.CreateVolume("thick", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool("pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pool.CreateVolume("thin", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewDeviceClassManager([]*DeviceClass{{Name: vgName, VolumeGroup: vgName}})
+	lvService := NewLVService(manager, NewWiper(manager, func() {}), nil, func() {})
+	for _, name := range []string{"thick", "thin"} {
+		snapName := "migration-" + name + "-base"
+		for i := 0; i < 2; i++ {
+			// SnapshotLV is idempotent.
+			_, err := lvService.SnapshotLV(context.Background(), &proto.SnapshotLVRequest{
+				Name:         name,
+				DeviceClass:  vgName,
+				SnapshotName: snapName,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		vg, err := command.FindVolumeGroup(vgName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := vg.FindVolume(snapName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !snap.IsActive() {
+			t.Errorf("the snapshot of %s should be active to be read", name)
+		}
+
+		_, err = lvService.RemoveLV(context.Background(), &proto.RemoveLVRequest{Name: snapName, DeviceClass: vgName})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
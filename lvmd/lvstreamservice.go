@@ -0,0 +1,244 @@
+package lvmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+
+	"github.com/cybozu-go/log"
+	"github.com/topolvm/topolvm/lvmd/command"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamBlockSize is the size of blocks sent by ReadLV.
+// This must be smaller than the maximum message size of gRPC, 4 MiB.
+const streamBlockSize = 1 << 20
+
+// migrationSnapshotPattern matches the names of the snapshots taken for VolumeMigration.
+// The first submatch is the part identifying the VolumeMigration.
+// This must match the names given by topolvm-node.
+var migrationSnapshotPattern = regexp.MustCompile(`^(migration-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-(base|final)$`)
+
+// NewLVStreamService creates a new LVStreamServiceServer
+func NewLVStreamService(mapper *DeviceClassManager) proto.LVStreamServiceServer {
+	return &lvStreamService{
+		mapper: mapper,
+	}
+}
+
+// NewRemoteLVStreamService creates a new LVStreamServiceServer for other lvmds.
+// It serves only the snapshots taken for VolumeMigration.  They exist only while
+// the migration is in progress because topolvm-node removes them when it finishes.
+func NewRemoteLVStreamService(mapper *DeviceClassManager) proto.LVStreamServiceServer {
+	return &lvStreamService{
+		mapper: mapper,
+		remote: true,
+	}
+}
+
+type lvStreamService struct {
+	proto.UnimplementedLVStreamServiceServer
+	mapper *DeviceClassManager
+	remote bool
+}
+
+// findVolume finds the logical volume to be read.
+// For other lvmds, only migration snapshots are found.
+func (s *lvStreamService) findVolume(deviceClass, name string) (*command.LogicalVolume, error) {
+	if s.remote && !migrationSnapshotPattern.MatchString(name) {
+		return nil, status.Errorf(codes.PermissionDenied, "logical volume %s is not a migration snapshot", name)
+	}
+	lv, err := findVolume(s.mapper, deviceClass, name)
+	if err != nil {
+		return nil, err
+	}
+	if s.remote && !lv.IsSnapshot() {
+		return nil, status.Errorf(codes.PermissionDenied, "logical volume %s is not a migration snapshot", name)
+	}
+	return lv, nil
+}
+
+// isSameMigration returns true if both names are the snapshots of the same VolumeMigration.
+func isSameMigration(name1, name2 string) bool {
+	m1 := migrationSnapshotPattern.FindStringSubmatch(name1)
+	m2 := migrationSnapshotPattern.FindStringSubmatch(name2)
+	return m1 != nil && m2 != nil && m1[1] == m2[1]
+}
+
+// findVolume finds the logical volume of the device-class.
+// The returned error is a gRPC status error.
+func findVolume(mapper *DeviceClassManager, deviceClass, name string) (*command.LogicalVolume, error) {
+	dc, err := mapper.DeviceClass(deviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), deviceClass)
+	}
+	vg, err := command.FindVolumeGroup(dc.VolumeGroup)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	lv, err := vg.FindVolume(name)
+	if err == command.ErrNotFound || (err == nil && IsWipingVolume(lv.Name())) {
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", name)
+	}
+	if err != nil {
+		log.Error("failed to find volume", map[string]interface{}{
+			log.FnError: err,
+			"name":      name,
+		})
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return lv, nil
+}
+
+func (s *lvStreamService) ReadLV(req *proto.ReadLVRequest, server proto.LVStreamService_ReadLVServer) error {
+	lv, err := s.findVolume(req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return err
+	}
+
+	var base *command.LogicalVolume
+	if req.GetBaseName() != "" {
+		if s.remote && !isSameMigration(req.GetName(), req.GetBaseName()) {
+			return status.Errorf(codes.PermissionDenied, "base volume %s is not a snapshot of the same migration", req.GetBaseName())
+		}
+		base, err = s.findVolume(req.GetDeviceClass(), req.GetBaseName())
+		if err != nil {
+			return err
+		}
+		if base.Size() != lv.Size() {
+			return status.Errorf(codes.FailedPrecondition, "size of base volume %s differs: expected=%d, actual=%d",
+				req.GetBaseName(), lv.Size(), base.Size())
+		}
+	}
+
+	r, err := lv.NewReader()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer r.Close()
+
+	var baseReader io.Reader
+	if base != nil {
+		br, err := base.NewReader()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		defer br.Close()
+		baseReader = br
+	}
+
+	log.Info("start reading a LV", map[string]interface{}{
+		"name": req.GetName(),
+		"base": req.GetBaseName(),
+		"size": lv.Size(),
+	})
+	err = sendBlocks(r, baseReader, lv.Size(), server.Send)
+	if err != nil {
+		log.Error("failed to read LV", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// sendBlocks reads total bytes from r and sends them by blocks.
+// If base is not nil, the blocks that are the same as those of base are skipped.
+// The first message has only the total size.
+func sendBlocks(r, base io.Reader, total uint64, send func(*proto.LVBlock) error) error {
+	if err := send(&proto.LVBlock{TotalBytes: total}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamBlockSize)
+	var baseBuf []byte
+	if base != nil {
+		baseBuf = make([]byte, streamBlockSize)
+	}
+	for offset := uint64(0); offset < total; {
+		n := total - offset
+		if n > streamBlockSize {
+			n = streamBlockSize
+		}
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			return err
+		}
+		if base != nil {
+			if _, err := io.ReadFull(base, baseBuf[:n]); err != nil {
+				return err
+			}
+			if bytes.Equal(buf[:n], baseBuf[:n]) {
+				offset += n
+				continue
+			}
+		}
+		if err := send(&proto.LVBlock{Offset: offset, Data: buf[:n]}); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return nil
+}
+
+func (s *lvStreamService) ChecksumLV(ctx context.Context, req *proto.ChecksumLVRequest) (*proto.ChecksumLVResponse, error) {
+	lv, err := s.findVolume(req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	length := lv.Size()
+	if req.GetLength() != 0 {
+		if req.GetLength() > length {
+			return nil, status.Errorf(codes.OutOfRange, "length exceeds the volume size: length=%d, size=%d", req.GetLength(), length)
+		}
+		length = req.GetLength()
+	}
+
+	r, err := lv.NewReader()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer r.Close()
+
+	sum, err := checksum(ctx, io.LimitReader(r, int64(length)))
+	if err != nil {
+		log.Error("failed to calculate checksum of LV", map[string]interface{}{
+			log.FnError: err,
+			"name":      req.GetName(),
+		})
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &proto.ChecksumLVResponse{
+		Sha256:     sum,
+		TotalBytes: length,
+	}, nil
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the data read from r.
+// It stops reading when ctx is canceled.
+func checksum(ctx context.Context, r io.Reader) (string, error) {
+	h := sha256.New()
+	buf := make([]byte, streamBlockSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := r.Read(buf)
+		h.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
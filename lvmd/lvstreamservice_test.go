@@ -0,0 +1,134 @@
+package lvmd
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/topolvm/topolvm/lvmd/proto"
+)
+
+func TestStreamBlocks(t *testing.T) {
+	const total = streamBlockSize*3 + 4096
+
+	data := make([]byte, total)
+	rand.Read(data)
+	base := make([]byte, total)
+	copy(base, data)
+	// change the second block and the last partial block
+	base[streamBlockSize+10] ^= 0xff
+	base[total-1] ^= 0xff
+
+	cases := []struct {
+		name    string
+		base    []byte
+		written int
+	}{
+		{"full", nil, total},
+		{"incremental", base, streamBlockSize + 4096},
+		{"unchanged", data, 0},
+	}
+
+	for _, c := range cases {
+		var blocks []*proto.LVBlock
+		send := func(b *proto.LVBlock) error {
+			b2 := &proto.LVBlock{Offset: b.Offset, TotalBytes: b.TotalBytes}
+			b2.Data = append([]byte(nil), b.Data...)
+			blocks = append(blocks, b2)
+			return nil
+		}
+		var baseReader io.Reader
+		if c.base != nil {
+			baseReader = bytes.NewReader(c.base)
+		}
+		err := sendBlocks(bytes.NewReader(data), baseReader, total, send)
+		if err != nil {
+			t.Fatalf("%s: sendBlocks failed: %v", c.name, err)
+		}
+		if len(blocks) == 0 || blocks[0].TotalBytes != total {
+			t.Fatalf("%s: the first block should have the total size", c.name)
+		}
+
+		dest := make([]byte, total)
+		if c.base != nil {
+			copy(dest, c.base)
+		}
+		recv := func() (*proto.LVBlock, error) {
+			if len(blocks) == 0 {
+				return nil, io.EOF
+			}
+			b := blocks[0]
+			blocks = blocks[1:]
+			return b, nil
+		}
+		write := func(p []byte, off uint64) error {
+			copy(dest[off:], p)
+			return nil
+		}
+		var lastCopied, lastWritten uint64
+		progress := func(copied, written uint64) error {
+			if copied < lastCopied {
+				t.Errorf("%s: copied bytes decreased: %d -> %d", c.name, lastCopied, copied)
+			}
+			lastCopied, lastWritten = copied, written
+			return nil
+		}
+		// skip the header
+		blocks = blocks[1:]
+		err = receiveBlocks(recv, write, total, progress)
+		if err != nil {
+			t.Fatalf("%s: receiveBlocks failed: %v", c.name, err)
+		}
+		if lastCopied != total {
+			t.Errorf("%s: unexpected copied bytes: %d", c.name, lastCopied)
+		}
+		if lastWritten != uint64(c.written) {
+			t.Errorf("%s: unexpected written bytes: expected=%d, actual=%d", c.name, c.written, lastWritten)
+		}
+		if !bytes.Equal(dest, data) {
+			t.Errorf("%s: data mismatch", c.name)
+		}
+	}
+}
+
+func TestReceiveBlocksOutOfRange(t *testing.T) {
+	sent := false
+	recv := func() (*proto.LVBlock, error) {
+		if sent {
+			return nil, io.EOF
+		}
+		sent = true
+		return &proto.LVBlock{Offset: 1024, Data: make([]byte, 1024)}, nil
+	}
+	write := func([]byte, uint64) error { return nil }
+	progress := func(uint64, uint64) error { return nil }
+
+	err := receiveBlocks(recv, write, 1536, progress)
+	if err == nil {
+		t.Error("blocks beyond the volume size should be rejected")
+	}
+}
+
+func TestIsSameMigration(t *testing.T) {
+	const (
+		base  = "migration-0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10-base"
+		final = "migration-0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10-final"
+		other = "migration-6c2e9d1b-3a4f-4b8e-8d7c-1e5f0a9b2c34-base"
+	)
+	cases := []struct {
+		name1, name2 string
+		expected     bool
+	}{
+		{final, base, true},
+		{final, other, false},
+		{final, "0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10", false},
+		{"migration-x-final", "migration-x-base", false},
+		{final + "-x", base, false},
+	}
+	for _, c := range cases {
+		if actual := isSameMigration(c.name1, c.name2); actual != c.expected {
+			t.Errorf("isSameMigration(%q, %q) = %v", c.name1, c.name2, actual)
+		}
+	}
+}
@@ -24,6 +24,16 @@ type LVServiceClient interface {
 	RemoveLV(ctx context.Context, in *RemoveLVRequest, opts ...grpc.CallOption) (*Empty, error)
 	// Resize a logical volume.
 	ResizeLV(ctx context.Context, in *ResizeLVRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Adopt an existing logical volume created outside of TopoLVM.
+	AdoptLV(ctx context.Context, in *AdoptLVRequest, opts ...grpc.CallOption) (*AdoptLVResponse, error)
+	// Take a snapshot of a logical volume.
+	SnapshotLV(ctx context.Context, in *SnapshotLVRequest, opts ...grpc.CallOption) (*SnapshotLVResponse, error)
+	// Copy the contents of a logical volume of another lvmd into a local logical volume.
+	PullLV(ctx context.Context, in *PullLVRequest, opts ...grpc.CallOption) (LVService_PullLVClient, error)
+	// Export the contents of a logical volume.
+	ExportLV(ctx context.Context, in *ExportLVRequest, opts ...grpc.CallOption) (LVService_ExportLVClient, error)
+	// Create a logical volume with the contents exported by ExportLV.
+	ImportLV(ctx context.Context, opts ...grpc.CallOption) (LVService_ImportLVClient, error)
 }
 
 type lVServiceClient struct {
@@ -61,6 +71,122 @@ func (c *lVServiceClient) ResizeLV(ctx context.Context, in *ResizeLVRequest, opt
 	return out, nil
 }
 
+func (c *lVServiceClient) AdoptLV(ctx context.Context, in *AdoptLVRequest, opts ...grpc.CallOption) (*AdoptLVResponse, error) {
+	out := new(AdoptLVResponse)
+	err := c.cc.Invoke(ctx, "/proto.LVService/AdoptLV", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) SnapshotLV(ctx context.Context, in *SnapshotLVRequest, opts ...grpc.CallOption) (*SnapshotLVResponse, error) {
+	out := new(SnapshotLVResponse)
+	err := c.cc.Invoke(ctx, "/proto.LVService/SnapshotLV", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) PullLV(ctx context.Context, in *PullLVRequest, opts ...grpc.CallOption) (LVService_PullLVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LVService_ServiceDesc.Streams[0], "/proto.LVService/PullLV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lVServicePullLVClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LVService_PullLVClient interface {
+	Recv() (*PullLVResponse, error)
+	grpc.ClientStream
+}
+
+type lVServicePullLVClient struct {
+	grpc.ClientStream
+}
+
+func (x *lVServicePullLVClient) Recv() (*PullLVResponse, error) {
+	m := new(PullLVResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lVServiceClient) ExportLV(ctx context.Context, in *ExportLVRequest, opts ...grpc.CallOption) (LVService_ExportLVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LVService_ServiceDesc.Streams[1], "/proto.LVService/ExportLV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lVServiceExportLVClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LVService_ExportLVClient interface {
+	Recv() (*LVChunk, error)
+	grpc.ClientStream
+}
+
+type lVServiceExportLVClient struct {
+	grpc.ClientStream
+}
+
+func (x *lVServiceExportLVClient) Recv() (*LVChunk, error) {
+	m := new(LVChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lVServiceClient) ImportLV(ctx context.Context, opts ...grpc.CallOption) (LVService_ImportLVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LVService_ServiceDesc.Streams[2], "/proto.LVService/ImportLV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lVServiceImportLVClient{stream}
+	return x, nil
+}
+
+type LVService_ImportLVClient interface {
+	Send(*ImportLVRequest) error
+	CloseAndRecv() (*ImportLVResponse, error)
+	grpc.ClientStream
+}
+
+type lVServiceImportLVClient struct {
+	grpc.ClientStream
+}
+
+func (x *lVServiceImportLVClient) Send(m *ImportLVRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *lVServiceImportLVClient) CloseAndRecv() (*ImportLVResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportLVResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // LVServiceServer is the server API for LVService service.
 // All implementations must embed UnimplementedLVServiceServer
 // for forward compatibility
@@ -71,6 +197,16 @@ type LVServiceServer interface {
 	RemoveLV(context.Context, *RemoveLVRequest) (*Empty, error)
 	// Resize a logical volume.
 	ResizeLV(context.Context, *ResizeLVRequest) (*Empty, error)
+	// Adopt an existing logical volume created outside of TopoLVM.
+	AdoptLV(context.Context, *AdoptLVRequest) (*AdoptLVResponse, error)
+	// Take a snapshot of a logical volume.
+	SnapshotLV(context.Context, *SnapshotLVRequest) (*SnapshotLVResponse, error)
+	// Copy the contents of a logical volume of another lvmd into a local logical volume.
+	PullLV(*PullLVRequest, LVService_PullLVServer) error
+	// Export the contents of a logical volume.
+	ExportLV(*ExportLVRequest, LVService_ExportLVServer) error
+	// Create a logical volume with the contents exported by ExportLV.
+	ImportLV(LVService_ImportLVServer) error
 	mustEmbedUnimplementedLVServiceServer()
 }
 
@@ -87,6 +223,21 @@ func (UnimplementedLVServiceServer) RemoveLV(context.Context, *RemoveLVRequest)
 func (UnimplementedLVServiceServer) ResizeLV(context.Context, *ResizeLVRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResizeLV not implemented")
 }
+func (UnimplementedLVServiceServer) AdoptLV(context.Context, *AdoptLVRequest) (*AdoptLVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdoptLV not implemented")
+}
+func (UnimplementedLVServiceServer) SnapshotLV(context.Context, *SnapshotLVRequest) (*SnapshotLVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnapshotLV not implemented")
+}
+func (UnimplementedLVServiceServer) PullLV(*PullLVRequest, LVService_PullLVServer) error {
+	return status.Errorf(codes.Unimplemented, "method PullLV not implemented")
+}
+func (UnimplementedLVServiceServer) ExportLV(*ExportLVRequest, LVService_ExportLVServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportLV not implemented")
+}
+func (UnimplementedLVServiceServer) ImportLV(LVService_ImportLVServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportLV not implemented")
+}
 func (UnimplementedLVServiceServer) mustEmbedUnimplementedLVServiceServer() {}
 
 // UnsafeLVServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -154,6 +305,110 @@ func _LVService_ResizeLV_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LVService_AdoptLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdoptLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).AdoptLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVService/AdoptLV",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).AdoptLV(ctx, req.(*AdoptLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_SnapshotLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).SnapshotLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVService/SnapshotLV",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).SnapshotLV(ctx, req.(*SnapshotLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_PullLV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullLVRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LVServiceServer).PullLV(m, &lVServicePullLVServer{stream})
+}
+
+type LVService_PullLVServer interface {
+	Send(*PullLVResponse) error
+	grpc.ServerStream
+}
+
+type lVServicePullLVServer struct {
+	grpc.ServerStream
+}
+
+func (x *lVServicePullLVServer) Send(m *PullLVResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LVService_ExportLV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportLVRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LVServiceServer).ExportLV(m, &lVServiceExportLVServer{stream})
+}
+
+type LVService_ExportLVServer interface {
+	Send(*LVChunk) error
+	grpc.ServerStream
+}
+
+type lVServiceExportLVServer struct {
+	grpc.ServerStream
+}
+
+func (x *lVServiceExportLVServer) Send(m *LVChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LVService_ImportLV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LVServiceServer).ImportLV(&lVServiceImportLVServer{stream})
+}
+
+type LVService_ImportLVServer interface {
+	SendAndClose(*ImportLVResponse) error
+	Recv() (*ImportLVRequest, error)
+	grpc.ServerStream
+}
+
+type lVServiceImportLVServer struct {
+	grpc.ServerStream
+}
+
+func (x *lVServiceImportLVServer) SendAndClose(m *ImportLVResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *lVServiceImportLVServer) Recv() (*ImportLVRequest, error) {
+	m := new(ImportLVRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // LVService_ServiceDesc is the grpc.ServiceDesc for LVService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -173,8 +428,186 @@ var LVService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ResizeLV",
 			Handler:    _LVService_ResizeLV_Handler,
 		},
+		{
+			MethodName: "AdoptLV",
+			Handler:    _LVService_AdoptLV_Handler,
+		},
+		{
+			MethodName: "SnapshotLV",
+			Handler:    _LVService_SnapshotLV_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PullLV",
+			Handler:       _LVService_PullLV_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportLV",
+			Handler:       _LVService_ExportLV_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportLV",
+			Handler:       _LVService_ImportLV_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "lvmd/proto/lvmd.proto",
+}
+
+// LVStreamServiceClient is the client API for LVStreamService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LVStreamServiceClient interface {
+	// Stream the contents of a logical volume.
+	ReadLV(ctx context.Context, in *ReadLVRequest, opts ...grpc.CallOption) (LVStreamService_ReadLVClient, error)
+	// Calculate the checksum of a logical volume.
+	ChecksumLV(ctx context.Context, in *ChecksumLVRequest, opts ...grpc.CallOption) (*ChecksumLVResponse, error)
+}
+
+type lVStreamServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLVStreamServiceClient(cc grpc.ClientConnInterface) LVStreamServiceClient {
+	return &lVStreamServiceClient{cc}
+}
+
+func (c *lVStreamServiceClient) ReadLV(ctx context.Context, in *ReadLVRequest, opts ...grpc.CallOption) (LVStreamService_ReadLVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LVStreamService_ServiceDesc.Streams[0], "/proto.LVStreamService/ReadLV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lVStreamServiceReadLVClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LVStreamService_ReadLVClient interface {
+	Recv() (*LVBlock, error)
+	grpc.ClientStream
+}
+
+type lVStreamServiceReadLVClient struct {
+	grpc.ClientStream
+}
+
+func (x *lVStreamServiceReadLVClient) Recv() (*LVBlock, error) {
+	m := new(LVBlock)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lVStreamServiceClient) ChecksumLV(ctx context.Context, in *ChecksumLVRequest, opts ...grpc.CallOption) (*ChecksumLVResponse, error) {
+	out := new(ChecksumLVResponse)
+	err := c.cc.Invoke(ctx, "/proto.LVStreamService/ChecksumLV", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LVStreamServiceServer is the server API for LVStreamService service.
+// All implementations must embed UnimplementedLVStreamServiceServer
+// for forward compatibility
+type LVStreamServiceServer interface {
+	// Stream the contents of a logical volume.
+	ReadLV(*ReadLVRequest, LVStreamService_ReadLVServer) error
+	// Calculate the checksum of a logical volume.
+	ChecksumLV(context.Context, *ChecksumLVRequest) (*ChecksumLVResponse, error)
+	mustEmbedUnimplementedLVStreamServiceServer()
+}
+
+// UnimplementedLVStreamServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLVStreamServiceServer struct {
+}
+
+func (UnimplementedLVStreamServiceServer) ReadLV(*ReadLVRequest, LVStreamService_ReadLVServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadLV not implemented")
+}
+func (UnimplementedLVStreamServiceServer) ChecksumLV(context.Context, *ChecksumLVRequest) (*ChecksumLVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChecksumLV not implemented")
+}
+func (UnimplementedLVStreamServiceServer) mustEmbedUnimplementedLVStreamServiceServer() {}
+
+// UnsafeLVStreamServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LVStreamServiceServer will
+// result in compilation errors.
+type UnsafeLVStreamServiceServer interface {
+	mustEmbedUnimplementedLVStreamServiceServer()
+}
+
+func RegisterLVStreamServiceServer(s grpc.ServiceRegistrar, srv LVStreamServiceServer) {
+	s.RegisterService(&LVStreamService_ServiceDesc, srv)
+}
+
+func _LVStreamService_ReadLV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadLVRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LVStreamServiceServer).ReadLV(m, &lVStreamServiceReadLVServer{stream})
+}
+
+type LVStreamService_ReadLVServer interface {
+	Send(*LVBlock) error
+	grpc.ServerStream
+}
+
+type lVStreamServiceReadLVServer struct {
+	grpc.ServerStream
+}
+
+func (x *lVStreamServiceReadLVServer) Send(m *LVBlock) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LVStreamService_ChecksumLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChecksumLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVStreamServiceServer).ChecksumLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LVStreamService/ChecksumLV",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVStreamServiceServer).ChecksumLV(ctx, req.(*ChecksumLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LVStreamService_ServiceDesc is the grpc.ServiceDesc for LVStreamService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LVStreamService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.LVStreamService",
+	HandlerType: (*LVStreamServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ChecksumLV",
+			Handler:    _LVStreamService_ChecksumLV_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadLV",
+			Handler:       _LVStreamService_ReadLV_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "lvmd/proto/lvmd.proto",
 }
 
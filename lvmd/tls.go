@@ -0,0 +1,81 @@
+package lvmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultStreamServerName is the default name verified in the certificate of LVStreamService.
+// The certificate is shared by lvmds on all nodes, so it is not verified against the address.
+const DefaultStreamServerName = "lvmd.topolvm.cybozu.com"
+
+// StreamTLS is the mutual TLS configuration of LVStreamService served over TCP.
+// The same certificate is used to serve LVStreamService and to connect to LVStreamService of other lvmds.
+type StreamTLS struct {
+	// CertFile is the PEM file of the certificate.
+	CertFile string `json:"cert-file"`
+	// KeyFile is the PEM file of the private key of the certificate.
+	KeyFile string `json:"key-file"`
+	// CAFile is the PEM file of the CA certificate to verify peers.
+	CAFile string `json:"ca-file"`
+	// ServerName is the name verified in the certificate of the server.
+	// DefaultStreamServerName is used if this is empty.
+	ServerName string `json:"server-name"`
+}
+
+func (t *StreamTLS) load() (tls.Certificate, *x509.CertPool, error) {
+	if t.CertFile == "" || t.KeyFile == "" || t.CAFile == "" {
+		return tls.Certificate{}, nil, errors.New("cert-file, key-file, and ca-file are required")
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	ca, err := os.ReadFile(t.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fmt.Errorf("no CA certificate in %s", t.CAFile)
+	}
+	return cert, pool, nil
+}
+
+// ServerCredentials returns the credentials to serve LVStreamService.
+// Clients must present a certificate signed by the CA.
+func (t *StreamTLS) ServerCredentials() (credentials.TransportCredentials, error) {
+	cert, pool, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// ClientCredentials returns the credentials to connect to LVStreamService of other lvmds.
+func (t *StreamTLS) ClientCredentials() (credentials.TransportCredentials, error) {
+	cert, pool, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	serverName := t.ServerName
+	if serverName == "" {
+		serverName = DefaultStreamServerName
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
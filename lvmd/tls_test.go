@@ -0,0 +1,158 @@
+package lvmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testStreamTLS creates a CA and a certificate signed by it, and returns StreamTLS for them.
+func testStreamTLS(t *testing.T) *StreamTLS {
+	dir := t.TempDir()
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: DefaultStreamServerName},
+		DNSNames:     []string{DefaultStreamServerName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &StreamTLS{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	writePEM(t, st.CertFile, "CERTIFICATE", der)
+	writePEM(t, st.KeyFile, "EC PRIVATE KEY", keyDER)
+	writePEM(t, st.CAFile, "CERTIFICATE", caDER)
+	return st
+}
+
+func TestRemoteLVStreamService(t *testing.T) {
+	st := testStreamTLS(t)
+	serverCreds, err := st.ServerCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCreds, err := st.ClientCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.Creds(serverCreds))
+	proto.RegisterLVStreamServiceServer(server, NewRemoteLVStreamService(NewDeviceClassManager(nil)))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	checksum := func(creds credentials.TransportCredentials, name string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = proto.NewLVStreamServiceClient(conn).ChecksumLV(ctx, &proto.ChecksumLVRequest{Name: name, DeviceClass: "ssd"})
+		return err
+	}
+
+	err = checksum(clientCreds, "0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("volumes other than migration snapshots should be denied: %v", err)
+	}
+	err = checksum(clientCreds, "migration-0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10-final")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("migration snapshots should be looked up: %v", err)
+	}
+
+	// clients without a certificate are rejected
+	pool := x509.NewCertPool()
+	ca, err := os.ReadFile(st.CAFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.AppendCertsFromPEM(ca)
+	noCert := credentials.NewTLS(&tls.Config{RootCAs: pool, ServerName: DefaultStreamServerName})
+	err = checksum(noCert, "migration-0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10-final")
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("clients without a certificate should be rejected: %v", err)
+	}
+
+	// servers with a certificate for another name are rejected
+	st2 := *st
+	st2.ServerName = "other.example.com"
+	otherName, err := st2.ClientCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checksum(otherName, "migration-0b3ac1e4-5e1f-4f0e-9c1a-7d2b8e6f4a10-final")
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("servers with a certificate for another name should be rejected: %v", err)
+	}
+}
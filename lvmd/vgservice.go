@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/cybozu-go/log"
+	"github.com/topolvm/topolvm"
 	"github.com/topolvm/topolvm/lvmd/command"
 	"github.com/topolvm/topolvm/lvmd/proto"
 	"google.golang.org/grpc/codes"
@@ -47,15 +48,40 @@ func (s *vgService) GetLVList(_ context.Context, req *proto.GetLVListRequest) (*
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	vols := make([]*proto.LogicalVolume, len(lvs))
-	for i, lv := range lvs {
-		vols[i] = &proto.LogicalVolume{
-			Name:     lv.Name(),
-			SizeGb:   (lv.Size() + (1 << 30) - 1) >> 30,
-			DevMajor: lv.MajorNumber(),
-			DevMinor: lv.MinorNumber(),
-			Tags:     lv.Tags(),
+	pools, err := vg.ListPools()
+	if err != nil {
+		log.Error("failed to list thin pools", map[string]interface{}{
+			log.FnError: err,
+		})
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	poolDataPercent := make(map[string]float64)
+	for _, pool := range pools {
+		poolDataPercent[pool.Name()] = pool.DataPercent()
+	}
+
+	vols := make([]*proto.LogicalVolume, 0, len(lvs))
+	for _, lv := range lvs {
+		// LVs being wiped are already removed from the client's point of view,
+		// and the snapshots for export are temporary.
+		if IsWipingVolume(lv.Name()) || IsExportSnapshot(lv) {
+			continue
+		}
+		vol := &proto.LogicalVolume{
+			Name:      lv.Name(),
+			SizeGb:    (lv.Size() + (1 << 30) - 1) >> 30,
+			SizeBytes: lv.Size(),
+			DevMajor:  lv.MajorNumber(),
+			DevMinor:  lv.MinorNumber(),
+			Path:      lv.Path(),
+			Tags:      lv.Tags(),
+			Inactive:  !lv.IsActive(),
+			Partial:   lv.IsPartial(),
 		}
+		if pool := lv.PoolName(); pool != "" {
+			vol.PoolDataPercent = poolDataPercent[pool]
+		}
+		vols = append(vols, vol)
 	}
 	return &proto.GetLVListResponse{Volumes: vols}, nil
 }
@@ -94,7 +120,7 @@ func (s *vgService) send(server proto.VGService_WatchServer) error {
 	if err != nil {
 		return err
 	}
-	res := &proto.WatchResponse{}
+	res := &proto.WatchResponse{Version: topolvm.Version}
 	for _, vg := range vgs {
 		vgFree, err := vg.Free()
 		if err != nil {
@@ -111,13 +137,49 @@ func (s *vgService) send(server proto.VGService_WatchServer) error {
 		if err != nil {
 			return err
 		}
+		lvs, err := vg.ListVolumes()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		var wiping, wipeFailed uint64
+		for _, lv := range lvs {
+			if IsWipingVolume(lv.Name()) {
+				wiping += lv.Size()
+			}
+			if IsWipeFailedVolume(lv) {
+				wipeFailed += lv.Size()
+			}
+		}
+		partial, err := vg.IsPartial()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		pools, err := vg.ListPools()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		thinPools := make([]*proto.ThinPoolItem, 0, len(pools))
+		for _, pool := range pools {
+			thinPools = append(thinPools, &proto.ThinPoolItem{
+				Name:        pool.Name(),
+				SizeBytes:   pool.Size(),
+				DataPercent: pool.DataPercent(),
+			})
+		}
 		if dc.Default {
 			res.FreeBytes = vgFree
+			res.SizeBytes = vgSize
 		}
 		res.Items = append(res.Items, &proto.WatchItem{
-			DeviceClass: dc.Name,
-			FreeBytes:   vgFree,
-			SizeBytes:   vgSize,
+			DeviceClass:     dc.Name,
+			FreeBytes:       vgFree,
+			SizeBytes:       vgSize,
+			WipingBytes:     wiping,
+			WipeFailedBytes: wipeFailed,
+			SpareBytes:      dc.GetSpare(),
+			Partial:         partial,
+			ThinPools:       thinPools,
+			Default:         dc.Default,
 		})
 	}
 	return server.Send(res)
@@ -0,0 +1,261 @@
+package lvmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cybozu-go/log"
+	"github.com/topolvm/topolvm/lvmd/command"
+)
+
+// wipingLVPrefix is the name prefix of logical volumes being wiped asynchronously.
+// Such volumes are hidden from GetLVList, but their space is still consumed
+// in the volume group until they are removed.
+const wipingLVPrefix = "wiping-"
+
+// wipeFailedTag is the tag of logical volumes whose wiping has failed.
+// They are wiped again until it succeeds.
+const wipeFailedTag = "topolvm/wipe-failed"
+
+const (
+	wipeRetryInterval    = 10 * time.Second
+	maxWipeRetryInterval = 10 * time.Minute
+)
+
+// IsWipingVolume returns true if the logical volume is being wiped.
+func IsWipingVolume(name string) bool {
+	return strings.HasPrefix(name, wipingLVPrefix)
+}
+
+// IsWipeFailedVolume returns true if the wiping of the logical volume has failed.
+func IsWipeFailedVolume(lv *command.LogicalVolume) bool {
+	if !IsWipingVolume(lv.Name()) {
+		return false
+	}
+	for _, tag := range lv.Tags() {
+		if tag == wipeFailedTag {
+			return true
+		}
+	}
+	return false
+}
+
+// Wiper wipes the data of logical volumes before they are removed.
+type Wiper struct {
+	dcManager  *DeviceClassManager
+	notifyFunc func()
+
+	mu      sync.Mutex
+	ctx     context.Context
+	running map[string]bool
+}
+
+// NewWiper creates a new Wiper.
+func NewWiper(manager *DeviceClassManager, notifyFunc func()) *Wiper {
+	return &Wiper{
+		dcManager:  manager,
+		notifyFunc: notifyFunc,
+		ctx:        context.Background(),
+		running:    make(map[string]bool),
+	}
+}
+
+func (w *Wiper) notify() {
+	if w.notifyFunc == nil {
+		return
+	}
+	w.notifyFunc()
+}
+
+// Run resumes wiping of the logical volumes left by the previous run, then waits
+// for ctx to be canceled.  Wiping in progress is interrupted when ctx is canceled
+// and will be restarted from the beginning by the next Run.
+func (w *Wiper) Run(ctx context.Context, deviceClasses []*DeviceClass) error {
+	w.mu.Lock()
+	w.ctx = ctx
+	w.mu.Unlock()
+
+	for _, dc := range deviceClasses {
+		vg, err := command.FindVolumeGroup(dc.VolumeGroup)
+		if err != nil {
+			return err
+		}
+		lvs, err := vg.ListVolumes()
+		if err != nil {
+			return err
+		}
+		for _, lv := range lvs {
+			if !IsWipingVolume(lv.Name()) {
+				continue
+			}
+			log.Info("resume wiping LV", map[string]interface{}{
+				"name":         lv.Name(),
+				"device_class": dc.Name,
+			})
+			w.start(lv, dc.GetWipePolicy())
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// WipeAndRemove wipes lv according to the wipe policy of dc, then removes it.
+// Fast policies are done synchronously.  For slow policies, lv is renamed
+// so that it is hidden from GetLVList, and then wiped and removed in the background.
+func (w *Wiper) WipeAndRemove(lv *command.LogicalVolume, dc *DeviceClass) error {
+	// Snapshots are removed without wiping because writing to a snapshot
+	// only consumes its copy-on-write space.
+	if lv.IsSnapshot() {
+		if lv.IsActive() {
+			if err := lv.Deactivate(); err != nil {
+				return err
+			}
+		}
+		return lv.Remove()
+	}
+
+	policy := dc.GetWipePolicy()
+	// Zero-filling a thin volume allocates all of its blocks in the pool.
+	// Discarding returns the blocks to the pool, and unallocated blocks are not
+	// visible to new thin volumes.
+	if lv.IsThin() && policy != WipePolicyNone {
+		if err := lv.Discard(); err != nil {
+			return err
+		}
+		return lv.Remove()
+	}
+
+	switch policy {
+	case WipePolicyNone:
+	case WipePolicyBlkdiscard:
+		if err := lv.Discard(); err != nil {
+			return err
+		}
+	case WipePolicyCryptoErase:
+		isLUKS, err := lv.IsLUKS()
+		if err != nil {
+			return err
+		}
+		if !isLUKS {
+			log.Warn("LV is not formatted with LUKS; falling back to zero-fill", map[string]interface{}{
+				"name": lv.Name(),
+			})
+			return w.wipeAsync(lv, policy)
+		}
+		if err := lv.EraseLUKSKeys(); err != nil {
+			return err
+		}
+	case WipePolicyZeroFill:
+		return w.wipeAsync(lv, policy)
+	}
+	return lv.Remove()
+}
+
+func (w *Wiper) wipeAsync(lv *command.LogicalVolume, policy WipePolicy) error {
+	if err := lv.Rename(wipingLVPrefix + lv.Name()); err != nil {
+		return err
+	}
+	w.start(lv, policy)
+	return nil
+}
+
+func (w *Wiper) start(lv *command.LogicalVolume, policy WipePolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running[lv.FullName()] {
+		return
+	}
+	w.running[lv.FullName()] = true
+	go w.wipe(w.ctx, lv, policy)
+}
+
+// wipe wipes and removes lv.  On failure, it is retried with exponential backoff
+// until ctx is canceled.  Zero-fill is resumed from where the last attempt has written.
+func (w *Wiper) wipe(ctx context.Context, lv *command.LogicalVolume, policy WipePolicy) {
+	defer func() {
+		w.mu.Lock()
+		delete(w.running, lv.FullName())
+		w.mu.Unlock()
+	}()
+
+	size := lv.Size()
+	log.Info("start wiping LV", map[string]interface{}{
+		"name":   lv.Name(),
+		"size":   size,
+		"policy": policy,
+	})
+	var written, reported uint64
+	progress := func(n uint64) {
+		written = n
+		// report progress at every 10 percent
+		percent := written * 100 / size
+		if percent/10 == reported/10 && written != size {
+			return
+		}
+		reported = percent
+		log.Info("wiping LV", map[string]interface{}{
+			"name":     lv.Name(),
+			"written":  written,
+			"size":     size,
+			"progress": percent,
+		})
+	}
+
+	interval := wipeRetryInterval
+	for {
+		err := func() error {
+			// Volumes left by an older lvmd may be thin.
+			if lv.IsThin() {
+				if err := lv.Discard(); err != nil {
+					return err
+				}
+			} else if written < size {
+				if err := lv.ZeroFill(ctx, written, progress); err != nil {
+					return err
+				}
+			}
+			return lv.Remove()
+		}()
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Error("failed to wipe LV; retrying", map[string]interface{}{
+			log.FnError: err,
+			"name":      lv.Name(),
+			"written":   written,
+			"retry_in":  interval.String(),
+		})
+		if !IsWipeFailedVolume(lv) {
+			if err := lv.AddTag(wipeFailedTag); err != nil {
+				log.Error("failed to add tag to LV", map[string]interface{}{
+					log.FnError: err,
+					"name":      lv.Name(),
+				})
+			}
+			w.notify()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > maxWipeRetryInterval {
+			interval = maxWipeRetryInterval
+		}
+	}
+	w.notify()
+
+	log.Info("wiped and removed LV", map[string]interface{}{
+		"name": lv.Name(),
+		"size": size,
+	})
+}
@@ -0,0 +1,120 @@
+package lvmd
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topolvm/lvmd/command"
+	"github.com/topolvm/topolvm/lvmd/proto"
+)
+
+func TestWiper(t *testing.T) {
+	uid := os.Getuid()
+	if uid != 0 {
+		t.Skip("run as root")
+	}
+
+	vgName := "test_wiper"
+	loop, err := MakeLoopbackDevice(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = MakeLoopbackVG(vgName, loop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CleanLoopbackVG(vgName, []string{loop}, []string{vgName})
+
+	vg, err := command.FindVolumeGroup(vgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc := &DeviceClass{Name: vgName, VolumeGroup: vgName, WipePolicy: WipePolicyZeroFill}
+	manager := NewDeviceClassManager([]*DeviceClass{dc})
+	var count int
+	notifier := func() {
+		count++
+	}
+	wiper := NewWiper(manager, notifier)
+	vgService, _ := NewVGService(manager)
+
+	lv, err := vg.CreateVolume("test1", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wiper.WipeAndRemove(lv, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The volume is renamed and hidden while being wiped.
+	if _, err := vg.FindVolume("test1"); err != command.ErrNotFound {
+		t.Errorf("test1 should be renamed: %v", err)
+	}
+	if _, err := vg.FindVolume(wipingLVPrefix + "test1"); err != nil {
+		t.Errorf("wiping-test1 is not found: %v", err)
+	}
+	res, err := vgService.GetLVList(context.Background(), &proto.GetLVListRequest{DeviceClass: vgName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetVolumes()) != 0 {
+		t.Errorf("wiping volumes should be hidden: %v", res.GetVolumes())
+	}
+
+	// The volume is removed after it is wiped.
+	deadline := time.Now().Add(time.Minute)
+	for {
+		_, err := vg.FindVolume(wipingLVPrefix + "test1")
+		if err == command.ErrNotFound {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("wiping-test1 is not removed")
+		}
+		time.Sleep(time.Second)
+	}
+	if count == 0 {
+		t.Error("is not notified")
+	}
+
+	// Thin volumes are discarded and removed synchronously.
+	pool, err := vg.CreatePool("pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thin, err := pool.CreateVolume("thin1", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wiper.WipeAndRemove(thin, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.FindVolume("thin1"); err != command.ErrNotFound {
+		t.Errorf("thin1 should be removed: %v", err)
+	}
+	if _, err := vg.FindVolume(wipingLVPrefix + "thin1"); err != command.ErrNotFound {
+		t.Errorf("thin1 should not be wiped asynchronously: %v", err)
+	}
+
+	// With the none policy, the volume is removed synchronously.
+	lv, err = vg.CreateVolume("test2", 1<<30, nil, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wiper.WipeAndRemove(lv, &DeviceClass{Name: vgName, VolumeGroup: vgName, WipePolicy: WipePolicyNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.FindVolume("test2"); err != command.ErrNotFound {
+		t.Errorf("test2 should be removed: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"github.com/topolvm/topolvm/lvmd/command"
 	"github.com/topolvm/topolvm/lvmd/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"sigs.k8s.io/yaml"
 )
 
@@ -27,6 +29,12 @@ type Config struct {
 	SocketName string `json:"socket-name"`
 	// DeviceClasses is
 	DeviceClasses []*lvmd.DeviceClass `json:"device-classes"`
+	// StreamListen is TCP address to serve LVStreamService for other lvmds.
+	// The service is not served over TCP if this is empty.
+	StreamListen string `json:"stream-listen"`
+	// StreamTLS is the mutual TLS configuration of LVStreamService over TCP.
+	// This is required if StreamListen is set.  It is also used by PullLV.
+	StreamTLS *lvmd.StreamTLS `json:"stream-tls"`
 }
 
 var config = &Config{
@@ -70,6 +78,8 @@ func subMain() error {
 	log.Info("configuration file loaded: ", map[string]interface{}{
 		"device_classes": config.DeviceClasses,
 		"socket_name":    config.SocketName,
+		"stream_listen":  config.StreamListen,
+		"stream_tls":     config.StreamTLS,
 		"file_name":      cfgFilePath,
 	})
 	err = lvmd.ValidateDeviceClasses(config.DeviceClasses)
@@ -86,12 +96,32 @@ func subMain() error {
 		}
 	}
 
+	err = lvmd.RemoveExportSnapshots(config.DeviceClasses)
+	if err != nil {
+		return err
+	}
+
 	// UNIX domain socket file should be removed before listening.
 	err = os.Remove(config.SocketName)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
 
+	if config.StreamListen != "" && config.StreamTLS == nil {
+		return errors.New("stream-tls is required to serve stream-listen")
+	}
+	var serverCreds, clientCreds credentials.TransportCredentials
+	if config.StreamTLS != nil {
+		serverCreds, err = config.StreamTLS.ServerCredentials()
+		if err != nil {
+			return fmt.Errorf("invalid stream-tls: %w", err)
+		}
+		clientCreds, err = config.StreamTLS.ClientCredentials()
+		if err != nil {
+			return fmt.Errorf("invalid stream-tls: %w", err)
+		}
+	}
+
 	lis, err := net.Listen("unix", config.SocketName)
 	if err != nil {
 		return err
@@ -99,8 +129,10 @@ func subMain() error {
 	grpcServer := grpc.NewServer()
 	manager := lvmd.NewDeviceClassManager(config.DeviceClasses)
 	vgService, notifier := lvmd.NewVGService(manager)
+	wiper := lvmd.NewWiper(manager, notifier)
 	proto.RegisterVGServiceServer(grpcServer, vgService)
-	proto.RegisterLVServiceServer(grpcServer, lvmd.NewLVService(manager, notifier))
+	proto.RegisterLVServiceServer(grpcServer, lvmd.NewLVService(manager, wiper, clientCreds, notifier))
+	proto.RegisterLVStreamServiceServer(grpcServer, lvmd.NewLVStreamService(manager))
 	well.Go(func(ctx context.Context) error {
 		return grpcServer.Serve(lis)
 	})
@@ -109,6 +141,26 @@ func subMain() error {
 		grpcServer.GracefulStop()
 		return nil
 	})
+	if config.StreamListen != "" {
+		streamLis, err := net.Listen("tcp", config.StreamListen)
+		if err != nil {
+			return err
+		}
+		// Only LVStreamService is served over TCP, and only migration snapshots can be read.
+		streamServer := grpc.NewServer(grpc.Creds(serverCreds))
+		proto.RegisterLVStreamServiceServer(streamServer, lvmd.NewRemoteLVStreamService(manager))
+		well.Go(func(ctx context.Context) error {
+			return streamServer.Serve(streamLis)
+		})
+		well.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			streamServer.GracefulStop()
+			return nil
+		})
+	}
+	well.Go(func(ctx context.Context) error {
+		return wiper.Run(ctx, config.DeviceClasses)
+	})
 	well.Go(func(ctx context.Context) error {
 		ticker := time.NewTicker(10 * time.Minute)
 		for {
@@ -18,6 +18,12 @@ var config struct {
 	certDir          string
 	leaderElectionID string
 	zapOpts          zap.Options
+
+	capacityResources   bool
+	capacityTopolvmNode bool
+
+	conversionWebhookService string
+	conversionCABundle       string
 }
 
 var rootCmd = &cobra.Command{
@@ -49,6 +55,10 @@ func init() {
 	fs.StringVar(&config.webhookAddr, "webhook-addr", ":9443", "Listen address for the webhook endpoint")
 	fs.StringVar(&config.certDir, "cert-dir", "", "certificate directory")
 	fs.StringVar(&config.leaderElectionID, "leader-election-id", "topolvm", "ID for leader election by controller-runtime")
+	fs.BoolVar(&config.capacityResources, "capacity-resources", false, "Request the capacity of device-classes as extended resources of Pods")
+	fs.BoolVar(&config.capacityTopolvmNode, "capacity-topolvm-node", false, "Read the capacity of nodes from TopolvmNode instead of the annotations of Node")
+	fs.StringVar(&config.conversionWebhookService, "conversion-webhook-service", "", "Configure the conversion webhook of LogicalVolume CRD to call this service given as <namespace>/<name>")
+	fs.StringVar(&config.conversionCABundle, "conversion-ca-bundle", "", "Base64-encoded CA certificate of the conversion webhook.  If not given, ca.crt in the cert-dir is used")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
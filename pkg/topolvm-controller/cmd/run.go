@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/topolvm/topolvm"
 	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	topolvmv2 "github.com/topolvm/topolvm/api/v2"
 	"github.com/topolvm/topolvm/controllers"
 	"github.com/topolvm/topolvm/csi"
 	"github.com/topolvm/topolvm/driver"
@@ -16,10 +22,12 @@ import (
 	"github.com/topolvm/topolvm/runners"
 	"google.golang.org/grpc"
 	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -34,11 +42,16 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
 	utilruntime.Must(topolvmv1.AddToScheme(scheme))
+	utilruntime.Must(topolvmv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update;patch
 
 // Run builds and starts the manager with leader election.
 func subMain() error {
@@ -74,9 +87,15 @@ func subMain() error {
 	// admissoin.NewDecoder never returns non-nil error
 	dec, _ := admission.NewDecoder(scheme)
 	wh := mgr.GetWebhookServer()
-	wh.Register("/pod/mutate", hook.PodMutator(mgr.GetClient(), dec))
+	wh.Register("/pod/mutate", hook.PodMutator(mgr.GetClient(), dec, config.capacityResources))
 	wh.Register("/pvc/mutate", hook.PVCMutator(mgr.GetClient(), dec))
 
+	// register the conversion webhook of LogicalVolume at "/convert"
+	if err := ctrl.NewWebhookManagedBy(mgr).For(&topolvmv2.LogicalVolume{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "LogicalVolume")
+		return err
+	}
+
 	// register controllers
 	nodecontroller := &controllers.NodeReconciler{
 		Client: mgr.GetClient(),
@@ -86,6 +105,22 @@ func subMain() error {
 		return err
 	}
 
+	pvcontroller := &controllers.PersistentVolumeReconciler{
+		Client: mgr.GetClient(),
+	}
+	if err := pvcontroller.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersistentVolume")
+		return err
+	}
+
+	switchcontroller := &controllers.VolumeMigrationSwitchReconciler{
+		Client: mgr.GetClient(),
+	}
+	if err := switchcontroller.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeMigration")
+		return err
+	}
+
 	pvccontroller := &controllers.PersistentVolumeClaimReconciler{
 		Client:    mgr.GetClient(),
 		APIReader: mgr.GetAPIReader(),
@@ -97,6 +132,15 @@ func subMain() error {
 
 	//+kubebuilder:scaffold:builder
 
+	conversion, err := logicalVolumeConversion()
+	if err != nil {
+		return err
+	}
+	crdUpdater := runners.NewCRDUpdater(mgr.GetClient(), "logicalvolumes.topolvm.cybozu.com", &topolvmv1.LogicalVolumeList{}, conversion)
+	if err := mgr.Add(crdUpdater); err != nil {
+		return err
+	}
+
 	// Add health checker to manager
 	ctx := context.Background()
 	check := func() error {
@@ -116,7 +160,7 @@ func subMain() error {
 	if err != nil {
 		return err
 	}
-	n := k8s.NewNodeService(mgr)
+	n := k8s.NewNodeService(mgr, config.capacityTopolvmNode)
 
 	grpcServer := grpc.NewServer()
 	csi.RegisterIdentityServer(grpcServer, driver.NewIdentityService(checker.Ready))
@@ -136,3 +180,59 @@ func subMain() error {
 	}
 	return nil
 }
+
+// logicalVolumeConversion returns the conversion of LogicalVolume CRD by the webhook of this process.
+// It returns nil if --conversion-webhook-service is not given.  In that case, v2 is not served.
+func logicalVolumeConversion() (*runners.CRDConversion, error) {
+	if config.conversionWebhookService == "" {
+		return nil, nil
+	}
+	svc := strings.SplitN(config.conversionWebhookService, "/", 2)
+	if len(svc) != 2 || svc[0] == "" || svc[1] == "" {
+		return nil, fmt.Errorf("invalid conversion webhook service: %s", config.conversionWebhookService)
+	}
+	caBundle, err := conversionCABundle()
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: svc[0],
+					Name:      svc[1],
+					Path:      pointer.String("/convert"),
+					Port:      pointer.Int32(443),
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1", "v1beta1"},
+		},
+	}
+	return &runners.CRDConversion{
+		Conversion:     conversion,
+		ServedVersions: []string{topolvmv2.GroupVersion.Version},
+	}, nil
+}
+
+// conversionCABundle returns the CA certificate of the webhook.
+// It is given by --conversion-ca-bundle or read from ca.crt in --cert-dir.
+func conversionCABundle() ([]byte, error) {
+	if config.conversionCABundle != "" {
+		caBundle, err := base64.StdEncoding.DecodeString(config.conversionCABundle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conversion CA bundle: %w", err)
+		}
+		return caBundle, nil
+	}
+	if config.certDir == "" {
+		return nil, errors.New("--cert-dir or --conversion-ca-bundle is required to configure the conversion webhook")
+	}
+	caBundle, err := os.ReadFile(filepath.Join(config.certDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate of the webhook: %w", err)
+	}
+	return caBundle, nil
+}
@@ -0,0 +1,35 @@
+//go:build kubescheduler
+// +build kubescheduler
+
+package cmd
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/topolvm/topolvm/scheduler"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+	_ "k8s.io/component-base/metrics/prometheus/clientgo"
+	_ "k8s.io/component-base/metrics/prometheus/version" // for version metric registration
+	"k8s.io/kubernetes/cmd/kube-scheduler/app"
+)
+
+// Execute runs kube-scheduler with the scheduler framework plugin for TopoLVM.
+// This is called by main.main(). It only needs to happen once.
+func Execute() {
+	rand.Seed(time.Now().UnixNano())
+
+	pflag.CommandLine.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
+
+	command := app.NewSchedulerCommand(app.WithPlugin(scheduler.PluginName, scheduler.NewPlugin))
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	if err := command.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build kubescheduler
+// +build kubescheduler
+
+package main
+
+import "github.com/topolvm/topolvm/pkg/topolvm-kube-scheduler/cmd"
+
+func main() {
+	cmd.Execute()
+}
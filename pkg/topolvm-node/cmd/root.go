@@ -4,10 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/cgroup"
+	"github.com/topolvm/topolvm/lvmd"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -15,8 +18,20 @@ import (
 var config struct {
 	csiSocket   string
 	lvmdSocket  string
+	lvmdStream  string
+	streamTLS   lvmd.StreamTLS
 	metricsAddr string
+	cgroupRoot  string
 	zapOpts     zap.Options
+
+	capacityAnnotations bool
+	capacityResources   bool
+	capacityTopolvmNode bool
+
+	fstrimInterval             time.Duration
+	fstrimDeviceClassIntervals map[string]string
+	fstrimJitter               float64
+	fstrimRateLimit            time.Duration
 }
 
 var rootCmd = &cobra.Command{
@@ -48,7 +63,20 @@ func init() {
 	fs := rootCmd.Flags()
 	fs.StringVar(&config.csiSocket, "csi-socket", topolvm.DefaultCSISocket, "UNIX domain socket filename for CSI")
 	fs.StringVar(&config.lvmdSocket, "lvmd-socket", topolvm.DefaultLVMdSocket, "UNIX domain socket of lvmd service")
+	fs.StringVar(&config.lvmdStream, "lvmd-stream-address", "", "TCP address of LVStreamService of lvmd reachable from other nodes. Empty disables volume migration from this node.")
+	fs.StringVar(&config.streamTLS.CertFile, "lvmd-stream-tls-cert", "", "Certificate file to connect to LVStreamService of lvmd on other nodes. Empty disables volume migration to this node.")
+	fs.StringVar(&config.streamTLS.KeyFile, "lvmd-stream-tls-key", "", "Private key file of the certificate to connect to LVStreamService of lvmd on other nodes.")
+	fs.StringVar(&config.streamTLS.CAFile, "lvmd-stream-tls-ca", "", "CA certificate file to verify LVStreamService of lvmd on other nodes.")
+	fs.StringVar(&config.streamTLS.ServerName, "lvmd-stream-server-name", lvmd.DefaultStreamServerName, "Name verified in the certificate of LVStreamService of lvmd on other nodes.")
 	fs.StringVar(&config.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	fs.DurationVar(&config.fstrimInterval, "fstrim-interval", 0, "Interval of fstrim for mounted filesystems. 0 disables fstrim.")
+	fs.StringToStringVar(&config.fstrimDeviceClassIntervals, "fstrim-device-class-interval", nil, "Interval of fstrim for each device-class, e.g. ssd=24h,hdd=0")
+	fs.Float64Var(&config.fstrimJitter, "fstrim-jitter", 0.1, "Maximum random delay added to fstrim interval, as a fraction of the interval")
+	fs.DurationVar(&config.fstrimRateLimit, "fstrim-rate-limit", 10*time.Second, "Minimum time between two fstrim operations on the node")
+	fs.StringVar(&config.cgroupRoot, "cgroup-root", cgroup.DefaultRoot, "Mount point of the cgroup v2 unified hierarchy of the host")
+	fs.BoolVar(&config.capacityAnnotations, "capacity-annotations", true, "Expose VG free space as annotations of Node")
+	fs.BoolVar(&config.capacityResources, "capacity-resources", false, "Expose VG free space as extended resources of Node")
+	fs.BoolVar(&config.capacityTopolvmNode, "capacity-topolvm-node", false, "Expose VG capacity and health in the status of TopolvmNode")
 	fs.String("nodename", "", "The resource name of the running node")
 
 	viper.BindEnv("nodename", "NODE_NAME")
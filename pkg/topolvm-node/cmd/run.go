@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/topolvm/topolvm/lvmd/proto"
 	"github.com/topolvm/topolvm/runners"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -78,6 +80,25 @@ func subMain() error {
 		setupLog.Error(err, "unable to create controller", "controller", "LogicalVolume")
 		return err
 	}
+
+	var streamCreds credentials.TransportCredentials
+	if config.streamTLS.CertFile != "" {
+		streamCreds, err = config.streamTLS.ClientCredentials()
+		if err != nil {
+			return err
+		}
+	}
+	migrationcontroller := controllers.NewVolumeMigrationReconciler(
+		mgr.GetClient(),
+		nodename,
+		config.lvmdStream,
+		conn,
+		streamCreds,
+	)
+	if err := migrationcontroller.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeMigration")
+		return err
+	}
 	//+kubebuilder:scaffold:builder
 
 	// Add health checker to manager
@@ -89,7 +110,26 @@ func subMain() error {
 	// Add metrics exporter to manager.
 	// Note that grpc.ClientConn can be shared with multiple stubs/services.
 	// https://github.com/grpc/grpc-go/tree/master/examples/features/multiplex
-	if err := mgr.Add(runners.NewMetricsExporter(conn, mgr, nodename)); err != nil {
+	exposure := runners.CapacityExposure{
+		Annotations:       config.capacityAnnotations,
+		ExtendedResources: config.capacityResources,
+		TopolvmNode:       config.capacityTopolvmNode,
+	}
+	if err := mgr.Add(runners.NewMetricsExporter(conn, mgr, nodename, exposure)); err != nil {
+		return err
+	}
+
+	// Add fstrim runner to manager.
+	fstrimConfig, err := newFSTrimConfig()
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(runners.NewFSTrimRunner(conn, mgr, nodename, fstrimConfig)); err != nil {
+		return err
+	}
+
+	// Add IO limits runner to manager.
+	if err := mgr.Add(runners.NewIOLimitsRunner(mgr, nodename, config.cgroupRoot)); err != nil {
 		return err
 	}
 
@@ -103,7 +143,7 @@ func subMain() error {
 	}
 	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(ErrorLoggingInterceptor))
 	csi.RegisterIdentityServer(grpcServer, driver.NewIdentityService(checker.Ready))
-	csi.RegisterNodeServer(grpcServer, driver.NewNodeService(nodename, conn, s))
+	csi.RegisterNodeServer(grpcServer, driver.NewNodeService(nodename, conn, s, config.cgroupRoot))
 	err = mgr.Add(runners.NewGRPCRunner(grpcServer, config.csiSocket, false))
 	if err != nil {
 		return err
@@ -120,6 +160,26 @@ func subMain() error {
 
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers,verbs=get;list;watch
 
+func newFSTrimConfig() (runners.FSTrimConfig, error) {
+	if config.fstrimJitter < 0 {
+		return runners.FSTrimConfig{}, fmt.Errorf("negative fstrim jitter: %v", config.fstrimJitter)
+	}
+	intervals := make(map[string]time.Duration)
+	for dc, v := range config.fstrimDeviceClassIntervals {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return runners.FSTrimConfig{}, fmt.Errorf("invalid fstrim interval for device-class %s: %w", dc, err)
+		}
+		intervals[dc] = d
+	}
+	return runners.FSTrimConfig{
+		Interval:             config.fstrimInterval,
+		DeviceClassIntervals: intervals,
+		Jitter:               config.fstrimJitter,
+		RateLimit:            config.fstrimRateLimit,
+	}, nil
+}
+
 func checkFunc(conn *grpc.ClientConn, r client.Reader) func() error {
 	vgs := proto.NewVGServiceClient(conn)
 	return func() error {
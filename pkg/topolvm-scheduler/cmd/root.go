@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,10 +11,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/topolvm/topolvm"
 	"github.com/topolvm/topolvm/scheduler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/yaml"
 )
 
-var cfgFilePath string
+var (
+	cfgFilePath string
+	zapOpts     zap.Options
+)
 
 const defaultDivisor = 1
 const defaultListenAddr = ":8000"
@@ -25,6 +34,19 @@ type Config struct {
 	Divisors map[string]float64 `json:"divisors"`
 	// DefaultDivisor is the default divisor value.
 	DefaultDivisor float64 `json:"default-divisor"`
+	// Scoring configures the scoring strategies of device-classes.
+	Scoring *scheduler.ScoringConfig `json:"scoring"`
+	// ReservationTTL is the duration to keep the capacity reserved for pods until their volumes are created.
+	// Zero disables the reservation.
+	ReservationTTL metav1.Duration `json:"reservation-ttl"`
+	// NodeCacheCapable enables requests with node names by watching Nodes.
+	NodeCacheCapable bool `json:"node-cache-capable"`
+	// TopolvmNodes reads the capacity of nodes from TopolvmNodes instead of the annotations of Node.
+	TopolvmNodes bool `json:"topolvm-nodes"`
+	// Explain enables "/explain" endpoint that reads Pods and Nodes.
+	Explain bool `json:"explain"`
+	// DecisionLogSampleRate is the ratio of requests whose decisions are logged, from 0 to 1.
+	DecisionLogSampleRate float64 `json:"decision-log-sample-rate"`
 }
 
 var config = &Config{
@@ -51,6 +73,28 @@ It scores nodes with this formula:
     min(10, max(0, log2(capacity >> 30 / divisor)))
 
 The default divisor is 1.  It can be changed with a command-line option.
+
+The scoring strategy can be changed per device-class with "scoring".
+Available strategies are "spread-log2" (the formula above), "most-allocated",
+"least-allocated-linear", and "requested-to-capacity-ratio".  The scores of
+device-classes are aggregated with "min" (default), "sum", or "weighted-average".
+
+If "reservation-ttl" is configured, the capacity requested by a pod is reserved
+on the nodes approved for the pod until its LogicalVolumes are created or the TTL
+expires.  The reserved capacity is subtracted in both verbs.
+
+If "node-cache-capable" is true, the extender watches Nodes and accepts
+requests with node names instead of Node objects.  Configure kube-scheduler
+with "nodeCacheCapable: true" to use this mode.
+
+If "topolvm-nodes" is true, the extender watches TopolvmNodes and reads the
+capacity of nodes from their status instead of the annotations of Node.
+Requests with node names are also accepted in this mode.
+
+Prometheus metrics are served at "/metrics".  If "explain" is true,
+"/explain?namespace=<namespace>&name=<pod>" shows how the extender filters
+and scores nodes for the pod.  The decisions of the verbs are logged for
+the ratio of requests given by "decision-log-sample-rate".
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -63,6 +107,7 @@ func subMain() error {
 	if err != nil {
 		return err
 	}
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
 
 	if len(cfgFilePath) != 0 {
 		b, err := os.ReadFile(cfgFilePath)
@@ -75,7 +120,61 @@ func subMain() error {
 		}
 	}
 
-	h, err := scheduler.NewHandler(config.DefaultDivisor, config.Divisors)
+	var reservations *scheduler.ReservationCache
+	if config.ReservationTTL.Duration > 0 {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		reservations, err = scheduler.StartReservationCache(context.Background(), cfg, config.ReservationTTL.Duration)
+		if err != nil {
+			return err
+		}
+	}
+
+	var nodes *scheduler.NodeCache
+	switch {
+	case config.TopolvmNodes:
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		nodes, err = scheduler.StartTopolvmNodeCache(context.Background(), cfg)
+		if err != nil {
+			return err
+		}
+	case config.NodeCacheCapable:
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		nodes, err = scheduler.StartNodeCache(context.Background(), cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var c client.Reader
+	if config.Explain {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		c, err = client.New(cfg, client.Options{})
+		if err != nil {
+			return err
+		}
+	}
+
+	h, err := scheduler.NewHandler(scheduler.HandlerOptions{
+		DefaultDivisor:        config.DefaultDivisor,
+		Divisors:              config.Divisors,
+		Scoring:               config.Scoring,
+		Reservations:          reservations,
+		Nodes:                 nodes,
+		Client:                c,
+		DecisionLogSampleRate: config.DecisionLogSampleRate,
+	})
 	if err != nil {
 		return err
 	}
@@ -111,4 +210,8 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFilePath, "config", "", "config file")
+
+	goflags := flag.NewFlagSet("zap", flag.ExitOnError)
+	zapOpts.BindFlags(goflags)
+	rootCmd.PersistentFlags().AddGoFlagSet(goflags)
 }
@@ -0,0 +1,162 @@
+package runners
+
+import (
+	"context"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const crdRetryInterval = time.Minute
+
+var crdLogger = ctrl.Log.WithName("crd")
+
+// CRDConversion is the conversion of a CRD configured by the runner created with NewCRDUpdater.
+type CRDConversion struct {
+	// Conversion is set to spec.conversion of the CRD.
+	Conversion *apiextensionsv1.CustomResourceConversion
+	// ServedVersions are the versions of the CRD to be served along with the conversion.
+	// The versions must not be served until the conversion is configured.
+	ServedVersions []string
+}
+
+type crdUpdater struct {
+	client     client.Client
+	name       string
+	list       client.ObjectList
+	conversion *CRDConversion
+}
+
+var _ manager.LeaderElectionRunnable = &crdUpdater{}
+
+// NewCRDUpdater creates controller-runtime's manager.Runnable to configure the conversion
+// of the CRD and to migrate the objects of the CRD to the storage version.
+//
+// If conversion is not nil, spec.conversion of the CRD is updated and its served versions are served.
+// Then, if status.storedVersions of the CRD has versions other than the storage version,
+// the objects listed by list are rewritten in the storage version and the other versions
+// are removed from status.storedVersions.
+func NewCRDUpdater(c client.Client, name string, list client.ObjectList, conversion *CRDConversion) manager.Runnable {
+	return &crdUpdater{
+		client:     c,
+		name:       name,
+		list:       list,
+		conversion: conversion,
+	}
+}
+
+// Start implements controller-runtime's manager.Runnable.
+func (u *crdUpdater) Start(ctx context.Context) error {
+	for {
+		err := u.update(ctx)
+		if err == nil {
+			return nil
+		}
+		crdLogger.Error(err, "failed to update CRD; retrying", "name", u.name)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(crdRetryInterval):
+		}
+	}
+}
+
+// NeedLeaderElection implements controller-runtime's manager.LeaderElectionRunnable.
+func (u *crdUpdater) NeedLeaderElection() bool {
+	return true
+}
+
+func (u *crdUpdater) update(ctx context.Context) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := u.client.Get(ctx, client.ObjectKey{Name: u.name}, crd); err != nil {
+		return err
+	}
+
+	if u.conversion != nil {
+		crd2 := crd.DeepCopy()
+		crd2.Spec.Conversion = u.conversion.Conversion
+		for i := range crd2.Spec.Versions {
+			v := &crd2.Spec.Versions[i]
+			for _, served := range u.conversion.ServedVersions {
+				if v.Name == served {
+					v.Served = true
+				}
+			}
+		}
+		if !equality.Semantic.DeepEqual(crd.Spec, crd2.Spec) {
+			// Versions is a list, so the patch replaces the whole spec.versions.
+			if err := u.client.Patch(ctx, crd2, client.MergeFrom(crd)); err != nil {
+				return err
+			}
+			crdLogger.Info("configured conversion", "name", u.name, "strategy", u.conversion.Conversion.Strategy, "served", u.conversion.ServedVersions)
+			crd = crd2
+		}
+	}
+
+	storage := storageVersion(crd)
+	if storage == "" {
+		return nil
+	}
+	stored := crd.Status.StoredVersions
+	if len(stored) == 1 && stored[0] == storage {
+		return nil
+	}
+
+	crdLogger.Info("start migrating objects to the storage version", "name", u.name, "storage", storage, "stored", stored)
+	n, err := u.migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	crd2 := crd.DeepCopy()
+	crd2.Status.StoredVersions = []string{storage}
+	if err := u.client.Status().Update(ctx, crd2); err != nil {
+		return err
+	}
+	crdLogger.Info("migrated objects to the storage version", "name", u.name, "storage", storage, "count", n)
+	return nil
+}
+
+// migrate rewrites all objects in the storage version.
+// An empty patch makes the API server write the object again in the storage version.
+func (u *crdUpdater) migrate(ctx context.Context) (int, error) {
+	list := u.list.DeepCopyObject().(client.ObjectList)
+	if err := u.client.List(ctx, list); err != nil {
+		return 0, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, item := range items {
+		obj := item.(client.Object)
+		err := u.client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, []byte("{}")))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package runners
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCRDUpdater(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	const name = "logicalvolumes.topolvm.cybozu.com"
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: false},
+				{Name: "v2", Served: false, Storage: true},
+			},
+			Conversion: &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1", "v2"},
+		},
+	}
+	lv := &topolvmv1.LogicalVolume{ObjectMeta: metav1.ObjectMeta{Name: "lv"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd, lv).Build()
+
+	conversion := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: "topolvm-system", Name: "topolvm-controller"},
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+	u := NewCRDUpdater(c, name, &topolvmv1.LogicalVolumeList{}, &CRDConversion{
+		Conversion:     conversion,
+		ServedVersions: []string{"v2"},
+	})
+	if err := u.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: name}, updated); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(updated.Spec.Conversion, conversion) {
+		t.Errorf("conversion is not configured: %#v", updated.Spec.Conversion)
+	}
+	for _, v := range updated.Spec.Versions {
+		if !v.Served {
+			t.Errorf("version %s is not served", v.Name)
+		}
+	}
+	if !reflect.DeepEqual(updated.Status.StoredVersions, []string{"v2"}) {
+		t.Errorf("unexpected stored versions: %v", updated.Status.StoredVersions)
+	}
+}
+
+func TestCRDUpdaterWithoutConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	const name = "logicalvolumes.topolvm.cybozu.com"
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: true},
+				{Name: "v2", Served: false, Storage: false},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd).Build()
+
+	u := NewCRDUpdater(c, name, &topolvmv1.LogicalVolumeList{}, nil)
+	if err := u.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: name}, updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Spec.Conversion != nil {
+		t.Errorf("conversion should not be configured: %#v", updated.Spec.Conversion)
+	}
+	if updated.Spec.Versions[1].Served {
+		t.Error("v2 should not be served without conversion")
+	}
+}
@@ -0,0 +1,430 @@
+package runners
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/driver"
+	"github.com/topolvm/topolvm/filesystem"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// fstrimCheckInterval is the interval to look for mounted volumes to be trimmed.
+const fstrimCheckInterval = 1 * time.Minute
+
+var ftLogger = ctrl.Log.WithName("runners").WithName("fstrim")
+
+// FSTrimConfig is the configuration of the fstrim runner.
+type FSTrimConfig struct {
+	// Interval is the interval of fstrim for the device-classes not listed in DeviceClassIntervals.
+	// Zero disables fstrim.
+	Interval time.Duration
+
+	// DeviceClassIntervals is the interval of fstrim for each device-class.
+	DeviceClassIntervals map[string]time.Duration
+
+	// Jitter is the maximum random delay added to each interval, as a fraction of the interval.
+	Jitter float64
+
+	// RateLimit is the minimum time between two fstrim operations on the node.
+	RateLimit time.Duration
+}
+
+type trimTarget struct {
+	volumeID    string
+	mountPath   string
+	deviceClass string
+	interval    time.Duration
+}
+
+type trimSchedule struct {
+	interval    time.Duration
+	deviceClass string
+	next        time.Time
+}
+
+type fstrimRunner struct {
+	client.Client
+	vgService proto.VGServiceClient
+	nodeName  string
+	config    FSTrimConfig
+
+	schedules map[string]*trimSchedule
+	lastTrim  time.Time
+
+	trimmedBytesTotal *prometheus.CounterVec
+	lastTrimmedBytes  *prometheus.GaugeVec
+	lastRunTimestamp  *prometheus.GaugeVec
+	failuresTotal     *prometheus.CounterVec
+}
+
+var _ manager.LeaderElectionRunnable = &fstrimRunner{}
+
+// NewFSTrimRunner creates controller-runtime's manager.Runnable to run
+// fstrim periodically for the filesystems of TopoLVM volumes mounted on a node.
+func NewFSTrimRunner(conn *grpc.ClientConn, mgr manager.Manager, nodeName string, config FSTrimConfig) manager.Runnable {
+	labels := []string{"device_class", "volume_id"}
+
+	trimmedBytesTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volume",
+		Name:        "fstrim_trimmed_bytes_total",
+		Help:        "Total bytes trimmed by fstrim",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, labels)
+	metrics.Registry.MustRegister(trimmedBytesTotal)
+
+	lastTrimmedBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volume",
+		Name:        "fstrim_last_trimmed_bytes",
+		Help:        "Bytes trimmed by the last successful fstrim",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, labels)
+	metrics.Registry.MustRegister(lastTrimmedBytes)
+
+	lastRunTimestamp := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volume",
+		Name:        "fstrim_last_run_timestamp_seconds",
+		Help:        "Unix time of the last successful fstrim",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, labels)
+	metrics.Registry.MustRegister(lastRunTimestamp)
+
+	failuresTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volume",
+		Name:        "fstrim_failures_total",
+		Help:        "Total number of failed fstrim",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, labels)
+	metrics.Registry.MustRegister(failuresTotal)
+
+	return &fstrimRunner{
+		Client:            mgr.GetClient(),
+		vgService:         proto.NewVGServiceClient(conn),
+		nodeName:          nodeName,
+		config:            config,
+		schedules:         make(map[string]*trimSchedule),
+		trimmedBytesTotal: trimmedBytesTotal,
+		lastTrimmedBytes:  lastTrimmedBytes,
+		lastRunTimestamp:  lastRunTimestamp,
+		failuresTotal:     failuresTotal,
+	}
+}
+
+// Start implements controller-runtime's manager.Runnable.
+func (r *fstrimRunner) Start(ctx context.Context) error {
+	tick := time.NewTicker(fstrimCheckInterval)
+	defer tick.Stop()
+
+	for {
+		if err := r.run(ctx); err != nil {
+			ftLogger.Error(err, "failed to run fstrim")
+		}
+
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements controller-runtime's manager.LeaderElectionRunnable.
+func (r *fstrimRunner) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *fstrimRunner) run(ctx context.Context) error {
+	data, err := os.ReadFile("/proc/mounts")
+	if err != nil {
+		return err
+	}
+	mounts := findVolumeMounts(data, driver.DeviceDirectory)
+
+	targets, err := r.resolveTargets(ctx, mounts)
+	if err != nil {
+		return err
+	}
+	r.updateSchedules(targets, time.Now())
+
+	for _, t := range r.dueTargets(targets, time.Now()) {
+		if err := r.waitRateLimit(ctx); err != nil {
+			return nil
+		}
+		r.trim(t)
+	}
+	return nil
+}
+
+// findVolumeMounts returns a map from the volume IDs to one of their mount paths.
+// data is the content of /proc/mounts.
+func findVolumeMounts(data []byte, deviceDir string) map[string]string {
+	mounts := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if filepath.Dir(fields[0]) != deviceDir {
+			continue
+		}
+		volumeID := filepath.Base(fields[0])
+		if _, ok := mounts[volumeID]; ok {
+			continue
+		}
+		mounts[volumeID] = unescapeMountPath(fields[1])
+	}
+	return mounts
+}
+
+// unescapeMountPath decodes the octal escapes used in /proc/mounts.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) && isOctal(path[i+1]) && isOctal(path[i+2]) && isOctal(path[i+3]) {
+			b.WriteByte((path[i+1]-'0')<<6 | (path[i+2]-'0')<<3 | (path[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return '0' <= c && c <= '7'
+}
+
+func (r *fstrimRunner) resolveTargets(ctx context.Context, mounts map[string]string) ([]trimTarget, error) {
+	if len(mounts) == 0 {
+		return nil, nil
+	}
+
+	var lvList topolvmv1.LogicalVolumeList
+	if err := r.List(ctx, &lvList); err != nil {
+		return nil, err
+	}
+	deviceClasses := make(map[string]string)
+	for _, lv := range lvList.Items {
+		if lv.Spec.NodeName != r.nodeName || lv.Status.VolumeID == "" {
+			continue
+		}
+		deviceClasses[lv.Status.VolumeID] = lv.Spec.DeviceClass
+	}
+
+	// Inline ephemeral volumes have no LogicalVolume, so ask lvmd for their device-classes.
+	ephemeral := make(map[string]bool)
+	for volumeID := range mounts {
+		if _, ok := deviceClasses[volumeID]; !ok {
+			ephemeral[volumeID] = true
+		}
+	}
+	if len(ephemeral) > 0 {
+		if err := r.findDeviceClasses(ctx, ephemeral, deviceClasses); err != nil {
+			return nil, err
+		}
+	}
+
+	var pvList corev1.PersistentVolumeList
+	if err := r.List(ctx, &pvList); err != nil {
+		return nil, err
+	}
+	storageClasses := make(map[string]string)
+	for _, pv := range pvList.Items {
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != topolvm.PluginName {
+			continue
+		}
+		storageClasses[pv.Spec.CSI.VolumeHandle] = pv.Spec.StorageClassName
+	}
+
+	var targets []trimTarget
+	for volumeID, mountPath := range mounts {
+		dc := deviceClasses[volumeID]
+
+		var scInterval string
+		if scName := storageClasses[volumeID]; scName != "" {
+			var sc storagev1.StorageClass
+			err := r.Get(ctx, types.NamespacedName{Name: scName}, &sc)
+			switch {
+			case err == nil:
+				scInterval = sc.Parameters[topolvm.FSTrimIntervalKey]
+			case apierrors.IsNotFound(err):
+			default:
+				return nil, err
+			}
+		}
+
+		interval, err := r.config.interval(dc, scInterval)
+		if err != nil {
+			ftLogger.Error(err, "invalid fstrim interval in StorageClass", "volume_id", volumeID, "storage_class", storageClasses[volumeID])
+		}
+		if interval <= 0 {
+			continue
+		}
+		targets = append(targets, trimTarget{
+			volumeID:    volumeID,
+			mountPath:   mountPath,
+			deviceClass: dc,
+			interval:    interval,
+		})
+	}
+	return targets, nil
+}
+
+// findDeviceClasses looks for the logical volumes of volumeIDs in the device-classes of lvmd
+// and adds their device-classes to deviceClasses.
+// The default device-class is represented as topolvm.DefaultDeviceClassName like LogicalVolume.
+func (r *fstrimRunner) findDeviceClasses(ctx context.Context, volumeIDs map[string]bool, deviceClasses map[string]string) error {
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wc, err := r.vgService.Watch(wctx, &proto.Empty{})
+	if err != nil {
+		return err
+	}
+	res, err := wc.Recv()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range res.GetItems() {
+		resp, err := r.vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: item.GetDeviceClass()})
+		if err != nil {
+			return err
+		}
+		dc := item.GetDeviceClass()
+		if item.GetDefault() {
+			dc = topolvm.DefaultDeviceClassName
+		}
+		for _, lv := range resp.GetVolumes() {
+			if volumeIDs[lv.GetName()] {
+				deviceClasses[lv.GetName()] = dc
+			}
+		}
+	}
+	return nil
+}
+
+// interval returns the fstrim interval for a volume.
+// The StorageClass parameter takes precedence over the device-class setting.
+// If scInterval is invalid, the device-class setting is returned with an error.
+func (c FSTrimConfig) interval(deviceClass, scInterval string) (time.Duration, error) {
+	dcInterval := c.Interval
+	if d, ok := c.DeviceClassIntervals[deviceClass]; ok {
+		dcInterval = d
+	}
+	if scInterval == "" {
+		return dcInterval, nil
+	}
+	d, err := time.ParseDuration(scInterval)
+	if err != nil {
+		return dcInterval, err
+	}
+	return d, nil
+}
+
+func (c FSTrimConfig) jitter(interval time.Duration) time.Duration {
+	if c.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Float64() * c.Jitter * float64(interval))
+}
+
+func (r *fstrimRunner) updateSchedules(targets []trimTarget, now time.Time) {
+	current := make(map[string]bool)
+	for _, t := range targets {
+		current[t.volumeID] = true
+		s, ok := r.schedules[t.volumeID]
+		if ok && s.interval == t.interval {
+			continue
+		}
+		// Spread the first run of the volumes over the interval so that
+		// they are not trimmed all at once when topolvm-node starts.
+		r.schedules[t.volumeID] = &trimSchedule{
+			interval:    t.interval,
+			deviceClass: t.deviceClass,
+			next:        now.Add(time.Duration(rand.Int63n(int64(t.interval)))),
+		}
+	}
+
+	for volumeID, s := range r.schedules {
+		if current[volumeID] {
+			continue
+		}
+		delete(r.schedules, volumeID)
+		r.trimmedBytesTotal.DeleteLabelValues(s.deviceClass, volumeID)
+		r.lastTrimmedBytes.DeleteLabelValues(s.deviceClass, volumeID)
+		r.lastRunTimestamp.DeleteLabelValues(s.deviceClass, volumeID)
+		r.failuresTotal.DeleteLabelValues(s.deviceClass, volumeID)
+	}
+}
+
+func (r *fstrimRunner) dueTargets(targets []trimTarget, now time.Time) []trimTarget {
+	var due []trimTarget
+	for _, t := range targets {
+		if !r.schedules[t.volumeID].next.After(now) {
+			due = append(due, t)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return r.schedules[due[i].volumeID].next.Before(r.schedules[due[j].volumeID].next)
+	})
+	return due
+}
+
+func (r *fstrimRunner) waitRateLimit(ctx context.Context) error {
+	wait := time.Until(r.lastTrim.Add(r.config.RateLimit))
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *fstrimRunner) trim(t trimTarget) {
+	start := time.Now()
+	r.lastTrim = start
+	r.schedules[t.volumeID].next = start.Add(t.interval + r.config.jitter(t.interval))
+
+	trimmed, err := filesystem.Fstrim(t.mountPath)
+	if err != nil {
+		ftLogger.Error(err, "fstrim failed", "volume_id", t.volumeID, "target_path", t.mountPath)
+		r.failuresTotal.WithLabelValues(t.deviceClass, t.volumeID).Inc()
+		return
+	}
+
+	ftLogger.Info("fstrim done",
+		"volume_id", t.volumeID,
+		"target_path", t.mountPath,
+		"trimmed", trimmed,
+		"duration", time.Since(start).String())
+	r.trimmedBytesTotal.WithLabelValues(t.deviceClass, t.volumeID).Add(float64(trimmed))
+	r.lastTrimmedBytes.WithLabelValues(t.deviceClass, t.volumeID).Set(float64(trimmed))
+	r.lastRunTimestamp.WithLabelValues(t.deviceClass, t.volumeID).Set(float64(start.Unix()))
+}
@@ -0,0 +1,152 @@
+package runners
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindVolumeMounts(t *testing.T) {
+	data := []byte(`/dev/sda1 / ext4 rw,relatime 0 0
+/dev/topolvm/vol1 /var/lib/kubelet/pods/a/volumes/kubernetes.io~csi/pvc-1/mount xfs rw,relatime 0 0
+/dev/topolvm/vol1 /var/lib/kubelet/pods/b/volumes/kubernetes.io~csi/pvc-1/mount xfs rw,relatime 0 0
+/dev/topolvm/vol2 /mnt/with\040space ext4 rw,relatime 0 0
+/dev/topolvm2/vol3 /mnt/other ext4 rw,relatime 0 0
+`)
+
+	mounts := findVolumeMounts(data, "/dev/topolvm")
+	if len(mounts) != 2 {
+		t.Fatalf("unexpected mounts: %v", mounts)
+	}
+	if mounts["vol1"] != "/var/lib/kubelet/pods/a/volumes/kubernetes.io~csi/pvc-1/mount" {
+		t.Errorf("unexpected mount path for vol1: %s", mounts["vol1"])
+	}
+	if mounts["vol2"] != "/mnt/with space" {
+		t.Errorf("unexpected mount path for vol2: %s", mounts["vol2"])
+	}
+}
+
+func TestFSTrimConfigInterval(t *testing.T) {
+	config := FSTrimConfig{
+		Interval: 24 * time.Hour,
+		DeviceClassIntervals: map[string]time.Duration{
+			"ssd": 12 * time.Hour,
+			"hdd": 0,
+		},
+	}
+
+	cases := []struct {
+		deviceClass string
+		scInterval  string
+		expected    time.Duration
+		valid       bool
+	}{
+		{"", "", 24 * time.Hour, true},
+		{"ssd", "", 12 * time.Hour, true},
+		{"hdd", "", 0, true},
+		{"hdd", "1h", time.Hour, true},
+		{"ssd", "0", 0, true},
+		{"ssd", "invalid", 12 * time.Hour, false},
+	}
+
+	for _, c := range cases {
+		interval, err := config.interval(c.deviceClass, c.scInterval)
+		if c.valid && err != nil {
+			t.Errorf("%s/%s: unexpected error: %v", c.deviceClass, c.scInterval, err)
+		} else if !c.valid && err == nil {
+			t.Errorf("%s/%s: should be invalid", c.deviceClass, c.scInterval)
+		}
+		if interval != c.expected {
+			t.Errorf("%s/%s: expected %v, actual %v", c.deviceClass, c.scInterval, c.expected, interval)
+		}
+	}
+}
+
+type fakeVGService struct {
+	proto.VGServiceClient
+	items   []*proto.WatchItem
+	volumes map[string][]*proto.LogicalVolume
+}
+
+func (s fakeVGService) GetLVList(ctx context.Context, in *proto.GetLVListRequest, opts ...grpc.CallOption) (*proto.GetLVListResponse, error) {
+	return &proto.GetLVListResponse{Volumes: s.volumes[in.DeviceClass]}, nil
+}
+
+func (s fakeVGService) Watch(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (proto.VGService_WatchClient, error) {
+	return fakeWatchClient{res: &proto.WatchResponse{Items: s.items}}, nil
+}
+
+type fakeWatchClient struct {
+	grpc.ClientStream
+	res *proto.WatchResponse
+}
+
+func (c fakeWatchClient) Recv() (*proto.WatchResponse, error) {
+	return c.res, nil
+}
+
+func TestFSTrimResolveTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	lv := &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec:       topolvmv1.LogicalVolumeSpec{NodeName: "node1", DeviceClass: "ssd"},
+		Status:     topolvmv1.LogicalVolumeStatus{VolumeID: "vol1"},
+	}
+
+	r := &fstrimRunner{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(lv).Build(),
+		vgService: fakeVGService{
+			items: []*proto.WatchItem{
+				{DeviceClass: "ssd"},
+				{DeviceClass: "hdd", Default: true},
+			},
+			volumes: map[string][]*proto.LogicalVolume{
+				"ssd": {{Name: "vol1"}, {Name: "vol3"}},
+				"hdd": {{Name: "vol2"}},
+			},
+		},
+		nodeName: "node1",
+		config: FSTrimConfig{
+			Interval:             24 * time.Hour,
+			DeviceClassIntervals: map[string]time.Duration{"ssd": 12 * time.Hour},
+		},
+	}
+
+	mounts := map[string]string{
+		"vol1": "/mnt/vol1",
+		"vol2": "/mnt/vol2",
+		"vol3": "/mnt/vol3",
+	}
+	targets, err := r.resolveTargets(context.Background(), mounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]trimTarget{
+		"vol1": {volumeID: "vol1", mountPath: "/mnt/vol1", deviceClass: "ssd", interval: 12 * time.Hour},
+		"vol2": {volumeID: "vol2", mountPath: "/mnt/vol2", deviceClass: "", interval: 24 * time.Hour},
+		"vol3": {volumeID: "vol3", mountPath: "/mnt/vol3", deviceClass: "ssd", interval: 12 * time.Hour},
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("unexpected targets: %#v", targets)
+	}
+	for _, target := range targets {
+		if target != expected[target.volumeID] {
+			t.Errorf("unexpected target: expected=%#v, actual=%#v", expected[target.volumeID], target)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package runners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/driver"
+	"github.com/topolvm/topolvm/filesystem"
+	"golang.org/x/sys/unix"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// ioLimitsSyncInterval is the interval to reapply the IO limits of the volumes.
+	ioLimitsSyncInterval = 30 * time.Second
+
+	kubeletPodsDir          = "/var/lib/kubelet/pods"
+	kubeletVolumeDevicesDir = "/var/lib/kubelet/plugins/kubernetes.io/csi/volumeDevices/publish"
+)
+
+var ilLogger = ctrl.Log.WithName("runners").WithName("io_limits")
+
+type ioLimitsRunner struct {
+	client.Client
+	nodeName   string
+	cgroupRoot string
+}
+
+var _ manager.LeaderElectionRunnable = &ioLimitsRunner{}
+
+// NewIOLimitsRunner creates controller-runtime's manager.Runnable to keep
+// the IO limits of LogicalVolumes applied to the cgroups of the pods using them.
+// This reflects the changes of the limits in LogicalVolumes and the moves of the pod cgroups.
+func NewIOLimitsRunner(mgr manager.Manager, nodeName, cgroupRoot string) manager.Runnable {
+	return &ioLimitsRunner{
+		Client:     mgr.GetClient(),
+		nodeName:   nodeName,
+		cgroupRoot: cgroupRoot,
+	}
+}
+
+// Start implements controller-runtime's manager.Runnable.
+func (r *ioLimitsRunner) Start(ctx context.Context) error {
+	tick := time.NewTicker(ioLimitsSyncInterval)
+	defer tick.Stop()
+
+	for {
+		if err := r.sync(ctx); err != nil {
+			ilLogger.Error(err, "failed to sync IO limits")
+		}
+
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements controller-runtime's manager.LeaderElectionRunnable.
+func (r *ioLimitsRunner) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *ioLimitsRunner) sync(ctx context.Context) error {
+	var lvList topolvmv1.LogicalVolumeList
+	if err := r.List(ctx, &lvList); err != nil {
+		return err
+	}
+
+	for _, lv := range lvList.Items {
+		if lv.Spec.NodeName != r.nodeName || lv.Status.VolumeID == "" {
+			continue
+		}
+		r.syncVolume(&lv)
+	}
+	return nil
+}
+
+func (r *ioLimitsRunner) syncVolume(lv *topolvmv1.LogicalVolume) {
+	var st unix.Stat_t
+	err := filesystem.Stat(filepath.Join(driver.DeviceDirectory, lv.Status.VolumeID), &st)
+	if err != nil {
+		// The device file is created when the volume is published.
+		if !os.IsNotExist(err) {
+			ilLogger.Error(err, "failed to stat device", "name", lv.Name)
+		}
+		return
+	}
+	major, minor := unix.Major(st.Rdev), unix.Minor(st.Rdev)
+
+	podUIDs, err := findPodsUsingVolume(lv.Name)
+	if err != nil {
+		ilLogger.Error(err, "failed to find pods using volume", "name", lv.Name)
+		return
+	}
+
+	for _, podUID := range podUIDs {
+		updated, err := driver.ApplyIOLimits(r.cgroupRoot, podUID, major, minor, lv.Spec.IOLimits)
+		if err != nil {
+			ilLogger.Error(err, "failed to apply IO limits", "name", lv.Name, "pod_uid", podUID)
+			continue
+		}
+		if updated {
+			ilLogger.Info("applied IO limits",
+				"name", lv.Name,
+				"pod_uid", podUID,
+				"io_limits", lv.Spec.IOLimits)
+		}
+	}
+}
+
+// findPodsUsingVolume returns the UIDs of the pods using the PersistentVolume
+// by looking for the directories kubelet creates to publish volumes.
+// The name of a LogicalVolume is the same as its PersistentVolume.
+func findPodsUsingVolume(pvName string) ([]string, error) {
+	var podUIDs []string
+
+	// filesystem volumes: <pods dir>/<pod UID>/volumes/kubernetes.io~csi/<PV name>
+	matches, err := filepath.Glob(filepath.Join(kubeletPodsDir, "*", "volumes", "kubernetes.io~csi", pvName))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		podUIDs = append(podUIDs, filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(m)))))
+	}
+
+	// block volumes: <volume devices dir>/<PV name>/<pod UID>
+	matches, err = filepath.Glob(filepath.Join(kubeletVolumeDevicesDir, pvName, "*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		podUIDs = append(podUIDs, filepath.Base(m))
+	}
+	return podUIDs, nil
+}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	"github.com/topolvm/topolvm/lvmd/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,24 +31,46 @@ var meLogger = ctrl.Log.WithName("runners").WithName("metrics_exporter")
 
 // NodeMetrics is a set of metrics of a TopoLVM Node.
 type NodeMetrics struct {
-	FreeBytes   uint64
-	SizeBytes   uint64
-	DeviceClass string
+	FreeBytes       uint64
+	SizeBytes       uint64
+	WipingBytes     uint64
+	WipeFailedBytes uint64
+	DeviceClass     string
 }
 
+// CapacityExposure specifies how the capacity of a node is exposed.
+type CapacityExposure struct {
+	// Annotations exposes VG free space as "capacity.topolvm.cybozu.com/<device-class>" annotations.
+	Annotations bool
+	// ExtendedResources exposes VG free space as "capacity.topolvm.cybozu.com/<device-class>"
+	// extended resources in the status of Node.
+	ExtendedResources bool
+	// TopolvmNode exposes the capacity and the health of volume groups in the status of
+	// TopolvmNode having the same name as Node.
+	TopolvmNode bool
+}
+
+// heartbeatInterval is the interval to update the heartbeat time of TopolvmNode.
+// This must be sufficiently shorter than topolvmv1.HeartbeatTimeout.
+const heartbeatInterval = time.Minute
+
 type metricsExporter struct {
 	client.Client
+	apiReader      client.Reader
 	nodeName       string
+	exposure       CapacityExposure
 	vgService      proto.VGServiceClient
 	availableBytes *prometheus.GaugeVec
 	sizeBytes      *prometheus.GaugeVec
+	wipingBytes    *prometheus.GaugeVec
+	wipeFailed     *prometheus.GaugeVec
 }
 
 var _ manager.LeaderElectionRunnable = &metricsExporter{}
 
 // NewMetricsExporter creates controller-runtime's manager.Runnable to run
-// a metrics exporter for a node.
-func NewMetricsExporter(conn *grpc.ClientConn, mgr manager.Manager, nodeName string) manager.Runnable {
+// a metrics exporter for a node.  It also exposes the capacity of the node as specified by exposure.
+func NewMetricsExporter(conn *grpc.ClientConn, mgr manager.Manager, nodeName string, exposure CapacityExposure) manager.Runnable {
 	availableBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace:   metricsNamespace,
 		Subsystem:   "volumegroup",
@@ -61,12 +89,34 @@ func NewMetricsExporter(conn *grpc.ClientConn, mgr manager.Manager, nodeName str
 	}, []string{"device_class"})
 	metrics.Registry.MustRegister(sizeBytes)
 
+	wipingBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volumegroup",
+		Name:        "wiping_bytes",
+		Help:        "LVM VG bytes held by logical volumes being wiped before removal",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, []string{"device_class"})
+	metrics.Registry.MustRegister(wipingBytes)
+
+	wipeFailed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "volumegroup",
+		Name:        "wipe_failed_bytes",
+		Help:        "LVM VG bytes held by logical volumes whose wiping has failed and is being retried",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, []string{"device_class"})
+	metrics.Registry.MustRegister(wipeFailed)
+
 	return &metricsExporter{
 		Client:         mgr.GetClient(),
+		apiReader:      mgr.GetAPIReader(),
 		nodeName:       nodeName,
+		exposure:       exposure,
 		vgService:      proto.NewVGServiceClient(conn),
 		availableBytes: availableBytes,
 		sizeBytes:      sizeBytes,
+		wipingBytes:    wipingBytes,
+		wipeFailed:     wipeFailed,
 	}
 }
 
@@ -81,10 +131,16 @@ func (m *metricsExporter) Start(ctx context.Context) error {
 			case met := <-metricsCh:
 				m.availableBytes.WithLabelValues(met.DeviceClass).Set(float64(met.FreeBytes))
 				m.sizeBytes.WithLabelValues(met.DeviceClass).Set(float64(met.SizeBytes))
+				m.wipingBytes.WithLabelValues(met.DeviceClass).Set(float64(met.WipingBytes))
+				m.wipeFailed.WithLabelValues(met.DeviceClass).Set(float64(met.WipeFailedBytes))
 			}
 		}
 	}()
 
+	if m.exposure.TopolvmNode {
+		go m.heartbeat(ctx)
+	}
+
 	wc, err := m.vgService.Watch(ctx, &proto.Empty{})
 	if err != nil {
 		return err
@@ -112,9 +168,11 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 
 		for _, item := range res.Items {
 			ch <- NodeMetrics{
-				DeviceClass: item.DeviceClass,
-				FreeBytes:   item.FreeBytes,
-				SizeBytes:   item.SizeBytes,
+				DeviceClass:     item.DeviceClass,
+				FreeBytes:       item.FreeBytes,
+				SizeBytes:       item.SizeBytes,
+				WipingBytes:     item.WipingBytes,
+				WipeFailedBytes: item.WipeFailedBytes,
 			}
 		}
 
@@ -141,14 +199,170 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 			node2.Finalizers = append(node2.Finalizers, topolvm.NodeFinalizer)
 		}
 
-		node2.Annotations[topolvm.CapacityKeyPrefix+topolvm.DefaultDeviceClassAnnotationName] = strconv.FormatUint(res.FreeBytes, 10)
-		for _, item := range res.Items {
-			node2.Annotations[topolvm.CapacityKeyPrefix+item.DeviceClass] = strconv.FormatUint(item.FreeBytes, 10)
+		if m.exposure.Annotations {
+			node2.Annotations[topolvm.CapacityKeyPrefix+topolvm.DefaultDeviceClassAnnotationName] = strconv.FormatUint(res.FreeBytes, 10)
+			node2.Annotations[topolvm.TotalCapacityKeyPrefix+topolvm.DefaultDeviceClassAnnotationName] = strconv.FormatUint(res.SizeBytes, 10)
+			for _, item := range res.Items {
+				node2.Annotations[topolvm.CapacityKeyPrefix+item.DeviceClass] = strconv.FormatUint(item.FreeBytes, 10)
+				node2.Annotations[topolvm.TotalCapacityKeyPrefix+item.DeviceClass] = strconv.FormatUint(item.SizeBytes, 10)
+			}
 		}
 		if err := m.Patch(ctx, node2, client.MergeFrom(&node)); err != nil {
 			return err
 		}
+
+		if m.exposure.ExtendedResources {
+			if err := m.updateNodeResources(ctx, node2, res); err != nil {
+				return err
+			}
+		}
+		if m.exposure.TopolvmNode {
+			if err := m.updateTopolvmNode(ctx, node2, res); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+// updateNodeResources updates the capacity extended resources in the status of the node.
+//
+// kube-scheduler subtracts the requests of the pods on the node from the allocatable resources,
+// while VG free space already excludes the volumes of running pods.  To avoid counting them
+// twice, the capacity requested by the running pods is added to the allocatable resources.
+func (m *metricsExporter) updateNodeResources(ctx context.Context, node *corev1.Node, res *proto.WatchResponse) error {
+	var pods corev1.PodList
+	if err := m.apiReader.List(ctx, &pods, client.MatchingFields{"spec.nodeName": m.nodeName}); err != nil {
+		return err
+	}
+	running := runningPodRequests(pods.Items)
+
+	node2 := node.DeepCopy()
+	if node2.Status.Capacity == nil {
+		node2.Status.Capacity = corev1.ResourceList{}
+	}
+	if node2.Status.Allocatable == nil {
+		node2.Status.Allocatable = corev1.ResourceList{}
+	}
+	setResource := func(dc string, free, size uint64) {
+		name := corev1.ResourceName(topolvm.CapacityResourcePrefix + dc)
+		allocatable := free + uint64(running[name])
+		if allocatable > size {
+			allocatable = size
+		}
+		node2.Status.Capacity[name] = *resource.NewQuantity(int64(size), resource.BinarySI)
+		node2.Status.Allocatable[name] = *resource.NewQuantity(int64(allocatable), resource.BinarySI)
+	}
+	setResource(topolvm.DefaultDeviceClassAnnotationName, res.FreeBytes, res.SizeBytes)
+	for _, item := range res.Items {
+		setResource(item.DeviceClass, item.FreeBytes, item.SizeBytes)
+	}
+	return m.Status().Patch(ctx, node2, client.MergeFrom(node))
+}
+
+// updateTopolvmNode creates TopolvmNode for the node if not exists and updates its status.
+// TopolvmNode is owned by the node to be deleted along with it.
+func (m *metricsExporter) updateTopolvmNode(ctx context.Context, node *corev1.Node, res *proto.WatchResponse) error {
+	var tn topolvmv1.TopolvmNode
+	err := m.Get(ctx, types.NamespacedName{Name: m.nodeName}, &tn)
+	switch {
+	case apierrors.IsNotFound(err):
+		tn = topolvmv1.TopolvmNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: m.nodeName,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "v1",
+					Kind:       "Node",
+					Name:       node.Name,
+					UID:        node.UID,
+				}},
+			},
+		}
+		if err := m.Create(ctx, &tn); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+
+	tn2 := tn.DeepCopy()
+	tn2.Status = topolvmNodeStatus(res, metav1.Now())
+	return m.Status().Patch(ctx, tn2, client.MergeFrom(&tn))
+}
+
+// heartbeat updates the heartbeat time of TopolvmNode periodically
+// because lvmd sends the capacity only when it is changed.
+func (m *metricsExporter) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var tn topolvmv1.TopolvmNode
+		if err := m.Get(ctx, types.NamespacedName{Name: m.nodeName}, &tn); err != nil {
+			if !apierrors.IsNotFound(err) {
+				meLogger.Error(err, "failed to get TopolvmNode", "name", m.nodeName)
+			}
+			continue
+		}
+		tn2 := tn.DeepCopy()
+		tn2.Status.LastHeartbeatTime = metav1.Now()
+		if err := m.Status().Patch(ctx, tn2, client.MergeFrom(&tn)); err != nil {
+			meLogger.Error(err, "failed to update heartbeat of TopolvmNode", "name", m.nodeName)
+		}
+	}
+}
+
+// topolvmNodeStatus converts the response of lvmd into the status of TopolvmNode.
+func topolvmNodeStatus(res *proto.WatchResponse, now metav1.Time) topolvmv1.TopolvmNodeStatus {
+	st := topolvmv1.TopolvmNodeStatus{
+		LVMdVersion:       res.Version,
+		LastHeartbeatTime: now,
+	}
+	for _, item := range res.Items {
+		dc := topolvmv1.DeviceClassStatus{
+			Name:        item.DeviceClass,
+			Default:     item.Default,
+			SizeBytes:   int64(item.SizeBytes),
+			FreeBytes:   int64(item.FreeBytes),
+			SpareBytes:  int64(item.SpareBytes),
+			WipingBytes: int64(item.WipingBytes),
+			Health:      topolvmv1.VolumeGroupHealthy,
+		}
+		if item.Partial {
+			dc.Health = topolvmv1.VolumeGroupPartial
+		}
+		for _, pool := range item.ThinPools {
+			dc.ThinPools = append(dc.ThinPools, topolvmv1.ThinPoolStatus{
+				Name:      pool.Name,
+				SizeBytes: int64(pool.SizeBytes),
+				UsedBytes: int64(float64(pool.SizeBytes) * pool.DataPercent / 100),
+			})
+		}
+		st.DeviceClasses = append(st.DeviceClasses, dc)
+	}
+	return st
+}
+
+// runningPodRequests returns the sum of the capacity extended resources requested by running pods.
+func runningPodRequests(pods []corev1.Pod) map[corev1.ResourceName]int64 {
+	result := make(map[corev1.ResourceName]int64)
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			for name, q := range c.Resources.Requests {
+				if strings.HasPrefix(string(name), topolvm.CapacityResourcePrefix) {
+					result[name] += q.Value()
+				}
+			}
+		}
+	}
+	return result
+}
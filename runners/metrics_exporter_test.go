@@ -0,0 +1,112 @@
+package runners
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/lvmd/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunningPodRequests(t *testing.T) {
+	ssd := corev1.ResourceName(topolvm.CapacityResourcePrefix + "ssd")
+	hdd := corev1.ResourceName(topolvm.CapacityResourcePrefix + "hdd")
+	pod := func(phase corev1.PodPhase, requests ...corev1.ResourceList) corev1.Pod {
+		p := corev1.Pod{Status: corev1.PodStatus{Phase: phase}}
+		for _, r := range requests {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{
+				Resources: corev1.ResourceRequirements{Requests: r},
+			})
+		}
+		return p
+	}
+
+	pods := []corev1.Pod{
+		pod(corev1.PodRunning, corev1.ResourceList{
+			ssd:                      *resource.NewQuantity(1<<30, resource.BinarySI),
+			corev1.ResourceCPU:       resource.MustParse("1"),
+			topolvm.CapacityResource: *resource.NewQuantity(1, resource.DecimalSI),
+		}),
+		pod(corev1.PodRunning,
+			corev1.ResourceList{ssd: *resource.NewQuantity(2<<30, resource.BinarySI)},
+			corev1.ResourceList{hdd: *resource.NewQuantity(5<<30, resource.BinarySI)},
+		),
+		// The volumes of pending pods may not be created yet.
+		pod(corev1.PodPending, corev1.ResourceList{ssd: *resource.NewQuantity(4<<30, resource.BinarySI)}),
+		pod(corev1.PodSucceeded, corev1.ResourceList{hdd: *resource.NewQuantity(8<<30, resource.BinarySI)}),
+	}
+
+	expected := map[corev1.ResourceName]int64{
+		ssd: 3 << 30,
+		hdd: 5 << 30,
+	}
+	actual := runningPodRequests(pods)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestTopolvmNodeStatus(t *testing.T) {
+	now := metav1.Now()
+	res := &proto.WatchResponse{
+		Version: "0.10.3",
+		Items: []*proto.WatchItem{
+			{
+				DeviceClass: "ssd",
+				Default:     true,
+				SizeBytes:   100 << 30,
+				FreeBytes:   40 << 30,
+				SpareBytes:  10 << 30,
+				WipingBytes: 1 << 30,
+				ThinPools: []*proto.ThinPoolItem{
+					{Name: "pool0", SizeBytes: 20 << 30, DataPercent: 25},
+				},
+			},
+			{
+				DeviceClass: "hdd",
+				SizeBytes:   1 << 40,
+				FreeBytes:   1 << 40,
+				Partial:     true,
+			},
+		},
+	}
+
+	expected := topolvmv1.TopolvmNodeStatus{
+		LVMdVersion:       "0.10.3",
+		LastHeartbeatTime: now,
+		DeviceClasses: []topolvmv1.DeviceClassStatus{
+			{
+				Name:        "ssd",
+				Default:     true,
+				SizeBytes:   100 << 30,
+				FreeBytes:   40 << 30,
+				SpareBytes:  10 << 30,
+				WipingBytes: 1 << 30,
+				Health:      topolvmv1.VolumeGroupHealthy,
+				ThinPools: []topolvmv1.ThinPoolStatus{
+					{Name: "pool0", SizeBytes: 20 << 30, UsedBytes: 5 << 30},
+				},
+			},
+			{
+				Name:      "hdd",
+				SizeBytes: 1 << 40,
+				FreeBytes: 1 << 40,
+				Health:    topolvmv1.VolumeGroupPartial,
+			},
+		},
+	}
+	st := topolvmNodeStatus(res, now)
+	if !reflect.DeepEqual(st, expected) {
+		t.Errorf("unexpected status: expected=%#v, actual=%#v", expected, st)
+	}
+	if dc := st.DeviceClass(""); dc == nil || dc.Name != "ssd" {
+		t.Errorf("ssd should be the default device-class: %#v", dc)
+	}
+	if dc := st.DeviceClass("nvme"); dc != nil {
+		t.Errorf("unknown device-class should not be found: %#v", dc)
+	}
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Explanation is the response of "/explain".
+// It shows how the extender would filter and score nodes for a pod.
+type Explanation struct {
+	// Pod is the namespaced name of the pod.
+	Pod string `json:"pod"`
+	// Requested is the capacity requested by the pod per device-class in bytes.
+	Requested map[string]int64 `json:"requested"`
+	// Nodes is the decision for each node.
+	Nodes []NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation is the decision for a node.
+type NodeExplanation struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+	// FailedReason is the reason why the node is filtered out.  Empty if the node passes the filter.
+	FailedReason string `json:"failedReason,omitempty"`
+	// Score is the score of the node.
+	Score int `json:"score"`
+	// DeviceClasses is the breakdown of the score per device-class.
+	DeviceClasses []DeviceClassExplanation `json:"deviceClasses,omitempty"`
+}
+
+// DeviceClassExplanation is the capacity and the score of a device-class on a node.
+type DeviceClassExplanation struct {
+	// DeviceClass is the name of the device-class.
+	DeviceClass string `json:"deviceClass"`
+	// Requested is the capacity requested by the pod in bytes.
+	Requested int64 `json:"requested"`
+	// Capacity is the value of the capacity annotation of the node.
+	Capacity string `json:"capacity,omitempty"`
+	// Reserved is the capacity reserved for other pods in bytes.
+	Reserved int64 `json:"reserved,omitempty"`
+	// Available is the capacity not reserved for other pods in bytes.
+	Available uint64 `json:"available"`
+	// Total is the size of the volume group in bytes, or zero if unknown.
+	Total uint64 `json:"total,omitempty"`
+	// Strategy is the scoring strategy of the device-class.
+	Strategy StrategyType `json:"strategy"`
+	// Score is the score of the device-class.
+	Score int `json:"score"`
+}
+
+func (s scheduler) explain(w http.ResponseWriter, r *http.Request) {
+	if s.client == nil {
+		http.Error(w, "explain is not enabled", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := types.NamespacedName{
+		Namespace: r.URL.Query().Get("namespace"),
+		Name:      r.URL.Query().Get("name"),
+	}
+	if name.Namespace == "" || name.Name == "" {
+		http.Error(w, "namespace and name must be given", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	pod := &corev1.Pod{}
+	if err := s.client.Get(ctx, name, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "pod not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nodes := &corev1.NodeList{}
+	if err := s.client.List(ctx, nodes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	candidates := nodes.Items
+	if s.nodes.lookup() {
+		candidates = s.lookupNodes(nodes.Items)
+	}
+
+	var reserved map[string]map[string]int64
+	if s.reservations != nil {
+		reserved = s.reservations.Reserved(pod.UID)
+	}
+	result := s.explainPod(pod, candidates, reserved)
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// lookupNodes replaces nodes with the ones in the cache.
+// The nodes not found in the cache are left without the capacity annotations.
+func (s scheduler) lookupNodes(nodes []corev1.Node) []corev1.Node {
+	result := make([]corev1.Node, len(nodes))
+	for i, n := range nodes {
+		cached, _ := s.nodes.Get([]string{n.Name})
+		if len(cached) == 0 {
+			result[i] = corev1.Node{}
+			result[i].Name = n.Name
+			continue
+		}
+		result[i] = cached[0]
+	}
+	return result
+}
+
+func (s scheduler) explainPod(pod *corev1.Pod, nodes []corev1.Node, reserved map[string]map[string]int64) *Explanation {
+	requested := extractRequestedSize(pod)
+	dcs := make([]string, 0, len(requested))
+	for dc := range requested {
+		dcs = append(dcs, dc)
+	}
+	sort.Strings(dcs)
+
+	result := &Explanation{
+		Pod:       podName(pod),
+		Requested: requested,
+		Nodes:     make([]NodeExplanation, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		ne := NodeExplanation{Name: node.Name}
+		if len(requested) != 0 {
+			if failure := filterNode(node, requested, reserved[node.Name]); failure != nil {
+				ne.FailedReason = failure.String()
+			}
+			ne.Score = scoreNode(node, requested, s.defaultDivisor, s.divisors, s.scoring, reserved[node.Name])
+		}
+		for _, dc := range dcs {
+			de := DeviceClassExplanation{
+				DeviceClass: dc,
+				Requested:   requested[dc],
+				Capacity:    node.Annotations[topolvm.CapacityKeyPrefix+dc],
+				Reserved:    reserved[node.Name][dc],
+				Strategy:    s.scoring.strategy(dc).Type,
+			}
+			if de.Strategy == "" {
+				de.Strategy = StrategySpreadLog2
+			}
+			if free, total, ok := nodeCapacity(node, dc, de.Reserved); ok {
+				de.Available = free
+				de.Total = total
+				de.Score = s.scoring.strategy(dc).score(free, total, de.Requested, divisorOf(dc, s.defaultDivisor, s.divisors))
+			}
+			ne.DeviceClasses = append(ne.DeviceClasses, de)
+		}
+		result.Nodes = append(result.Nodes, ne)
+	}
+	return result
+}
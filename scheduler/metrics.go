@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "topolvm"
+
+// extenderMetrics is a set of metrics of the scheduler extender.
+type extenderMetrics struct {
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	filteredNodes *prometheus.CounterVec
+}
+
+func newExtenderMetrics(registry prometheus.Registerer) *extenderMetrics {
+	m := &extenderMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "scheduler",
+			Name:      "requests_total",
+			Help:      "The number of requests to the scheduler extender per verb and status code",
+		}, []string{"verb", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "scheduler",
+			Name:      "request_duration_seconds",
+			Help:      "The latency of requests to the scheduler extender per verb",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+		}, []string{"verb"}),
+		filteredNodes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "scheduler",
+			Name:      "filtered_nodes_total",
+			Help:      "The number of nodes filtered out by the predicate verb per device-class and reason",
+		}, []string{"device_class", "reason"}),
+	}
+	registry.MustRegister(m.requests, m.duration, m.filteredNodes)
+	return m
+}
+
+// statusRecorder records the status code written to http.ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// observe serves the request with h and records the count and the latency for the verb.
+func (m *extenderMetrics) observe(verb string, h http.HandlerFunc, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	h(rec, r)
+	m.requests.WithLabelValues(verb, strconv.Itoa(rec.code)).Inc()
+	m.duration.WithLabelValues(verb).Observe(time.Since(start).Seconds())
+}
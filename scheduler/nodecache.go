@@ -0,0 +1,248 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+var nodeCacheLogger = ctrl.Log.WithName("node-cache")
+
+// NodeCache keeps the capacity annotations of nodes to serve requests with NodeNames,
+// i.e. when the extender is configured with nodeCacheCapable.
+//
+// Only the names and the capacity annotations of nodes are kept to save memory.
+// If the cache is built from TopolvmNodes, their status is kept as the capacity
+// annotations, and the cache is used for requests with Nodes too.  Nodes whose
+// TopolvmNode has a stale heartbeat are returned as having no free space.
+type NodeCache struct {
+	mu           sync.RWMutex
+	nodes        map[string]*corev1.Node
+	topolvmNodes bool
+	heartbeats   map[string]time.Time
+}
+
+// NewNodeCache creates an empty NodeCache for Nodes.
+func NewNodeCache() *NodeCache {
+	return &NodeCache{
+		nodes: make(map[string]*corev1.Node),
+	}
+}
+
+// NewTopolvmNodeCache creates an empty NodeCache for TopolvmNodes.
+func NewTopolvmNodeCache() *NodeCache {
+	return &NodeCache{
+		nodes:        make(map[string]*corev1.Node),
+		topolvmNodes: true,
+		heartbeats:   make(map[string]time.Time),
+	}
+}
+
+// lookup returns true if the capacity of nodes should be looked up from the cache
+// even for requests with Nodes.
+func (c *NodeCache) lookup() bool {
+	return c != nil && c.topolvmNodes
+}
+
+// Get returns the cached nodes in the order of names.
+// The names of nodes not found in the cache are returned as missing.
+func (c *NodeCache) Get(names []string) (nodes []corev1.Node, missing []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	nodes = make([]corev1.Node, 0, len(names))
+	for _, name := range names {
+		n, ok := c.nodes[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if hb, ok := c.heartbeats[name]; ok && now.Sub(hb) > topolvmv1.HeartbeatTimeout {
+			n = withoutFreeSpace(n)
+		}
+		nodes = append(nodes, *n)
+	}
+	return nodes, missing
+}
+
+func (c *NodeCache) update(node *corev1.Node) {
+	n := &corev1.Node{}
+	n.Name = node.Name
+	for k, v := range node.Annotations {
+		if !strings.HasPrefix(k, topolvm.CapacityKeyPrefix) && !strings.HasPrefix(k, topolvm.TotalCapacityKeyPrefix) {
+			continue
+		}
+		if n.Annotations == nil {
+			n.Annotations = make(map[string]string)
+		}
+		n.Annotations[k] = v
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nodes[node.Name] = n
+}
+
+// nodeFromTopolvmNode converts the status of TopolvmNode into the capacity annotations of Node.
+// The free space of unhealthy device-classes is zero.
+func nodeFromTopolvmNode(tn *topolvmv1.TopolvmNode) *corev1.Node {
+	n := &corev1.Node{}
+	n.Name = tn.Name
+	n.Annotations = make(map[string]string)
+	for _, dc := range tn.Status.DeviceClasses {
+		names := []string{dc.Name}
+		if dc.Default {
+			names = append(names, topolvm.DefaultDeviceClassAnnotationName)
+		}
+		for _, name := range names {
+			n.Annotations[topolvm.CapacityKeyPrefix+name] = strconv.FormatInt(dc.AvailableBytes(), 10)
+			n.Annotations[topolvm.TotalCapacityKeyPrefix+name] = strconv.FormatInt(dc.SizeBytes, 10)
+		}
+	}
+	return n
+}
+
+// withoutFreeSpace returns a copy of the cached node whose free space is zero.
+func withoutFreeSpace(node *corev1.Node) *corev1.Node {
+	n := node.DeepCopy()
+	for k := range n.Annotations {
+		if strings.HasPrefix(k, topolvm.CapacityKeyPrefix) {
+			n.Annotations[k] = "0"
+		}
+	}
+	return n
+}
+
+func (c *NodeCache) delete(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, name)
+	delete(c.heartbeats, name)
+}
+
+// NodeHandler returns an event handler that keeps the cache up to date.
+func (c *NodeCache) NodeHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if node, ok := obj.(*corev1.Node); ok {
+				c.update(node)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if node, ok := obj.(*corev1.Node); ok {
+				c.update(node)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch o := obj.(type) {
+			case *corev1.Node:
+				c.delete(o.Name)
+			case toolscache.DeletedFinalStateUnknown:
+				// The key of cluster-scoped objects is the name.
+				c.delete(o.Key)
+			}
+		},
+	}
+}
+
+// TopolvmNodeHandler returns an event handler that keeps the cache up to date with TopolvmNodes.
+func (c *NodeCache) TopolvmNodeHandler() toolscache.ResourceEventHandler {
+	set := func(tn *topolvmv1.TopolvmNode) {
+		n := nodeFromTopolvmNode(tn)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.nodes[n.Name] = n
+		c.heartbeats[n.Name] = tn.Status.LastHeartbeatTime.Time
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if tn, ok := obj.(*topolvmv1.TopolvmNode); ok {
+				set(tn)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if tn, ok := obj.(*topolvmv1.TopolvmNode); ok {
+				set(tn)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch o := obj.(type) {
+			case *topolvmv1.TopolvmNode:
+				c.delete(o.Name)
+			case toolscache.DeletedFinalStateUnknown:
+				c.delete(o.Key)
+			}
+		},
+	}
+}
+
+// StartNodeCache creates a NodeCache and starts watching Nodes.
+// It returns after the cache of Nodes is synced.
+func StartNodeCache(ctx context.Context, cfg *rest.Config) (*NodeCache, error) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	informer, err := c.GetInformer(ctx, &corev1.Node{})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := NewNodeCache()
+	informer.AddEventHandler(nodes.NodeHandler())
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			nodeCacheLogger.Error(err, "failed to watch Nodes")
+		}
+	}()
+	if !c.WaitForCacheSync(ctx) {
+		return nil, errors.New("failed to sync the cache of Nodes")
+	}
+	return nodes, nil
+}
+
+// StartTopolvmNodeCache creates a NodeCache and starts watching TopolvmNodes.
+// It returns after the cache of TopolvmNodes is synced.
+func StartTopolvmNodeCache(ctx context.Context, cfg *rest.Config) (*NodeCache, error) {
+	scheme := runtime.NewScheme()
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	informer, err := c.GetInformer(ctx, &topolvmv1.TopolvmNode{})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := NewTopolvmNodeCache()
+	informer.AddEventHandler(nodes.TopolvmNodeHandler())
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			nodeCacheLogger.Error(err, "failed to watch TopolvmNodes")
+		}
+	}()
+	if !c.WaitForCacheSync(ctx) {
+		return nil, errors.New("failed to sync the cache of TopolvmNodes")
+	}
+	return nodes, nil
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestNodeCache(t *testing.T) {
+	c := NewNodeCache()
+	h := c.NodeHandler()
+
+	node1 := testNode("10.1.1.1", 2, 10, 10)
+	node1.Labels = map[string]string{"foo": "bar"}
+	node1.Annotations["foo"] = "bar"
+	node2 := testNode("10.1.1.2", 5, 10, 10)
+	h.OnAdd(&node1)
+	h.OnAdd(&node2)
+
+	nodes, missing := c.Get([]string{"10.1.1.2", "10.1.1.3", "10.1.1.1"})
+	if len(nodes) != 2 || nodes[0].Name != "10.1.1.2" || nodes[1].Name != "10.1.1.1" {
+		t.Fatalf("unexpected nodes: %v", nodes)
+	}
+	if !reflect.DeepEqual(missing, []string{"10.1.1.3"}) {
+		t.Errorf("unexpected missing nodes: %v", missing)
+	}
+	if nodes[1].Labels != nil || len(nodes[1].Annotations) != 3 {
+		t.Errorf("only the capacity annotations should be cached: %v", nodes[1].ObjectMeta)
+	}
+
+	updated := testNode("10.1.1.1", 1, 10, 10)
+	h.OnUpdate(&node1, &updated)
+	nodes, _ = c.Get([]string{"10.1.1.1"})
+	if nodes[0].Annotations[topolvm.CapacityKeyPrefix+"ssd"] != updated.Annotations[topolvm.CapacityKeyPrefix+"ssd"] {
+		t.Errorf("the node should be updated: %v", nodes[0].Annotations)
+	}
+
+	h.OnDelete(&updated)
+	h.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "10.1.1.2", Obj: &node2})
+	nodes, missing = c.Get([]string{"10.1.1.1", "10.1.1.2"})
+	if len(nodes) != 0 || len(missing) != 2 {
+		t.Errorf("the nodes should be deleted: nodes=%v, missing=%v", nodes, missing)
+	}
+}
+
+func TestTopolvmNodeCache(t *testing.T) {
+	c := NewTopolvmNodeCache()
+	h := c.TopolvmNodeHandler()
+
+	tn := &topolvmv1.TopolvmNode{}
+	tn.Name = "10.1.1.1"
+	tn.Status.LastHeartbeatTime = metav1.Now()
+	tn.Status.DeviceClasses = []topolvmv1.DeviceClassStatus{
+		{Name: "ssd", Default: true, SizeBytes: 10 << 30, FreeBytes: 2 << 30, Health: topolvmv1.VolumeGroupHealthy},
+		{Name: "hdd", SizeBytes: 100 << 30, FreeBytes: 50 << 30, Health: topolvmv1.VolumeGroupPartial},
+	}
+	h.OnAdd(tn)
+
+	nodes, missing := c.Get([]string{"10.1.1.1", "10.1.1.2"})
+	if len(nodes) != 1 || !reflect.DeepEqual(missing, []string{"10.1.1.2"}) {
+		t.Fatalf("unexpected nodes: nodes=%v, missing=%v", nodes, missing)
+	}
+	expected := map[string]string{
+		topolvm.CapacityKeyPrefix + "ssd":                                         strconv.Itoa(2 << 30),
+		topolvm.TotalCapacityKeyPrefix + "ssd":                                    strconv.Itoa(10 << 30),
+		topolvm.CapacityKeyPrefix + topolvm.DefaultDeviceClassAnnotationName:      strconv.Itoa(2 << 30),
+		topolvm.TotalCapacityKeyPrefix + topolvm.DefaultDeviceClassAnnotationName: strconv.Itoa(10 << 30),
+		topolvm.CapacityKeyPrefix + "hdd":                                         "0",
+		topolvm.TotalCapacityKeyPrefix + "hdd":                                    strconv.Itoa(100 << 30),
+	}
+	if !reflect.DeepEqual(nodes[0].Annotations, expected) {
+		t.Errorf("unexpected annotations: %v", nodes[0].Annotations)
+	}
+	if !c.lookup() || NewNodeCache().lookup() {
+		t.Error("only the cache of TopolvmNodes should be used for requests with Nodes")
+	}
+
+	stale := tn.DeepCopy()
+	stale.Status.LastHeartbeatTime = metav1.NewTime(time.Now().Add(-topolvmv1.HeartbeatTimeout - time.Minute))
+	h.OnUpdate(tn, stale)
+	nodes, _ = c.Get([]string{"10.1.1.1"})
+	if nodes[0].Annotations[topolvm.CapacityKeyPrefix+"ssd"] != "0" || nodes[0].Annotations[topolvm.TotalCapacityKeyPrefix+"ssd"] != strconv.Itoa(10<<30) {
+		t.Errorf("the node with stale heartbeat should have no free space: %v", nodes[0].Annotations)
+	}
+
+	h.OnDelete(stale)
+	if nodes, _ := c.Get([]string{"10.1.1.1"}); len(nodes) != 0 {
+		t.Errorf("the node should be deleted: %v", nodes)
+	}
+}
+
+func TestToNodeNames(t *testing.T) {
+	result := ExtenderFilterResult{
+		Nodes: &corev1.NodeList{
+			Items: []corev1.Node{testNode("10.1.1.1", 2, 10, 10)},
+		},
+		FailedNodes: FailedNodesMap{"10.1.1.2": "out of VG free space"},
+	}
+
+	converted := toNodeNames(result, []string{"10.1.1.3"}, false)
+	if converted.Nodes != nil || !reflect.DeepEqual(*converted.NodeNames, []string{"10.1.1.1"}) {
+		t.Errorf("unexpected NodeNames: %#v", converted)
+	}
+	expected := FailedNodesMap{"10.1.1.2": "out of VG free space", "10.1.1.3": "node not found"}
+	if !reflect.DeepEqual(converted.FailedNodes, expected) {
+		t.Errorf("unexpected FailedNodes: %v", converted.FailedNodes)
+	}
+
+	result = ExtenderFilterResult{
+		Nodes: &corev1.NodeList{
+			Items: []corev1.Node{testNode("10.1.1.1", 2, 10, 10)},
+		},
+	}
+	converted = toNodeNames(result, []string{"10.1.1.3"}, true)
+	if !reflect.DeepEqual(*converted.NodeNames, []string{"10.1.1.1", "10.1.1.3"}) || len(converted.FailedNodes) != 0 {
+		t.Errorf("missing nodes should pass: %#v", converted)
+	}
+}
@@ -0,0 +1,238 @@
+//go:build kubescheduler
+// +build kubescheduler
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+// PluginName is the name of the scheduler framework plugin for TopoLVM.
+const PluginName = "TopoLVM"
+
+const defaultReservationTTL = time.Minute
+
+// PluginArgs is the arguments of the scheduler framework plugin.
+type PluginArgs struct {
+	// DefaultDivisor is the default divisor value for node scoring.
+	DefaultDivisor float64 `json:"defaultDivisor,omitempty"`
+	// Divisors is a mapping between device-class names and their divisors.
+	Divisors map[string]float64 `json:"divisors,omitempty"`
+	// Scoring configures the scoring strategies of device-classes.
+	Scoring *ScoringConfig `json:"scoring,omitempty"`
+	// ReservationTTL is the duration to keep the capacity reserved for pods until their volumes are created.
+	ReservationTTL metav1.Duration `json:"reservationTTL,omitempty"`
+	// TopolvmNodes reads the capacity of nodes from TopolvmNodes instead of the annotations of Node.
+	TopolvmNodes bool `json:"topolvmNodes,omitempty"`
+	// Kubeconfig is the path to kubeconfig to watch LogicalVolumes.
+	// If empty, the in-cluster configuration is used.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+}
+
+// plugin implements the scheduler framework plugin for TopoLVM.
+// It filters and scores nodes in the same way as the extender, and reserves the capacity
+// on the node chosen in the scheduling cycle.
+type plugin struct {
+	defaultDivisor float64
+	divisors       map[string]float64
+	scoring        *ScoringConfig
+	reservations   *ReservationCache
+	nodes          *NodeCache
+	handle         framework.Handle
+}
+
+var _ framework.PreFilterPlugin = &plugin{}
+var _ framework.FilterPlugin = &plugin{}
+var _ framework.ScorePlugin = &plugin{}
+var _ framework.ReservePlugin = &plugin{}
+
+const stateKey framework.StateKey = PluginName
+
+// preFilterState is computed at PreFilter and used at Filter and Score.
+type preFilterState struct {
+	requested map[string]int64
+	reserved  map[string]map[string]int64
+}
+
+// Clone implements framework.StateData.  The state is not modified after PreFilter.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// NewPlugin creates the scheduler framework plugin for TopoLVM.
+func NewPlugin(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	args := PluginArgs{
+		DefaultDivisor: 1,
+		ReservationTTL: metav1.Duration{Duration: defaultReservationTTL},
+	}
+	if err := frameworkruntime.DecodeInto(obj, &args); err != nil {
+		return nil, err
+	}
+	if args.DefaultDivisor <= 0 {
+		return nil, fmt.Errorf("invalid default divisor: %f", args.DefaultDivisor)
+	}
+	for _, divisor := range args.Divisors {
+		if divisor <= 0 {
+			return nil, fmt.Errorf("invalid divisor: %f", divisor)
+		}
+	}
+	if err := args.Scoring.Validate(); err != nil {
+		return nil, err
+	}
+	if args.ReservationTTL.Duration <= 0 {
+		return nil, fmt.Errorf("invalid reservation TTL: %v", args.ReservationTTL.Duration)
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", args.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	reservations, err := StartReservationCache(context.Background(), cfg, args.ReservationTTL.Duration)
+	if err != nil {
+		return nil, err
+	}
+	var nodes *NodeCache
+	if args.TopolvmNodes {
+		nodes, err = StartTopolvmNodeCache(context.Background(), cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &plugin{
+		defaultDivisor: args.DefaultDivisor,
+		divisors:       args.Divisors,
+		scoring:        args.Scoring,
+		reservations:   reservations,
+		nodes:          nodes,
+		handle:         handle,
+	}, nil
+}
+
+// Name implements framework.Plugin.
+func (p *plugin) Name() string {
+	return PluginName
+}
+
+// PreFilter implements framework.PreFilterPlugin.
+func (p *plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	s := &preFilterState{
+		requested: extractRequestedSize(pod),
+	}
+	if len(s.requested) != 0 {
+		s.reserved = p.reservations.Reserved(pod.UID)
+	}
+	state.Write(stateKey, s)
+	return nil
+}
+
+// PreFilterExtensions implements framework.PreFilterPlugin.
+func (p *plugin) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+func getPreFilterState(state *framework.CycleState) (*preFilterState, error) {
+	c, err := state.Read(stateKey)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("invalid state of %s: %T", PluginName, c)
+	}
+	return s, nil
+}
+
+// Filter implements framework.FilterPlugin.
+func (p *plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if len(s.requested) == 0 {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	node, ok := p.lookupNode(node)
+	if !ok {
+		return framework.NewStatus(framework.Unschedulable, reasonNodeNotFound)
+	}
+	if failure := filterNode(*node, s.requested, s.reserved[node.Name]); failure != nil {
+		return framework.NewStatus(framework.Unschedulable, failure.String())
+	}
+	return nil
+}
+
+// Score implements framework.ScorePlugin.
+// The score of the extender is scaled to framework.MaxNodeScore.
+func (p *plugin) Score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	if len(s.requested) == 0 {
+		return 0, nil
+	}
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return 0, framework.NewStatus(framework.Error, "node not found")
+	}
+	node, ok := p.lookupNode(node)
+	if !ok {
+		return 0, nil
+	}
+
+	score := scoreNode(*node, s.requested, p.defaultDivisor, p.divisors, p.scoring, s.reserved[nodeName])
+	return int64(score) * framework.MaxNodeScore / maxScore, nil
+}
+
+// lookupNode returns the node with the capacity read from TopolvmNode if configured.
+func (p *plugin) lookupNode(node *corev1.Node) (*corev1.Node, bool) {
+	if !p.nodes.lookup() {
+		return node, true
+	}
+	cached, _ := p.nodes.Get([]string{node.Name})
+	if len(cached) == 0 {
+		return nil, false
+	}
+	return &cached[0], true
+}
+
+// ScoreExtensions implements framework.ScorePlugin.
+func (p *plugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve implements framework.ReservePlugin.
+func (p *plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if len(s.requested) == 0 {
+		return nil
+	}
+	p.reservations.Reserve(pod.UID, []string{nodeName}, s.requested)
+	return nil
+}
+
+// Unreserve implements framework.ReservePlugin.
+func (p *plugin) Unreserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	p.reservations.Release(pod.UID)
+}
@@ -0,0 +1,120 @@
+//go:build kubescheduler
+// +build kubescheduler
+
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+type fakeSharedLister struct {
+	nodeInfos framework.NodeInfoLister
+}
+
+func (l fakeSharedLister) NodeInfos() framework.NodeInfoLister {
+	return l.nodeInfos
+}
+
+type fakeHandle struct {
+	framework.Handle
+	lister framework.SharedLister
+}
+
+func (h fakeHandle) SnapshotSharedLister() framework.SharedLister {
+	return h.lister
+}
+
+func testPluginPod(uid types.UID, ssdGb int64) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: uid,
+		},
+	}
+	if ssdGb != 0 {
+		pod.Annotations = map[string]string{
+			topolvm.CapacityKeyPrefix + "ssd": strconv.FormatInt(ssdGb<<30, 10),
+		}
+	}
+	return pod
+}
+
+func TestPlugin(t *testing.T) {
+	node1 := testNode("10.1.1.1", 2, 10, 10)
+	node2 := testNode("10.1.1.2", 5, 10, 10)
+	p := &plugin{
+		defaultDivisor: 1,
+		reservations:   NewReservationCache(time.Minute),
+		handle: fakeHandle{
+			lister: fakeSharedLister{fake.NewNodeInfoLister([]*corev1.Node{&node1, &node2})},
+		},
+	}
+	ctx := context.Background()
+
+	filter := func(state *framework.CycleState, pod *corev1.Pod, node corev1.Node) *framework.Status {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(&node)
+		return p.Filter(ctx, state, pod, nodeInfo)
+	}
+
+	pod1 := testPluginPod("pod1", 3)
+	state := framework.NewCycleState()
+	if st := p.PreFilter(ctx, state, pod1); !st.IsSuccess() {
+		t.Fatal("PreFilter failed:", st.AsError())
+	}
+	if st := filter(state, pod1, node1); st.Code() != framework.Unschedulable {
+		t.Error("node1 should be filtered out:", st.Code())
+	}
+	if st := filter(state, pod1, node2); !st.IsSuccess() {
+		t.Error("node2 should not be filtered out:", st.AsError())
+	}
+	score, st := p.Score(ctx, state, pod1, node2.Name)
+	if !st.IsSuccess() {
+		t.Fatal("Score failed:", st.AsError())
+	}
+	if score != 20 {
+		t.Error("unexpected score:", score)
+	}
+	if st := p.Reserve(ctx, state, pod1, node2.Name); !st.IsSuccess() {
+		t.Fatal("Reserve failed:", st.AsError())
+	}
+
+	// 3 GiB of node2 is reserved for pod1.
+	pod2 := testPluginPod("pod2", 3)
+	state2 := framework.NewCycleState()
+	p.PreFilter(ctx, state2, pod2)
+	if st := filter(state2, pod2, node2); st.Code() != framework.Unschedulable {
+		t.Error("node2 should be filtered out for pod2:", st.Code())
+	}
+	score, _ = p.Score(ctx, state2, pod2, node2.Name)
+	if score != 10 {
+		t.Error("unexpected score with the reservation:", score)
+	}
+
+	p.Unreserve(ctx, state, pod1, node2.Name)
+	state2 = framework.NewCycleState()
+	p.PreFilter(ctx, state2, pod2)
+	if st := filter(state2, pod2, node2); !st.IsSuccess() {
+		t.Error("node2 should not be filtered out after Unreserve:", st.AsError())
+	}
+
+	// Pods without TopoLVM volumes are not affected.
+	pod3 := testPluginPod("pod3", 0)
+	state3 := framework.NewCycleState()
+	p.PreFilter(ctx, state3, pod3)
+	if st := filter(state3, pod3, node1); !st.IsSuccess() {
+		t.Error("pod3 should not be filtered:", st.AsError())
+	}
+	if score, _ := p.Score(ctx, state3, pod3, node1.Name); score != 0 {
+		t.Error("unexpected score for pod3:", score)
+	}
+}
@@ -11,21 +11,44 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-func filterNodes(nodes corev1.NodeList, requested map[string]int64) ExtenderFilterResult {
+// Reasons why nodes are filtered out.
+const (
+	reasonNoAnnotation  = "no capacity annotation"
+	reasonBadAnnotation = "bad capacity annotation"
+	reasonOutOfSpace    = "out of VG free space"
+	reasonNodeNotFound  = "node not found"
+)
+
+// filterFailure describes why a node is filtered out.
+type filterFailure struct {
+	deviceClass string
+	reason      string
+	value       string
+}
+
+// String returns the message reported to kube-scheduler.
+func (f *filterFailure) String() string {
+	if f.value != "" {
+		return f.reason + ": " + f.value
+	}
+	return f.reason
+}
+
+func filterNodes(nodes corev1.NodeList, requested map[string]int64, reserved map[string]map[string]int64) (ExtenderFilterResult, map[string]*filterFailure) {
 	if len(requested) == 0 {
 		return ExtenderFilterResult{
 			Nodes: &nodes,
-		}
+		}, nil
 	}
 
-	failedNodes := make([]string, len(nodes.Items))
+	failures := make([]*filterFailure, len(nodes.Items))
 	wg := &sync.WaitGroup{}
 	wg.Add(len(nodes.Items))
 	for i := range nodes.Items {
-		reason := &failedNodes[i]
+		failure := &failures[i]
 		node := nodes.Items[i]
 		go func() {
-			*reason = filterNode(node, requested)
+			*failure = filterNode(node, requested, reserved[node.Name])
 			wg.Done()
 		}()
 	}
@@ -34,31 +57,46 @@ func filterNodes(nodes corev1.NodeList, requested map[string]int64) ExtenderFilt
 		Nodes:       &corev1.NodeList{},
 		FailedNodes: FailedNodesMap{},
 	}
-	for i, reason := range failedNodes {
-		if len(reason) == 0 {
+	failed := make(map[string]*filterFailure)
+	for i, failure := range failures {
+		if failure == nil {
 			result.Nodes.Items = append(result.Nodes.Items, nodes.Items[i])
 		} else {
-			result.FailedNodes[nodes.Items[i].Name] = reason
+			result.FailedNodes[nodes.Items[i].Name] = failure.String()
+			failed[nodes.Items[i].Name] = failure
 		}
 	}
-	return result
+	return result, failed
 }
 
-func filterNode(node corev1.Node, requested map[string]int64) string {
+// filterNode returns nil if the node has enough capacity for the requested device-classes.
+func filterNode(node corev1.Node, requested map[string]int64, reserved map[string]int64) *filterFailure {
 	for dc, required := range requested {
 		val, ok := node.Annotations[topolvm.CapacityKeyPrefix+dc]
 		if !ok {
-			return "no capacity annotation"
+			return &filterFailure{deviceClass: dc, reason: reasonNoAnnotation}
 		}
 		capacity, err := strconv.ParseUint(val, 10, 64)
 		if err != nil {
-			return "bad capacity annotation: " + val
+			return &filterFailure{deviceClass: dc, reason: reasonBadAnnotation, value: val}
 		}
+		capacity = subtractReserved(capacity, reserved[dc])
 		if capacity < uint64(required) {
-			return "out of VG free space"
+			return &filterFailure{deviceClass: dc, reason: reasonOutOfSpace}
 		}
 	}
-	return ""
+	return nil
+}
+
+// subtractReserved returns the capacity not reserved for other pods.
+func subtractReserved(capacity uint64, reserved int64) uint64 {
+	if reserved <= 0 {
+		return capacity
+	}
+	if uint64(reserved) >= capacity {
+		return 0
+	}
+	return capacity - uint64(reserved)
 }
 
 func extractRequestedSize(pod *corev1.Pod) map[string]int64 {
@@ -83,13 +121,96 @@ func (s scheduler) predicate(w http.ResponseWriter, r *http.Request) {
 
 	reader := http.MaxBytesReader(w, r.Body, 10<<20)
 	err := json.NewDecoder(reader).Decode(&input)
-	if err != nil || input.Nodes == nil || input.Pod == nil {
+	if err != nil || input.Pod == nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	nodes, missing, ok := s.candidateNodes(&input)
+	if !ok {
 		http.Error(w, "bad request", http.StatusBadRequest)
 		return
 	}
 
 	requested := extractRequestedSize(input.Pod)
-	result := filterNodes(*input.Nodes, requested)
+	var reserved map[string]map[string]int64
+	if s.reservations != nil {
+		reserved = s.reservations.Reserved(input.Pod.UID)
+	}
+	result, failed := filterNodes(corev1.NodeList{Items: nodes}, requested, reserved)
+	for _, f := range failed {
+		s.metrics.filteredNodes.WithLabelValues(f.deviceClass, f.reason).Inc()
+	}
+	if len(requested) != 0 {
+		for range missing {
+			s.metrics.filteredNodes.WithLabelValues("", reasonNodeNotFound).Inc()
+		}
+	}
+	if s.sampleDecision() {
+		decisionLogger.Info("filtered nodes",
+			"pod", podName(input.Pod),
+			"requested", requested,
+			"passed", len(result.Nodes.Items),
+			"failed", result.FailedNodes,
+			"missing", missing,
+		)
+	}
+	if s.reservations != nil && len(requested) != 0 {
+		approved := make([]string, len(result.Nodes.Items))
+		for i, n := range result.Nodes.Items {
+			approved[i] = n.Name
+		}
+		s.reservations.Reserve(input.Pod.UID, approved, requested)
+	}
+	switch {
+	case input.Nodes == nil:
+		result = toNodeNames(result, missing, len(requested) == 0)
+	case s.nodes.lookup():
+		result = toNodeObjects(result, input.Nodes.Items, missing, len(requested) == 0)
+	}
 	w.Header().Set("content-type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
+
+// toNodeNames converts the result for a request with NodeNames.
+// The nodes not found in the cache are filtered out unless nothing is requested.
+func toNodeNames(result ExtenderFilterResult, missing []string, passMissing bool) ExtenderFilterResult {
+	names := make([]string, 0, len(result.Nodes.Items)+len(missing))
+	for _, n := range result.Nodes.Items {
+		names = append(names, n.Name)
+	}
+	failed := result.FailedNodes
+	if passMissing {
+		names = append(names, missing...)
+	} else if len(missing) != 0 {
+		if failed == nil {
+			failed = FailedNodesMap{}
+		}
+		for _, name := range missing {
+			failed[name] = reasonNodeNotFound
+		}
+	}
+	return ExtenderFilterResult{
+		NodeNames:   &names,
+		FailedNodes: failed,
+	}
+}
+
+// toNodeObjects converts the result for a request with Nodes looked up from the cache.
+// The nodes in the result are replaced with the ones in the request.
+func toNodeObjects(result ExtenderFilterResult, nodes []corev1.Node, missing []string, passMissing bool) ExtenderFilterResult {
+	converted := toNodeNames(result, missing, passMissing)
+	passed := make(map[string]bool, len(*converted.NodeNames))
+	for _, name := range *converted.NodeNames {
+		passed[name] = true
+	}
+	items := make([]corev1.Node, 0, len(passed))
+	for _, n := range nodes {
+		if passed[n.Name] {
+			items = append(items, n)
+		}
+	}
+	return ExtenderFilterResult{
+		Nodes:       &corev1.NodeList{Items: items},
+		FailedNodes: converted.FailedNodes,
+	}
+}
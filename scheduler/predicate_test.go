@@ -113,7 +113,7 @@ func TestFilterNodes(t *testing.T) {
 	}
 
 	for _, tt := range testCases {
-		result := filterNodes(tt.nodes, tt.requested)
+		result, _ := filterNodes(tt.nodes, tt.requested, nil)
 		if len(result.Nodes.Items) != len(tt.expect.Nodes.Items) {
 			t.Fatalf("not match length of filtered NodeList: expect=%d actual=%d", len(tt.expect.Nodes.Items), len(result.Nodes.Items))
 		}
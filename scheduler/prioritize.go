@@ -5,7 +5,6 @@ import (
 	"math"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/topolvm/topolvm"
@@ -31,14 +30,9 @@ func capacityToScore(capacity uint64, divisor float64) int {
 	}
 }
 
-func scoreNodes(pod *corev1.Pod, nodes []corev1.Node, defaultDivisor float64, divisors map[string]float64) []HostPriority {
-	var dcs []string
-	for k := range pod.Annotations {
-		if strings.HasPrefix(k, topolvm.CapacityKeyPrefix) {
-			dcs = append(dcs, k[len(topolvm.CapacityKeyPrefix):])
-		}
-	}
-	if len(dcs) == 0 {
+func scoreNodes(pod *corev1.Pod, nodes []corev1.Node, defaultDivisor float64, divisors map[string]float64, scoring *ScoringConfig, reserved map[string]map[string]int64) []HostPriority {
+	requested := extractRequestedSize(pod)
+	if len(requested) == 0 {
 		return nil
 	}
 
@@ -49,7 +43,7 @@ func scoreNodes(pod *corev1.Pod, nodes []corev1.Node, defaultDivisor float64, di
 		r := &result[i]
 		item := nodes[i]
 		go func() {
-			score := scoreNode(item, dcs, defaultDivisor, divisors)
+			score := scoreNode(item, requested, defaultDivisor, divisors, scoring, reserved[item.Name])
 			*r = HostPriority{Host: item.Name, Score: score}
 			wg.Done()
 		}()
@@ -59,27 +53,36 @@ func scoreNodes(pod *corev1.Pod, nodes []corev1.Node, defaultDivisor float64, di
 	return result
 }
 
-func scoreNode(item corev1.Node, deviceClasses []string, defaultDivisor float64, divisors map[string]float64) int {
-	minScore := math.MaxInt32
-	for _, dc := range deviceClasses {
-		if val, ok := item.Annotations[topolvm.CapacityKeyPrefix+dc]; ok {
-			capacity, _ := strconv.ParseUint(val, 10, 64)
-			var divisor float64
-			if v, ok := divisors[dc]; ok {
-				divisor = v
-			} else {
-				divisor = defaultDivisor
-			}
-			score := capacityToScore(capacity, divisor)
-			if score < minScore {
-				minScore = score
-			}
+func scoreNode(item corev1.Node, requested map[string]int64, defaultDivisor float64, divisors map[string]float64, scoring *ScoringConfig, reserved map[string]int64) int {
+	scores := make(map[string]int, len(requested))
+	for dc, size := range requested {
+		free, total, ok := nodeCapacity(item, dc, reserved[dc])
+		if !ok {
+			continue
 		}
+		scores[dc] = scoring.strategy(dc).score(free, total, size, divisorOf(dc, defaultDivisor, divisors))
 	}
-	if minScore == math.MaxInt32 {
-		minScore = 0
+	return scoring.aggregate(scores)
+}
+
+// nodeCapacity returns the free space not reserved for other pods and the size of
+// the volume group of the device-class.  total is zero if it is not annotated.
+func nodeCapacity(item corev1.Node, deviceClass string, reserved int64) (free, total uint64, ok bool) {
+	val, ok := item.Annotations[topolvm.CapacityKeyPrefix+deviceClass]
+	if !ok {
+		return 0, 0, false
+	}
+	free, _ = strconv.ParseUint(val, 10, 64)
+	free = subtractReserved(free, reserved)
+	total, _ = strconv.ParseUint(item.Annotations[topolvm.TotalCapacityKeyPrefix+deviceClass], 10, 64)
+	return free, total, true
+}
+
+func divisorOf(deviceClass string, defaultDivisor float64, divisors map[string]float64) float64 {
+	if v, ok := divisors[deviceClass]; ok {
+		return v
 	}
-	return minScore
+	return defaultDivisor
 }
 
 func (s scheduler) prioritize(w http.ResponseWriter, r *http.Request) {
@@ -87,12 +90,33 @@ func (s scheduler) prioritize(w http.ResponseWriter, r *http.Request) {
 
 	reader := http.MaxBytesReader(w, r.Body, 10<<20)
 	err := json.NewDecoder(reader).Decode(&input)
-	if err != nil {
+	if err != nil || input.Pod == nil {
+		http.Error(w, "Bad Request.", http.StatusBadRequest)
+		return
+	}
+	nodes, missing, ok := s.candidateNodes(&input)
+	if !ok {
 		http.Error(w, "Bad Request.", http.StatusBadRequest)
 		return
 	}
 
-	result := scoreNodes(input.Pod, input.Nodes.Items, s.defaultDivisor, s.divisors)
+	var reserved map[string]map[string]int64
+	if s.reservations != nil {
+		reserved = s.reservations.Reserved(input.Pod.UID)
+	}
+	result := scoreNodes(input.Pod, nodes, s.defaultDivisor, s.divisors, s.scoring, reserved)
+	if result != nil {
+		// The nodes not found in the cache have the lowest score.
+		for _, name := range missing {
+			result = append(result, HostPriority{Host: name, Score: 0})
+		}
+	}
+	if s.sampleDecision() {
+		decisionLogger.Info("scored nodes",
+			"pod", podName(input.Pod),
+			"scores", result,
+		)
+	}
 
 	w.Header().Set("content-type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -81,7 +81,7 @@ func TestScoreNodes(t *testing.T) {
 		"ssd":  4,
 		"hdd1": 10,
 	}
-	result := scoreNodes(pod, input, defaultDivisor, divisors)
+	result := scoreNodes(pod, input, defaultDivisor, divisors, nil, nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("expected scoreNodes() to be %#v, but actual %#v", expected, result)
 	}
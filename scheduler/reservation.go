@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+var reservationLogger = ctrl.Log.WithName("reservation")
+
+// ReservationCache keeps the capacity assumed to be consumed by pods approved by the extender.
+//
+// The capacity annotations of nodes are not updated until the logical volumes are created,
+// so the extender would place many pods on the same node if they are scheduled at once.
+// To avoid this, the capacity requested by a pod is reserved on every node approved for
+// the pod, and subtracted from the capacity of the nodes for the other pods.
+//
+// A reservation is consumed when a LogicalVolume of the same device-class is created on
+// one of the approved nodes, and expires after TTL.
+type ReservationCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu           sync.Mutex
+	reservations map[types.UID]*reservation
+}
+
+type reservation struct {
+	nodes     map[string]struct{}
+	requested map[string]int64
+	created   time.Time
+	expires   time.Time
+}
+
+// NewReservationCache creates a ReservationCache whose entries expire after ttl.
+func NewReservationCache(ttl time.Duration) *ReservationCache {
+	return &ReservationCache{
+		ttl:          ttl,
+		now:          time.Now,
+		reservations: make(map[types.UID]*reservation),
+	}
+}
+
+func (c *ReservationCache) expire(now time.Time) {
+	for uid, r := range c.reservations {
+		if !now.Before(r.expires) {
+			delete(c.reservations, uid)
+		}
+	}
+}
+
+// Reserve records the capacity requested by the pod on the nodes.
+// The previous reservation of the pod is replaced.
+func (c *ReservationCache) Reserve(uid types.UID, nodes []string, requested map[string]int64) {
+	if uid == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.expire(now)
+	if len(nodes) == 0 || len(requested) == 0 {
+		delete(c.reservations, uid)
+		return
+	}
+
+	r := &reservation{
+		nodes:     make(map[string]struct{}, len(nodes)),
+		requested: make(map[string]int64, len(requested)),
+		created:   now,
+		expires:   now.Add(c.ttl),
+	}
+	for _, n := range nodes {
+		r.nodes[n] = struct{}{}
+	}
+	for dc, size := range requested {
+		if size > 0 {
+			r.requested[dc] = size
+		}
+	}
+	if len(r.requested) == 0 {
+		delete(c.reservations, uid)
+		return
+	}
+	c.reservations[uid] = r
+}
+
+// Reserved returns the reserved capacity per node and device-class.
+// The reservation of the pod given by exclude is not counted.
+func (c *ReservationCache) Reserved(exclude types.UID) map[string]map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(c.now())
+	result := make(map[string]map[string]int64)
+	for uid, r := range c.reservations {
+		if uid == exclude {
+			continue
+		}
+		for n := range r.nodes {
+			m, ok := result[n]
+			if !ok {
+				m = make(map[string]int64)
+				result[n] = m
+			}
+			for dc, size := range r.requested {
+				m[dc] += size
+			}
+		}
+	}
+	return result
+}
+
+// Consume consumes the oldest reservation on the node for a logical volume created at createdAt.
+// Since the pod of the reservation must have been placed on the node, the reservation is
+// no longer counted on the other nodes.
+func (c *ReservationCache) Consume(node, deviceClass string, size int64, createdAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(c.now())
+	var candidates []types.UID
+	for uid, r := range c.reservations {
+		if _, ok := r.nodes[node]; !ok {
+			continue
+		}
+		if r.requested[deviceClass] <= 0 {
+			continue
+		}
+		// creationTimestamp of objects has the precision of seconds.
+		if createdAt.Before(r.created.Truncate(time.Second)) {
+			continue
+		}
+		candidates = append(candidates, uid)
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return c.reservations[candidates[i]].created.Before(c.reservations[candidates[j]].created)
+	})
+
+	uid := candidates[0]
+	r := c.reservations[uid]
+	r.nodes = map[string]struct{}{node: {}}
+	r.requested[deviceClass] -= size
+	if r.requested[deviceClass] <= 0 {
+		delete(r.requested, deviceClass)
+	}
+	if len(r.requested) == 0 {
+		delete(c.reservations, uid)
+	}
+}
+
+// Release removes the reservation of the pod.
+func (c *ReservationCache) Release(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.reservations, uid)
+}
+
+// LogicalVolumeHandler returns an event handler that consumes the reservations
+// when LogicalVolumes are created.
+func (c *ReservationCache) LogicalVolumeHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			lv, ok := obj.(*topolvmv1.LogicalVolume)
+			if !ok {
+				return
+			}
+			c.Consume(lv.Spec.NodeName, deviceClassAnnotationName(lv.Spec.DeviceClass), lv.Spec.Size.Value(), lv.CreationTimestamp.Time)
+		},
+	}
+}
+
+// StartReservationCache creates a ReservationCache and starts watching LogicalVolumes
+// to consume the reservations.  It returns after the cache of LogicalVolumes is synced.
+func StartReservationCache(ctx context.Context, cfg *rest.Config, ttl time.Duration) (*ReservationCache, error) {
+	scheme := runtime.NewScheme()
+	if err := topolvmv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	informer, err := c.GetInformer(ctx, &topolvmv1.LogicalVolume{})
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := NewReservationCache(ttl)
+	informer.AddEventHandler(reservations.LogicalVolumeHandler())
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			reservationLogger.Error(err, "failed to watch LogicalVolumes")
+		}
+	}()
+	if !c.WaitForCacheSync(ctx) {
+		return nil, errors.New("failed to sync the cache of LogicalVolumes")
+	}
+	return reservations, nil
+}
+
+func deviceClassAnnotationName(deviceClass string) string {
+	if deviceClass == topolvm.DefaultDeviceClassName {
+		return topolvm.DefaultDeviceClassAnnotationName
+	}
+	return deviceClass
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReservationCache(t *testing.T) {
+	now := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	c := NewReservationCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Reserve("pod1", []string{"node1", "node2"}, map[string]int64{"ssd": 3 << 30})
+	now = now.Add(10 * time.Second)
+	c.Reserve("pod2", []string{"node2"}, map[string]int64{"ssd": 2 << 30, "hdd": 0})
+	c.Reserve("pod3", []string{"node1"}, map[string]int64{"ssd": 0})
+
+	reserved := c.Reserved("")
+	expected := map[string]map[string]int64{
+		"node1": {"ssd": 3 << 30},
+		"node2": {"ssd": 5 << 30},
+	}
+	if !reflect.DeepEqual(reserved, expected) {
+		t.Errorf("unexpected reservations: expected=%v, actual=%v", expected, reserved)
+	}
+
+	reserved = c.Reserved("pod1")
+	expected = map[string]map[string]int64{
+		"node2": {"ssd": 2 << 30},
+	}
+	if !reflect.DeepEqual(reserved, expected) {
+		t.Errorf("unexpected reservations excluding pod1: expected=%v, actual=%v", expected, reserved)
+	}
+
+	// Logical volumes created before the reservations do not consume them.
+	c.Consume("node2", "ssd", 1<<30, now.Add(-time.Hour))
+	// Logical volumes in other device-classes do not consume them.
+	c.Consume("node2", "hdd", 1<<30, now)
+	if reserved := c.Reserved(""); reserved["node2"]["ssd"] != 5<<30 {
+		t.Errorf("reservations should not be consumed: %v", reserved)
+	}
+
+	// The oldest reservation, i.e. pod1, is consumed and limited to node2.
+	c.Consume("node2", "ssd", 1<<30, now)
+	reserved = c.Reserved("")
+	expected = map[string]map[string]int64{
+		"node2": {"ssd": 4 << 30},
+	}
+	if !reflect.DeepEqual(reserved, expected) {
+		t.Errorf("unexpected reservations after consumption: expected=%v, actual=%v", expected, reserved)
+	}
+
+	c.Consume("node2", "ssd", 2<<30, now)
+	reserved = c.Reserved("")
+	expected = map[string]map[string]int64{
+		"node2": {"ssd": 2 << 30},
+	}
+	if !reflect.DeepEqual(reserved, expected) {
+		t.Errorf("pod1 should be removed: expected=%v, actual=%v", expected, reserved)
+	}
+
+	now = now.Add(time.Minute)
+	if reserved := c.Reserved(""); len(reserved) != 0 {
+		t.Errorf("reservations should expire: %v", reserved)
+	}
+}
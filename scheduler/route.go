@@ -2,20 +2,62 @@ package scheduler
 
 import (
 	"fmt"
+	"math/rand"
 	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var decisionLogger = ctrl.Log.WithName("decision")
+
 type scheduler struct {
 	defaultDivisor float64
 	divisors       map[string]float64
+	scoring        *ScoringConfig
+	reservations   *ReservationCache
+	nodes          *NodeCache
+	client         client.Reader
+	sampleRate     float64
+	metrics        *extenderMetrics
+	metricsHandler http.Handler
+}
+
+// HandlerOptions is the options of the scheduler extender.
+type HandlerOptions struct {
+	// DefaultDivisor is the default divisor value for node scoring.
+	DefaultDivisor float64
+	// Divisors is a mapping between device-class names and their divisors.
+	Divisors map[string]float64
+	// Scoring configures the scoring strategies of device-classes.
+	// If nil, nodes are scored with spread-log2 and the minimum score of device-classes is taken.
+	Scoring *ScoringConfig
+	// Reservations, if not nil, is used to subtract the capacity reserved for other pods
+	// from the capacity of nodes.
+	Reservations *ReservationCache
+	// Nodes, if not nil, is used to serve requests with NodeNames.
+	// If it is built from TopolvmNodes, it is also used for requests with Nodes
+	// instead of their annotations.
+	Nodes *NodeCache
+	// Client, if not nil, is used to read Pods and Nodes to serve "/explain".
+	Client client.Reader
+	// DecisionLogSampleRate is the ratio of requests whose decisions are logged, from 0 to 1.
+	DecisionLogSampleRate float64
 }
 
 func (s scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case "/predicate":
-		s.predicate(w, r)
+		s.metrics.observe("predicate", s.predicate, w, r)
 	case "/prioritize":
-		s.prioritize(w, r)
+		s.metrics.observe("prioritize", s.prioritize, w, r)
+	case "/explain":
+		s.explain(w, r)
+	case "/metrics":
+		s.metricsHandler.ServeHTTP(w, r)
 	case "/status":
 		status(w, r)
 	default:
@@ -23,14 +65,65 @@ func (s scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// NewHandler return new http.Handler of the scheduler extender
-func NewHandler(defaultDiv float64, divisors map[string]float64) (http.Handler, error) {
-	for _, divisor := range divisors {
+// NewHandler return new http.Handler of the scheduler extender.
+// The handler also serves Prometheus metrics at "/metrics".
+func NewHandler(opts HandlerOptions) (http.Handler, error) {
+	for _, divisor := range opts.Divisors {
 		if divisor <= 0 {
 			return nil, fmt.Errorf("invalid divisor: %f", divisor)
 		}
 	}
-	return scheduler{defaultDiv, divisors}, nil
+	if err := opts.Scoring.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.DecisionLogSampleRate < 0 || opts.DecisionLogSampleRate > 1 {
+		return nil, fmt.Errorf("invalid decision log sample rate: %f", opts.DecisionLogSampleRate)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	return scheduler{
+		defaultDivisor: opts.DefaultDivisor,
+		divisors:       opts.Divisors,
+		scoring:        opts.Scoring,
+		reservations:   opts.Reservations,
+		nodes:          opts.Nodes,
+		client:         opts.Client,
+		sampleRate:     opts.DecisionLogSampleRate,
+		metrics:        newExtenderMetrics(registry),
+		metricsHandler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}, nil
+}
+
+// sampleDecision returns true if the decision of the request should be logged.
+func (s scheduler) sampleDecision() bool {
+	return s.sampleRate > 0 && rand.Float64() < s.sampleRate
+}
+
+func podName(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+// candidateNodes returns the nodes given by the request.
+// If the request has only NodeNames or the cache is built from TopolvmNodes,
+// the nodes are looked up from the cache and the names not found in the cache
+// are returned as missing.
+func (s scheduler) candidateNodes(input *ExtenderArgs) (nodes []corev1.Node, missing []string, ok bool) {
+	switch {
+	case input.Nodes != nil && s.nodes.lookup():
+		names := make([]string, len(input.Nodes.Items))
+		for i, n := range input.Nodes.Items {
+			names[i] = n.Name
+		}
+		nodes, missing = s.nodes.Get(names)
+		return nodes, missing, true
+	case input.Nodes != nil:
+		return input.Nodes.Items, nil, true
+	case input.NodeNames != nil && s.nodes != nil:
+		nodes, missing = s.nodes.Get(*input.NodeNames)
+		return nodes, missing, true
+	}
+	return nil, nil, false
 }
 
 func status(w http.ResponseWriter, r *http.Request) {
@@ -3,16 +3,23 @@ package scheduler
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var extenderArgs = ExtenderArgs{
@@ -45,8 +52,11 @@ var extenderArgs = ExtenderArgs{
 func testPredicate(t *testing.T) {
 	t.Parallel()
 
-	handler, err := NewHandler(1, map[string]float64{
-		"ssd": 1,
+	handler, err := NewHandler(HandlerOptions{
+		DefaultDivisor: 1,
+		Divisors: map[string]float64{
+			"ssd": 1,
+		},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -92,8 +102,11 @@ func testPredicate(t *testing.T) {
 func testPrioritize(t *testing.T) {
 	t.Parallel()
 
-	handler, err := NewHandler(1, map[string]float64{
-		"ssd": 1,
+	handler, err := NewHandler(HandlerOptions{
+		DefaultDivisor: 1,
+		Divisors: map[string]float64{
+			"ssd": 1,
+		},
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -143,7 +156,415 @@ func testPrioritize(t *testing.T) {
 	}
 }
 
+func testPredicateWithReservation(t *testing.T) {
+	t.Parallel()
+
+	handler, err := NewHandler(HandlerOptions{DefaultDivisor: 1, Reservations: NewReservationCache(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	predicate := func(uid types.UID) *ExtenderFilterResult {
+		args := extenderArgs
+		pod := *extenderArgs.Pod
+		pod.UID = uid
+		args.Pod = &pod
+		input, err := json.Marshal(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/predicate", bytes.NewReader(input))
+		handler.ServeHTTP(w, r)
+
+		result := new(ExtenderFilterResult)
+		err = json.NewDecoder(w.Result().Body).Decode(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := predicate("pod1")
+	if result.Nodes == nil || len(result.Nodes.Items) != 1 || result.Nodes.Items[0].Name != "10.1.1.2" {
+		t.Fatalf("wrong result.Nodes for pod1: %#v", result.Nodes)
+	}
+
+	// 10.1.1.2 has 5 GiB and pod1 reserves 3 GiB of it.
+	result = predicate("pod2")
+	if result.Nodes == nil || len(result.Nodes.Items) != 0 {
+		t.Errorf("wrong result.Nodes for pod2: %#v", result.Nodes)
+	}
+
+	// The reservation of the pod itself should not be counted.
+	result = predicate("pod1")
+	if result.Nodes == nil || len(result.Nodes.Items) != 1 {
+		t.Errorf("wrong result.Nodes for pod1 on retry: %#v", result.Nodes)
+	}
+}
+
+func testNodeNames(t *testing.T) {
+	t.Parallel()
+
+	nodes := NewNodeCache()
+	for i := range extenderArgs.Nodes.Items {
+		nodes.NodeHandler().OnAdd(&extenderArgs.Nodes.Items[i])
+	}
+	handler, err := NewHandler(HandlerOptions{DefaultDivisor: 1, Nodes: nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"10.1.1.1", "10.1.1.2", "10.1.1.3"}
+	input, err := json.Marshal(ExtenderArgs{Pod: extenderArgs.Pod, NodeNames: &names})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/predicate", bytes.NewReader(input))
+	handler.ServeHTTP(w, r)
+
+	result := new(ExtenderFilterResult)
+	err = json.NewDecoder(w.Result().Body).Decode(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Nodes != nil || result.NodeNames == nil || !reflect.DeepEqual(*result.NodeNames, []string{"10.1.1.2"}) {
+		t.Errorf("wrong result.NodeNames: %#v", result)
+	}
+	expectedFailed := FailedNodesMap{"10.1.1.1": "out of VG free space", "10.1.1.3": "node not found"}
+	if !reflect.DeepEqual(result.FailedNodes, expectedFailed) {
+		t.Errorf("wrong result.FailedNodes: %#v", result.FailedNodes)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/prioritize", bytes.NewReader(input))
+	handler.ServeHTTP(w, r)
+
+	priorities := HostPriorityList{}
+	err = json.NewDecoder(w.Result().Body).Decode(&priorities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := HostPriorityList{
+		{Host: "10.1.1.1", Score: 1},
+		{Host: "10.1.1.2", Score: 2},
+		{Host: "10.1.1.3", Score: 0},
+	}
+	if !reflect.DeepEqual(priorities, expected) {
+		t.Errorf("wrong Hostprioritylist; expected: %#v, actual: %#v", expected, priorities)
+	}
+
+	// NodeNames are not accepted without the node cache.
+	handler, err = NewHandler(HandlerOptions{DefaultDivisor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/predicate", "/prioritize"} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("POST", path, bytes.NewReader(input))
+		handler.ServeHTTP(w, r)
+		if w.Result().StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: resp.StatusCode != http.StatusBadRequest: %d", path, w.Result().StatusCode)
+		}
+	}
+}
+
+func testTopolvmNodes(t *testing.T) {
+	t.Parallel()
+
+	nodes := NewTopolvmNodeCache()
+	for _, free := range []struct {
+		name string
+		gb   int64
+	}{{"10.1.1.1", 5}, {"10.1.1.2", 2}} {
+		tn := &topolvmv1.TopolvmNode{}
+		tn.Name = free.name
+		tn.Status.LastHeartbeatTime = metav1.Now()
+		tn.Status.DeviceClasses = []topolvmv1.DeviceClassStatus{{Name: "ssd", FreeBytes: free.gb << 30, Health: topolvmv1.VolumeGroupHealthy}}
+		nodes.TopolvmNodeHandler().OnAdd(tn)
+	}
+	handler, err := NewHandler(HandlerOptions{DefaultDivisor: 1, Nodes: nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The annotations of Nodes in the request are ignored.
+	args := ExtenderArgs{
+		Pod: extenderArgs.Pod,
+		Nodes: &corev1.NodeList{
+			Items: []corev1.Node{
+				testNode("10.1.1.1", 2, 10, 10),
+				testNode("10.1.1.2", 5, 10, 10),
+				testNode("10.1.1.3", 5, 10, 10),
+			},
+		},
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/predicate", bytes.NewReader(input))
+	handler.ServeHTTP(w, r)
+
+	result := new(ExtenderFilterResult)
+	err = json.NewDecoder(w.Result().Body).Decode(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Nodes == nil || len(result.Nodes.Items) != 1 || result.Nodes.Items[0].Name != "10.1.1.1" {
+		t.Fatalf("wrong result.Nodes: %#v", result)
+	}
+	if !reflect.DeepEqual(result.Nodes.Items[0], args.Nodes.Items[0]) {
+		t.Errorf("the node in the request should be returned: %#v", result.Nodes.Items[0])
+	}
+	expectedFailed := FailedNodesMap{"10.1.1.2": "out of VG free space", "10.1.1.3": "node not found"}
+	if !reflect.DeepEqual(result.FailedNodes, expectedFailed) {
+		t.Errorf("wrong result.FailedNodes: %#v", result.FailedNodes)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/prioritize", bytes.NewReader(input))
+	handler.ServeHTTP(w, r)
+
+	priorities := HostPriorityList{}
+	err = json.NewDecoder(w.Result().Body).Decode(&priorities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := HostPriorityList{
+		{Host: "10.1.1.1", Score: 2},
+		{Host: "10.1.1.2", Score: 1},
+		{Host: "10.1.1.3", Score: 0},
+	}
+	if !reflect.DeepEqual(priorities, expected) {
+		t.Errorf("wrong Hostprioritylist; expected: %#v, actual: %#v", expected, priorities)
+	}
+}
+
+func testMetrics(t *testing.T) {
+	t.Parallel()
+
+	handler, err := NewHandler(HandlerOptions{DefaultDivisor: 1, DecisionLogSampleRate: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := json.Marshal(extenderArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/predicate", "/prioritize"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", path, bytes.NewReader(input))
+		handler.ServeHTTP(w, r)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/predicate", nil)
+	handler.ServeHTTP(w, r)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/metrics", nil)
+	handler.ServeHTTP(w, r)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatal("resp.StatusCode != http.StatusOK:", w.Result().StatusCode)
+	}
+	body := w.Body.String()
+	for _, expected := range []string{
+		`topolvm_scheduler_requests_total{code="200",verb="predicate"} 1`,
+		`topolvm_scheduler_requests_total{code="400",verb="predicate"} 1`,
+		`topolvm_scheduler_requests_total{code="200",verb="prioritize"} 1`,
+		`topolvm_scheduler_request_duration_seconds_count{verb="predicate"} 2`,
+		`topolvm_scheduler_filtered_nodes_total{device_class="ssd",reason="out of VG free space"} 1`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("metrics do not contain %q", expected)
+		}
+	}
+
+	_, err = NewHandler(HandlerOptions{DefaultDivisor: 1, DecisionLogSampleRate: 1.5})
+	if err == nil {
+		t.Error("invalid sample rate should be rejected")
+	}
+}
+
+func testExplain(t *testing.T) {
+	t.Parallel()
+
+	pod := extenderArgs.Pod.DeepCopy()
+	pod.Namespace = "default"
+	pod.Name = "pod1"
+	pod.UID = "uid1"
+	objects := []runtime.Object{pod}
+	for i := range extenderArgs.Nodes.Items {
+		objects = append(objects, &extenderArgs.Nodes.Items[i])
+	}
+	reservations := NewReservationCache(time.Minute)
+	reservations.Reserve("uid2", []string{"10.1.1.2"}, map[string]int64{"ssd": 1 << 30})
+
+	handler, err := NewHandler(HandlerOptions{
+		DefaultDivisor: 1,
+		Reservations:   reservations,
+		Client:         fake.NewClientBuilder().WithRuntimeObjects(objects...).Build(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/explain?namespace=default&name=pod1", nil)
+	handler.ServeHTTP(w, r)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatal("resp.StatusCode != http.StatusOK:", w.Result().StatusCode)
+	}
+	result := new(Explanation)
+	err = json.NewDecoder(w.Result().Body).Decode(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Explanation{
+		Pod:       "default/pod1",
+		Requested: map[string]int64{"ssd": 3 << 30},
+		Nodes: []NodeExplanation{
+			{
+				Name:         "10.1.1.1",
+				FailedReason: "out of VG free space",
+				Score:        1,
+				DeviceClasses: []DeviceClassExplanation{
+					{DeviceClass: "ssd", Requested: 3 << 30, Capacity: strconv.Itoa(2 << 30), Available: 2 << 30, Strategy: StrategySpreadLog2, Score: 1},
+				},
+			},
+			{
+				Name:  "10.1.1.2",
+				Score: 2,
+				DeviceClasses: []DeviceClassExplanation{
+					{DeviceClass: "ssd", Requested: 3 << 30, Capacity: strconv.Itoa(5 << 30), Reserved: 1 << 30, Available: 4 << 30, Strategy: StrategySpreadLog2, Score: 2},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong explanation; expected: %#v, actual: %#v", expected, result)
+	}
+
+	for path, code := range map[string]int{
+		"/explain?namespace=default&name=pod2": http.StatusNotFound,
+		"/explain?namespace=default":           http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", path, nil)
+		handler.ServeHTTP(w, r)
+		if w.Result().StatusCode != code {
+			t.Errorf("%s: resp.StatusCode != %d: %d", path, code, w.Result().StatusCode)
+		}
+	}
+
+	// explain is disabled without the client.
+	handler, err = NewHandler(HandlerOptions{DefaultDivisor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/explain?namespace=default&name=pod1", nil)
+	handler.ServeHTTP(w, r)
+	if w.Result().StatusCode != http.StatusNotFound {
+		t.Error("resp.StatusCode != http.StatusNotFound:", w.Result().StatusCode)
+	}
+}
+
 func TestRoute(t *testing.T) {
 	t.Run("predicate", testPredicate)
 	t.Run("prioritize", testPrioritize)
+	t.Run("predicate-with-reservation", testPredicateWithReservation)
+	t.Run("node-names", testNodeNames)
+	t.Run("topolvm-nodes", testTopolvmNodes)
+	t.Run("metrics", testMetrics)
+	t.Run("explain", testExplain)
+}
+
+// benchmarkNode returns a node with the typical amount of labels, annotations and status.
+func benchmarkNode(name string) corev1.Node {
+	node := testNode(name, 100, 100, 100)
+	node.Labels = map[string]string{
+		"kubernetes.io/hostname":           name,
+		"kubernetes.io/os":                 "linux",
+		"kubernetes.io/arch":               "amd64",
+		"topology.kubernetes.io/zone":      "zone1",
+		"topology.kubernetes.io/region":    "region1",
+		"node.kubernetes.io/instance-type": "standard",
+	}
+	node.Annotations["node.alpha.kubernetes.io/ttl"] = "0"
+	node.Annotations["volumes.kubernetes.io/controller-managed-attach-detach"] = "true"
+	for i := 0; i < 20; i++ {
+		node.Status.Images = append(node.Status.Images, corev1.ContainerImage{
+			Names: []string{
+				fmt.Sprintf("quay.io/example/image%d@sha256:%064d", i, i),
+				fmt.Sprintf("quay.io/example/image%d:1.0.%d", i, i),
+			},
+			SizeBytes: 100 << 20,
+		})
+	}
+	for _, c := range []corev1.NodeConditionType{corev1.NodeReady, corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure} {
+		node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+			Type:    c,
+			Status:  corev1.ConditionFalse,
+			Reason:  "KubeletHasSufficient",
+			Message: "kubelet has sufficient resources available",
+		})
+	}
+	return node
+}
+
+const benchmarkNodes = 1500
+
+func benchmarkPredicate(b *testing.B, nodeNames bool) {
+	nodes := make([]corev1.Node, benchmarkNodes)
+	names := make([]string, benchmarkNodes)
+	cache := NewNodeCache()
+	for i := range nodes {
+		nodes[i] = benchmarkNode(fmt.Sprintf("node%d", i))
+		names[i] = nodes[i].Name
+		cache.NodeHandler().OnAdd(&nodes[i])
+	}
+	handler, err := NewHandler(HandlerOptions{DefaultDivisor: 1, Nodes: cache})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	args := ExtenderArgs{Pod: extenderArgs.Pod}
+	if nodeNames {
+		args.NodeNames = &names
+	} else {
+		args.Nodes = &corev1.NodeList{Items: nodes}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// kube-scheduler encodes the request for every pod, and decodes the response.
+		input, err := json.Marshal(args)
+		if err != nil {
+			b.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/predicate", bytes.NewReader(input))
+		handler.ServeHTTP(w, r)
+
+		var result ExtenderFilterResult
+		if err := json.NewDecoder(w.Result().Body).Decode(&result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPredicateNodes(b *testing.B) {
+	benchmarkPredicate(b, false)
+}
+
+func BenchmarkPredicateNodeNames(b *testing.B) {
+	benchmarkPredicate(b, true)
 }
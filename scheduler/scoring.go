@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxScore is the maximum score of the prioritize verb.
+const maxScore = 10
+
+// StrategyType is the type of the scoring strategy for a device-class.
+type StrategyType string
+
+// Scoring strategies.
+const (
+	// StrategySpreadLog2 scores nodes with min(10, max(0, log2(free GiB / divisor))).
+	// Volumes are spread over nodes with more free space.
+	StrategySpreadLog2 = StrategyType("spread-log2")
+	// StrategyMostAllocated scores nodes linearly by the utilization after placing the volumes.
+	// Volumes are packed into nodes with less free space.
+	StrategyMostAllocated = StrategyType("most-allocated")
+	// StrategyLeastAllocated scores nodes linearly by the free space ratio after placing the volumes.
+	StrategyLeastAllocated = StrategyType("least-allocated-linear")
+	// StrategyRequestedToCapacityRatio scores nodes by the utilization after placing the volumes
+	// with a piecewise linear function given by Shape.
+	StrategyRequestedToCapacityRatio = StrategyType("requested-to-capacity-ratio")
+)
+
+// Aggregation is the way to aggregate the scores of device-classes requested by a pod.
+type Aggregation string
+
+// Aggregations.
+const (
+	// AggregationMin takes the minimum score.
+	AggregationMin = Aggregation("min")
+	// AggregationSum takes the sum of the scores capped at 10.
+	AggregationSum = Aggregation("sum")
+	// AggregationWeightedAverage takes the average of the scores weighted by Weights.
+	AggregationWeightedAverage = Aggregation("weighted-average")
+)
+
+// ShapePoint is a point of the function of StrategyRequestedToCapacityRatio.
+type ShapePoint struct {
+	// Utilization is the utilization of the volume group in percent, from 0 to 100.
+	Utilization int `json:"utilization"`
+	// Score is the score at Utilization, from 0 to 10.
+	Score int `json:"score"`
+}
+
+// ScoringStrategy is the scoring strategy for a device-class.
+type ScoringStrategy struct {
+	// Type is the type of the strategy.  The default is spread-log2.
+	Type StrategyType `json:"type,omitempty"`
+	// Shape is the points of the function for requested-to-capacity-ratio.
+	// The utilization of the points must be in increasing order.
+	Shape []ShapePoint `json:"shape,omitempty"`
+}
+
+// ScoringConfig configures how the prioritize verb scores nodes.
+//
+// The strategies other than spread-log2 need the size of volume groups annotated
+// to Node as "total-capacity.topolvm.cybozu.com/<device-class>".  Nodes without
+// the annotation get the lowest score with such strategies.
+type ScoringConfig struct {
+	// Default is the strategy for device-classes not found in Strategies.
+	Default ScoringStrategy `json:"default,omitempty"`
+	// Strategies is a mapping between device-class names and their strategies.
+	Strategies map[string]ScoringStrategy `json:"strategies,omitempty"`
+	// Aggregation is the way to aggregate the scores of device-classes.  The default is min.
+	Aggregation Aggregation `json:"aggregation,omitempty"`
+	// Weights is a mapping between device-class names and their weights for weighted-average.
+	// The default weight is 1.
+	Weights map[string]float64 `json:"weights,omitempty"`
+}
+
+// Validate checks the configuration.
+func (c *ScoringConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("default strategy: %w", err)
+	}
+	for dc, s := range c.Strategies {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("strategy for %s: %w", dc, err)
+		}
+	}
+	switch c.Aggregation {
+	case "", AggregationMin, AggregationSum, AggregationWeightedAverage:
+	default:
+		return fmt.Errorf("unknown aggregation: %s", c.Aggregation)
+	}
+	for dc, w := range c.Weights {
+		if w <= 0 {
+			return fmt.Errorf("invalid weight for %s: %f", dc, w)
+		}
+	}
+	return nil
+}
+
+func (s ScoringStrategy) validate() error {
+	switch s.Type {
+	case "", StrategySpreadLog2, StrategyMostAllocated, StrategyLeastAllocated:
+		if len(s.Shape) != 0 {
+			return fmt.Errorf("shape is not supported by %s", s.Type)
+		}
+		return nil
+	case StrategyRequestedToCapacityRatio:
+	default:
+		return fmt.Errorf("unknown strategy: %s", s.Type)
+	}
+
+	if len(s.Shape) == 0 {
+		return errors.New("shape must not be empty")
+	}
+	for i, p := range s.Shape {
+		if p.Utilization < 0 || p.Utilization > 100 {
+			return fmt.Errorf("invalid utilization: %d", p.Utilization)
+		}
+		if p.Score < 0 || p.Score > maxScore {
+			return fmt.Errorf("invalid score: %d", p.Score)
+		}
+		if i > 0 && p.Utilization <= s.Shape[i-1].Utilization {
+			return errors.New("utilization of shape must be in increasing order")
+		}
+	}
+	return nil
+}
+
+func (c *ScoringConfig) strategy(deviceClass string) ScoringStrategy {
+	if c == nil {
+		return ScoringStrategy{}
+	}
+	if s, ok := c.Strategies[deviceClass]; ok {
+		return s
+	}
+	return c.Default
+}
+
+// score returns the score of a device-class on a node.
+// free is the free space, total is the size of the volume group, or zero if unknown,
+// and requested is the capacity requested by the pod.
+func (s ScoringStrategy) score(free, total uint64, requested int64, divisor float64) int {
+	if s.Type == "" || s.Type == StrategySpreadLog2 {
+		return capacityToScore(free, divisor)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// the utilization in percent after the volumes are placed.
+	used := float64(total) - float64(free)
+	if requested > 0 {
+		used += float64(requested)
+	}
+	utilization := math.Max(0, math.Min(100, used*100/float64(total)))
+
+	switch s.Type {
+	case StrategyMostAllocated:
+		return int(utilization * maxScore / 100)
+	case StrategyLeastAllocated:
+		return int((100 - utilization) * maxScore / 100)
+	case StrategyRequestedToCapacityRatio:
+		return shapeScore(s.Shape, utilization)
+	}
+	return 0
+}
+
+// shapeScore interpolates the score at utilization linearly between the points.
+func shapeScore(shape []ShapePoint, utilization float64) int {
+	if len(shape) == 0 {
+		return 0
+	}
+	if utilization <= float64(shape[0].Utilization) {
+		return shape[0].Score
+	}
+	for i := 1; i < len(shape); i++ {
+		p0, p1 := shape[i-1], shape[i]
+		if utilization > float64(p1.Utilization) {
+			continue
+		}
+		ratio := (utilization - float64(p0.Utilization)) / float64(p1.Utilization-p0.Utilization)
+		return p0.Score + int(ratio*float64(p1.Score-p0.Score))
+	}
+	return shape[len(shape)-1].Score
+}
+
+// aggregate aggregates the scores of device-classes into the score of a node.
+func (c *ScoringConfig) aggregate(scores map[string]int) int {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	var aggregation Aggregation
+	if c != nil {
+		aggregation = c.Aggregation
+	}
+	switch aggregation {
+	case AggregationSum:
+		sum := 0
+		for _, s := range scores {
+			sum += s
+		}
+		if sum > maxScore {
+			return maxScore
+		}
+		return sum
+	case AggregationWeightedAverage:
+		var sum, weights float64
+		for dc, s := range scores {
+			w, ok := c.Weights[dc]
+			if !ok {
+				w = 1
+			}
+			sum += w * float64(s)
+			weights += w
+		}
+		return int(sum / weights)
+	}
+
+	minScore := math.MaxInt32
+	for _, s := range scores {
+		if s < minScore {
+			minScore = s
+		}
+	}
+	return minScore
+}
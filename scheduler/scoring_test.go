@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStrategyScore(t *testing.T) {
+	shape := []ShapePoint{
+		{Utilization: 0, Score: 0},
+		{Utilization: 50, Score: 10},
+		{Utilization: 100, Score: 0},
+	}
+	testCases := []struct {
+		strategy  ScoringStrategy
+		free      uint64
+		total     uint64
+		requested int64
+		expect    int
+	}{
+		{ScoringStrategy{}, 128 << 30, 0, 0, 7},
+		{ScoringStrategy{Type: StrategySpreadLog2}, 128 << 30, 256 << 30, 1 << 30, 7},
+		{ScoringStrategy{Type: StrategyMostAllocated}, 80 << 30, 100 << 30, 10 << 30, 3},
+		{ScoringStrategy{Type: StrategyMostAllocated}, 80 << 30, 0, 10 << 30, 0},
+		{ScoringStrategy{Type: StrategyMostAllocated}, 0, 100 << 30, 10 << 30, 10},
+		{ScoringStrategy{Type: StrategyLeastAllocated}, 80 << 30, 100 << 30, 10 << 30, 7},
+		{ScoringStrategy{Type: StrategyLeastAllocated}, 80 << 30, 0, 10 << 30, 0},
+		{ScoringStrategy{Type: StrategyRequestedToCapacityRatio, Shape: shape}, 100 << 30, 100 << 30, 25 << 30, 5},
+		{ScoringStrategy{Type: StrategyRequestedToCapacityRatio, Shape: shape}, 50 << 30, 100 << 30, 0, 10},
+		{ScoringStrategy{Type: StrategyRequestedToCapacityRatio, Shape: shape}, 10 << 30, 100 << 30, 10 << 30, 0},
+	}
+
+	for _, tt := range testCases {
+		score := tt.strategy.score(tt.free, tt.total, tt.requested, 1)
+		if score != tt.expect {
+			t.Errorf("score incorrect: strategy=%#v free=%d total=%d requested=%d expect=%d actual=%d",
+				tt.strategy, tt.free, tt.total, tt.requested, tt.expect, score)
+		}
+	}
+}
+
+func TestShapeScore(t *testing.T) {
+	shape := []ShapePoint{
+		{Utilization: 20, Score: 2},
+		{Utilization: 60, Score: 10},
+	}
+	testCases := []struct {
+		utilization float64
+		expect      int
+	}{
+		{0, 2},
+		{20, 2},
+		{40, 6},
+		{60, 10},
+		{100, 10},
+	}
+
+	for _, tt := range testCases {
+		score := shapeScore(shape, tt.utilization)
+		if score != tt.expect {
+			t.Errorf("score incorrect: utilization=%f expect=%d actual=%d", tt.utilization, tt.expect, score)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	scores := map[string]int{
+		"ssd": 8,
+		"hdd": 2,
+	}
+	testCases := []struct {
+		config *ScoringConfig
+		expect int
+	}{
+		{nil, 2},
+		{&ScoringConfig{Aggregation: AggregationMin}, 2},
+		{&ScoringConfig{Aggregation: AggregationSum}, 10},
+		{&ScoringConfig{Aggregation: AggregationWeightedAverage}, 5},
+		{&ScoringConfig{Aggregation: AggregationWeightedAverage, Weights: map[string]float64{"ssd": 3}}, 6},
+	}
+
+	for _, tt := range testCases {
+		score := tt.config.aggregate(scores)
+		if score != tt.expect {
+			t.Errorf("score incorrect: config=%#v expect=%d actual=%d", tt.config, tt.expect, score)
+		}
+	}
+
+	if score := (&ScoringConfig{Aggregation: AggregationSum}).aggregate(nil); score != 0 {
+		t.Errorf("score of no device-class should be 0: %d", score)
+	}
+}
+
+func TestScoringConfigValidate(t *testing.T) {
+	testCases := []struct {
+		config *ScoringConfig
+		valid  bool
+	}{
+		{nil, true},
+		{&ScoringConfig{}, true},
+		{&ScoringConfig{
+			Default: ScoringStrategy{Type: StrategyMostAllocated},
+			Strategies: map[string]ScoringStrategy{
+				"ssd": {Type: StrategyRequestedToCapacityRatio, Shape: []ShapePoint{{0, 10}, {100, 0}}},
+			},
+			Aggregation: AggregationWeightedAverage,
+			Weights:     map[string]float64{"ssd": 2},
+		}, true},
+		{&ScoringConfig{Default: ScoringStrategy{Type: "foo"}}, false},
+		{&ScoringConfig{Default: ScoringStrategy{Type: StrategyMostAllocated, Shape: []ShapePoint{{0, 0}}}}, false},
+		{&ScoringConfig{Strategies: map[string]ScoringStrategy{"ssd": {Type: StrategyRequestedToCapacityRatio}}}, false},
+		{&ScoringConfig{Strategies: map[string]ScoringStrategy{
+			"ssd": {Type: StrategyRequestedToCapacityRatio, Shape: []ShapePoint{{50, 0}, {50, 10}}},
+		}}, false},
+		{&ScoringConfig{Strategies: map[string]ScoringStrategy{
+			"ssd": {Type: StrategyRequestedToCapacityRatio, Shape: []ShapePoint{{0, 0}, {120, 10}}},
+		}}, false},
+		{&ScoringConfig{Strategies: map[string]ScoringStrategy{
+			"ssd": {Type: StrategyRequestedToCapacityRatio, Shape: []ShapePoint{{0, 0}, {100, 11}}},
+		}}, false},
+		{&ScoringConfig{Aggregation: "max"}, false},
+		{&ScoringConfig{Weights: map[string]float64{"ssd": 0}}, false},
+	}
+
+	for _, tt := range testCases {
+		err := tt.config.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("config %#v should be valid: %v", tt.config, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("config %#v should be invalid", tt.config)
+		}
+	}
+}
+
+func TestScoreNodeWithStrategies(t *testing.T) {
+	node := func(name string, freeGb, totalGb int64) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					topolvm.CapacityKeyPrefix + "ssd":      fmt.Sprintf("%d", freeGb<<30),
+					topolvm.TotalCapacityKeyPrefix + "ssd": fmt.Sprintf("%d", totalGb<<30),
+					topolvm.CapacityKeyPrefix + "hdd":      fmt.Sprintf("%d", 1024<<30),
+				},
+			},
+		}
+	}
+	requested := map[string]int64{
+		"ssd": 10 << 30,
+		"hdd": 1 << 30,
+	}
+	scoring := &ScoringConfig{
+		Strategies: map[string]ScoringStrategy{
+			"ssd": {Type: StrategyMostAllocated},
+		},
+		Aggregation: AggregationWeightedAverage,
+		Weights:     map[string]float64{"ssd": 3},
+	}
+
+	// ssd: 1 (most-allocated), hdd: 10 (spread-log2)
+	empty := scoreNode(node("empty", 100, 100), requested, 1, nil, scoring, nil)
+	if empty != 3 {
+		t.Errorf("unexpected score of empty node: %d", empty)
+	}
+	// ssd: 9 (most-allocated), hdd: 10 (spread-log2)
+	busy := scoreNode(node("busy", 20, 100), requested, 1, nil, scoring, nil)
+	if busy != 9 {
+		t.Errorf("unexpected score of busy node: %d", busy)
+	}
+	// ssd: 10 GiB is reserved for other pods.
+	reserved := scoreNode(node("reserved", 30, 100), requested, 1, nil, scoring, map[string]int64{"ssd": 10 << 30})
+	if reserved != 9 {
+		t.Errorf("unexpected score of reserved node: %d", reserved)
+	}
+}